@@ -3,12 +3,13 @@ module github.com/avran02/fileshare/auth
 go 1.22.3
 
 require (
-	github.com/avran02/fileshare v0.0.4
+	github.com/avran02/fileshare/proto/authpb v0.0.0
 	github.com/golang-jwt/jwt/v5 v5.2.1
 	github.com/google/uuid v1.6.0
 	github.com/lib/pq v1.10.9
 	golang.org/x/crypto v0.21.0
 	google.golang.org/grpc v1.64.0
+	google.golang.org/protobuf v1.34.2
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -17,5 +18,6 @@ require (
 	golang.org/x/sys v0.18.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237 // indirect
-	google.golang.org/protobuf v1.34.1 // indirect
 )
+
+replace github.com/avran02/fileshare/proto/authpb => ../proto/authpb
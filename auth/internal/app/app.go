@@ -1,14 +1,19 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"log/slog"
 	"net"
 	"os"
+	"time"
 
 	"github.com/avran02/fileshare/auth/internal/config"
 	"github.com/avran02/fileshare/auth/internal/controller"
 	"github.com/avran02/fileshare/auth/internal/pkg/jwt"
+	"github.com/avran02/fileshare/auth/internal/pkg/notifier"
+	"github.com/avran02/fileshare/auth/internal/pkg/passwd"
 	"github.com/avran02/fileshare/auth/internal/repo"
 	"github.com/avran02/fileshare/auth/internal/server"
 	"github.com/avran02/fileshare/auth/internal/service"
@@ -18,6 +23,8 @@ import (
 	"google.golang.org/grpc/health/grpc_health_v1"
 )
 
+const purgeInterval = time.Hour
+
 type App struct {
 	server     *server.Server
 	config     *config.Config
@@ -34,6 +41,9 @@ func (app *App) Run() {
 		os.Exit(1)
 	}
 
+	go app.jwt.RunRotation(context.Background())
+	go app.purgeExpired(context.Background())
+
 	slog.Info("Listening on " + host)
 	var opts []grpc.ServerOption
 
@@ -51,12 +61,71 @@ func (app *App) Run() {
 	}
 }
 
+func (app *App) purgeExpired(ctx context.Context) {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := app.repo.DeleteExpiredRevocations(); err != nil {
+			slog.Error(err.Error())
+		}
+
+		if err := app.repo.DeleteExpiredPasswordResets(); err != nil {
+			slog.Error(err.Error())
+		}
+
+		if err := app.repo.DeleteStaleLoginAttempts(); err != nil {
+			slog.Error(err.Error())
+		}
+
+		if err := app.repo.DeleteExpiredChallenges(); err != nil {
+			slog.Error(err.Error())
+		}
+	}
+}
+
 func New() *App {
 	config := config.New()
-	jwtConf := jwt.New(config.JWT)
-
 	repo := repo.New(&config.DB)
-	service := service.New(repo, jwtConf)
+	jwtConf := jwt.New(config.JWT, repo)
+
+	notifier, err := notifier.New(config.Notifier)
+	if err != nil {
+		log.Fatal("can't create notifier:\n", err)
+	}
+
+	hasher, err := passwd.New(config.Password)
+	if err != nil {
+		log.Fatal("can't create password hasher:\n", err)
+	}
+
+	policy, err := passwd.NewPolicy(config.Password)
+	if err != nil {
+		log.Fatal("can't load password policy:\n", err)
+	}
+
+	// the config is the only source of admins, so anyone removed from it loses the role
+	demoted, err := repo.DemoteAdmins(config.Admins)
+	if err != nil {
+		log.Fatal("can't demote former admins:\n", err)
+	}
+	if demoted > 0 {
+		slog.Info(fmt.Sprintf("demoted %d admins missing from the config", demoted))
+	}
+
+	for _, id := range config.Admins {
+		if err = repo.SetUserRole(id, pb.RoleAdmin); err != nil {
+			slog.Error(fmt.Sprintf("can't make user %s an admin: %s", id, err.Error()))
+		}
+	}
+
+	service := service.New(repo, jwtConf, hasher, policy, notifier, config.PasswordReset)
 	controller := controller.New(service)
 	server := server.New(controller)
 
@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -21,8 +22,40 @@ type DB struct {
 }
 
 type JWT struct {
-	Secret string `yaml:"secret"`
-	Exp    int    `yaml:"exp"`
+	Exp       int           `yaml:"exp"`
+	Algorithm string        `yaml:"algorithm"`
+	Rotation  time.Duration `yaml:"rotation"`
+}
+
+type Argon2 struct {
+	// Memory is in KiB.
+	Memory      uint32 `yaml:"memory"`
+	Time        uint32 `yaml:"time"`
+	Parallelism uint8  `yaml:"parallelism"`
+}
+
+type Password struct {
+	// Algorithm new hashes are made with, argon2id or bcrypt. Existing hashes
+	// are upgraded on the next successful login.
+	Algorithm  string `yaml:"algorithm"`
+	Argon2     Argon2 `yaml:"argon2"`
+	BcryptCost int    `yaml:"bcrypt_cost"`
+
+	MinLength int `yaml:"min_length"`
+	// BreachedList is a file with one known breached password per line.
+	BreachedList string `yaml:"breached_list"`
+}
+
+type Notifier struct {
+	// Type is either "log" or "file". The file notifier appends to Path.
+	Type string `yaml:"type"`
+	Path string `yaml:"path"`
+}
+
+type PasswordReset struct {
+	Lifetime time.Duration `yaml:"lifetime"`
+	// URL is the link sent to users, %s is replaced with the reset token.
+	URL string `yaml:"url"`
 }
 
 type Config struct {
@@ -30,6 +63,13 @@ type Config struct {
 	Server Server `yaml:"server"`
 	DB     DB     `yaml:"db"`
 	JWT    JWT    `yaml:"jwt"`
+
+	Password      Password      `yaml:"password"`
+	Notifier      Notifier      `yaml:"notifier"`
+	PasswordReset PasswordReset `yaml:"password_reset"`
+	// Admins lists ids of existing users that are given the admin role on startup.
+	// Admins missing from the list are demoted.
+	Admins []string `yaml:"admins"`
 }
 
 func New() *Config {
@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/avran02/fileshare/auth/internal/models"
+	pb "github.com/avran02/fileshare/proto/authpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (c *controller) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	if _, err := c.servcie.CheckAdmin(req.AccessToken); err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	users, total, err := c.servcie.ListUsers(req.Search, int(req.Page), int(req.PageSize))
+	if err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	resp := &pb.ListUsersResponse{
+		Users: make([]*pb.UserInfo, len(users)),
+		Total: int32(total),
+	}
+	for i, user := range users {
+		resp.Users[i] = userToPb(user)
+	}
+
+	return resp, nil
+}
+
+func (c *controller) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+	if _, err := c.servcie.CheckAdmin(req.AccessToken); err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	user, err := c.servcie.FindUser(req.Id, "")
+	if err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return &pb.GetUserResponse{
+		User: userToPb(user),
+	}, nil
+}
+
+func (c *controller) DisableUser(ctx context.Context, req *pb.DisableUserRequest) (*pb.DisableUserResponse, error) {
+	adminID, err := c.servcie.CheckAdmin(req.AccessToken)
+	if err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to disable user: %w", err)
+	}
+
+	if err = c.servcie.SetUserDisabled(adminID, req.Id, true); err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to disable user: %w", err)
+	}
+
+	return &pb.DisableUserResponse{
+		Success: true,
+	}, nil
+}
+
+func (c *controller) EnableUser(ctx context.Context, req *pb.EnableUserRequest) (*pb.EnableUserResponse, error) {
+	adminID, err := c.servcie.CheckAdmin(req.AccessToken)
+	if err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to enable user: %w", err)
+	}
+
+	if err = c.servcie.SetUserDisabled(adminID, req.Id, false); err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to enable user: %w", err)
+	}
+
+	return &pb.EnableUserResponse{
+		Success: true,
+	}, nil
+}
+
+func (c *controller) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+	adminID, err := c.servcie.CheckAdmin(req.AccessToken)
+	if err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	if err = c.servcie.DeleteUser(adminID, req.Id); err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	return &pb.DeleteUserResponse{
+		Success: true,
+	}, nil
+}
+
+func (c *controller) ForceLogout(ctx context.Context, req *pb.ForceLogoutRequest) (*pb.ForceLogoutResponse, error) {
+	if _, err := c.servcie.CheckAdmin(req.AccessToken); err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to force logout: %w", err)
+	}
+
+	if err := c.servcie.ForceLogout(req.Id); err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to force logout: %w", err)
+	}
+
+	return &pb.ForceLogoutResponse{
+		Success: true,
+	}, nil
+}
+
+func userToPb(user models.User) *pb.UserInfo {
+	info := &pb.UserInfo{
+		Id:           user.ID,
+		Username:     user.Username,
+		Role:         user.Role,
+		Disabled:     user.Disabled,
+		TotpEnabled:  user.TOTPEnabled,
+		FailedLogins: int32(user.FailedLogins),
+	}
+
+	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		info.LockedUntil = timestamppb.New(*user.LockedUntil)
+	}
+
+	return info
+}
+
+func (c *controller) GetSecuritySettings(ctx context.Context, req *pb.GetSecuritySettingsRequest) (*pb.GetSecuritySettingsResponse, error) {
+	if _, err := c.servcie.CheckAdmin(req.AccessToken); err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to get security settings: %w", err)
+	}
+
+	settings, err := c.servcie.GetSecuritySettings()
+	if err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to get security settings: %w", err)
+	}
+
+	return &pb.GetSecuritySettingsResponse{
+		TotpRequired: settings.TOTPRequired,
+	}, nil
+}
+
+func (c *controller) UpdateSecuritySettings(ctx context.Context, req *pb.UpdateSecuritySettingsRequest) (*pb.UpdateSecuritySettingsResponse, error) {
+	if _, err := c.servcie.CheckAdmin(req.AccessToken); err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to update security settings: %w", err)
+	}
+
+	if err := c.servcie.UpdateSecuritySettings(models.SecuritySettings{
+		TOTPRequired: req.TotpRequired,
+	}); err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to update security settings: %w", err)
+	}
+
+	return &pb.UpdateSecuritySettingsResponse{
+		Success: true,
+	}, nil
+}
@@ -4,10 +4,17 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/avran02/fileshare/auth/internal/service"
 	pb "github.com/avran02/fileshare/proto/authpb"
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	revocationPollInterval = time.Second
+	revocationResendWindow = 5 * time.Second
 )
 
 type Controller interface {
@@ -16,6 +23,38 @@ type Controller interface {
 	RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error)
 	ValidateToken(ctx context.Context, req *pb.ValidateTokenRequest) (*pb.ValidateTokenResponse, error)
 	Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error)
+	FindUser(ctx context.Context, req *pb.FindUserRequest) (*pb.FindUserResponse, error)
+
+	ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error)
+	RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error)
+	ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error)
+
+	ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error)
+	RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error)
+	RevokeOtherSessions(ctx context.Context, req *pb.RevokeOtherSessionsRequest) (*pb.RevokeOtherSessionsResponse, error)
+
+	VerifyLoginTOTP(ctx context.Context, req *pb.VerifyLoginTOTPRequest) (*pb.VerifyLoginTOTPResponse, error)
+	BeginTOTPEnrollment(ctx context.Context, req *pb.BeginTOTPEnrollmentRequest) (*pb.BeginTOTPEnrollmentResponse, error)
+	ConfirmTOTPEnrollment(ctx context.Context, req *pb.ConfirmTOTPEnrollmentRequest) (*pb.ConfirmTOTPEnrollmentResponse, error)
+	DisableTOTP(ctx context.Context, req *pb.DisableTOTPRequest) (*pb.DisableTOTPResponse, error)
+
+	CreatePersonalToken(ctx context.Context, req *pb.CreatePersonalTokenRequest) (*pb.CreatePersonalTokenResponse, error)
+	ListPersonalTokens(ctx context.Context, req *pb.ListPersonalTokensRequest) (*pb.ListPersonalTokensResponse, error)
+	RevokePersonalToken(ctx context.Context, req *pb.RevokePersonalTokenRequest) (*pb.RevokePersonalTokenResponse, error)
+
+	GetJWKS(ctx context.Context, req *pb.GetJWKSRequest) (*pb.GetJWKSResponse, error)
+	WatchRevocations(req *pb.WatchRevocationsRequest, stream pb.AuthService_WatchRevocationsServer) error
+
+	ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error)
+	GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error)
+	DisableUser(ctx context.Context, req *pb.DisableUserRequest) (*pb.DisableUserResponse, error)
+	EnableUser(ctx context.Context, req *pb.EnableUserRequest) (*pb.EnableUserResponse, error)
+	DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error)
+	ForceLogout(ctx context.Context, req *pb.ForceLogoutRequest) (*pb.ForceLogoutResponse, error)
+	GetSecuritySettings(ctx context.Context, req *pb.GetSecuritySettingsRequest) (*pb.GetSecuritySettingsResponse, error)
+	UpdateSecuritySettings(ctx context.Context, req *pb.UpdateSecuritySettingsRequest) (*pb.UpdateSecuritySettingsResponse, error)
+	ListLoginLockouts(ctx context.Context, req *pb.ListLoginLockoutsRequest) (*pb.ListLoginLockoutsResponse, error)
+	ClearLoginLockout(ctx context.Context, req *pb.ClearLoginLockoutRequest) (*pb.ClearLoginLockoutResponse, error)
 }
 
 // implements pb.AuthServiceServer.
@@ -26,7 +65,7 @@ type controller struct {
 func (c *controller) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
 	if err := c.servcie.Register(uuid.NewString(), req.Username, req.Password); err != nil {
 		slog.Info(err.Error())
-		return nil, fmt.Errorf("failed to register user: %w", err)
+		return nil, passwordError(err, "failed to register user")
 	}
 
 	resp := &pb.RegisterResponse{
@@ -37,38 +76,44 @@ func (c *controller) Register(ctx context.Context, req *pb.RegisterRequest) (*pb
 }
 
 func (c *controller) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
-	accessToken, refreshToken, err := c.servcie.Login(req.Username, req.Password)
+	result, err := c.servcie.Login(req.Username, req.Password, req.UserAgent, clientIP(ctx, req.Ip))
 	if err != nil {
 		slog.Info(err.Error())
-		return nil, fmt.Errorf("failed to login user: %w", err)
+		return nil, loginError(err)
 	}
 
 	return &pb.LoginResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		AccessToken:      result.AccessToken,
+		RefreshToken:     result.RefreshToken,
+		MfaRequired:      result.MFARequired,
+		MfaSetupRequired: result.MFASetupRequired,
+		ChallengeToken:   result.ChallengeToken,
 	}, nil
 }
 
 func (c *controller) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
-	token, err := c.servcie.RefreshToken(req.RefreshToken)
+	accessToken, refreshToken, err := c.servcie.RefreshToken(req.RefreshToken)
 	if err != nil {
 		slog.Info(err.Error())
 		return nil, fmt.Errorf("failed to refresh token: %w", err)
 	}
 
 	return &pb.RefreshTokenResponse{
-		AccessToken: token,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
 	}, nil
 }
 
 func (c *controller) ValidateToken(ctx context.Context, req *pb.ValidateTokenRequest) (*pb.ValidateTokenResponse, error) {
-	id, err := c.servcie.ValidateToken(req.AccessToken)
+	id, role, scopes, err := c.servcie.ValidateToken(req.AccessToken)
 	if err != nil {
 		slog.Info(err.Error())
 		return nil, fmt.Errorf("failed to validate token: %w", err)
 	}
 	return &pb.ValidateTokenResponse{
-		Id: id,
+		Id:     id,
+		Role:   role,
+		Scopes: scopes,
 	}, nil
 }
 
@@ -85,6 +130,127 @@ func (c *controller) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.Log
 	}, nil
 }
 
+func (c *controller) FindUser(ctx context.Context, req *pb.FindUserRequest) (*pb.FindUserResponse, error) {
+	user, err := c.servcie.FindUser(req.Id, req.Username)
+	if err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	return &pb.FindUserResponse{
+		Id:       user.ID,
+		Username: user.Username,
+		Role:     user.Role,
+	}, nil
+}
+
+func (c *controller) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	sessions, currentID, err := c.servcie.ListSessions(req.AccessToken)
+	if err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	resp := &pb.ListSessionsResponse{
+		Sessions: make([]*pb.SessionInfo, len(sessions)),
+	}
+	for i, session := range sessions {
+		resp.Sessions[i] = &pb.SessionInfo{
+			Id:         session.ID,
+			UserAgent:  session.UserAgent,
+			Ip:         session.IP,
+			CreatedAt:  timestamppb.New(session.CreatedAt),
+			LastUsedAt: timestamppb.New(session.LastUsedAt),
+			Current:    session.ID == currentID,
+		}
+	}
+
+	return resp, nil
+}
+
+func (c *controller) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	if err := c.servcie.RevokeSession(req.AccessToken, req.SessionID); err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return &pb.RevokeSessionResponse{
+		Success: true,
+	}, nil
+}
+
+func (c *controller) RevokeOtherSessions(ctx context.Context, req *pb.RevokeOtherSessionsRequest) (*pb.RevokeOtherSessionsResponse, error) {
+	if err := c.servcie.RevokeOtherSessions(req.AccessToken); err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return &pb.RevokeOtherSessionsResponse{
+		Success: true,
+	}, nil
+}
+
+func (c *controller) GetJWKS(ctx context.Context, req *pb.GetJWKSRequest) (*pb.GetJWKSResponse, error) {
+	keys := c.servcie.JWKS()
+
+	resp := &pb.GetJWKSResponse{
+		Keys: make([]*pb.JWK, len(keys)),
+	}
+	for i, key := range keys {
+		resp.Keys[i] = &pb.JWK{
+			Kid: key.Kid,
+			Kty: key.Kty,
+			Alg: key.Alg,
+			Use: key.Use,
+			N:   key.N,
+			E:   key.E,
+			Crv: key.Crv,
+			X:   key.X,
+		}
+	}
+
+	return resp, nil
+}
+
+// WatchRevocations polls for revoked sessions and streams them, ending every poll with
+// a heartbeat so the receiver can tell an up to date list from a broken stream.
+func (c *controller) WatchRevocations(req *pb.WatchRevocationsRequest, stream pb.AuthService_WatchRevocationsServer) error {
+	ctx := stream.Context()
+	afterID := req.AfterID
+
+	ticker := time.NewTicker(revocationPollInterval)
+	defer ticker.Stop()
+
+	for {
+		revocations, err := c.servcie.ListRevocations(afterID, time.Now().Add(-revocationResendWindow))
+		if err != nil {
+			return fmt.Errorf("failed to list revocations: %w", err)
+		}
+
+		for _, revocation := range revocations {
+			if err = stream.Send(&pb.Revocation{
+				Id:        revocation.ID,
+				UserID:    revocation.UserID,
+				SessionID: revocation.SessionID,
+				ExpiresAt: timestamppb.New(revocation.ExpiresAt),
+			}); err != nil {
+				return fmt.Errorf("failed to send revocation: %w", err)
+			}
+			afterID = max(afterID, revocation.ID)
+		}
+
+		if err = stream.Send(&pb.Revocation{Heartbeat: true}); err != nil {
+			return fmt.Errorf("failed to send heartbeat: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
 func New(service service.Service) Controller {
 	return &controller{
 		servcie: service,
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/avran02/fileshare/auth/internal/models"
+	"github.com/avran02/fileshare/auth/internal/service"
+	pb "github.com/avran02/fileshare/proto/authpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// clientIPMetadataKey carries the IP of the HTTP client the gateway calls on behalf of.
+const clientIPMetadataKey = "x-client-ip"
+
+func clientIP(ctx context.Context, fallback string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return fallback
+	}
+
+	if ips := md.Get(clientIPMetadataKey); len(ips) > 0 && ips[0] != "" {
+		return ips[0]
+	}
+
+	return fallback
+}
+
+// loginError gives failed logins a status code, so the gateway can tell bad
+// credentials and lockouts apart from other failures.
+func loginError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrTooManyAttempts):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, service.ErrInvalidCredentials), errors.Is(err, service.ErrInvalidCode), errors.Is(err, service.ErrChallengeUsed):
+		return status.Error(codes.Unauthenticated, err.Error())
+	default:
+		return fmt.Errorf("failed to login user: %w", err)
+	}
+}
+
+func (c *controller) ListLoginLockouts(ctx context.Context, req *pb.ListLoginLockoutsRequest) (*pb.ListLoginLockoutsResponse, error) {
+	if _, err := c.servcie.CheckAdmin(req.AccessToken); err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to list login lockouts: %w", err)
+	}
+
+	lockouts, err := c.servcie.ListLoginLockouts()
+	if err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to list login lockouts: %w", err)
+	}
+
+	resp := &pb.ListLoginLockoutsResponse{
+		Lockouts: make([]*pb.LoginLockout, len(lockouts)),
+	}
+	for i, lockout := range lockouts {
+		resp.Lockouts[i] = lockoutToPb(lockout)
+	}
+
+	return resp, nil
+}
+
+func (c *controller) ClearLoginLockout(ctx context.Context, req *pb.ClearLoginLockoutRequest) (*pb.ClearLoginLockoutResponse, error) {
+	if _, err := c.servcie.CheckAdmin(req.AccessToken); err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to clear login lockout: %w", err)
+	}
+
+	if err := c.servcie.ClearLoginLockout(req.Scope, req.Subject); err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to clear login lockout: %w", err)
+	}
+
+	return &pb.ClearLoginLockoutResponse{
+		Success: true,
+	}, nil
+}
+
+func lockoutToPb(lockout models.LoginAttempts) *pb.LoginLockout {
+	info := &pb.LoginLockout{
+		Scope:        lockout.Scope,
+		Subject:      lockout.Subject,
+		Failures:     int32(lockout.Failures),
+		LastFailedAt: timestamppb.New(lockout.LastFailedAt),
+	}
+
+	if lockout.LockedUntil != nil {
+		info.LockedUntil = timestamppb.New(*lockout.LockedUntil)
+	}
+
+	return info
+}
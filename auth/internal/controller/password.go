@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/avran02/fileshare/auth/internal/pkg/passwd"
+	"github.com/avran02/fileshare/auth/internal/service"
+	pb "github.com/avran02/fileshare/proto/authpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (c *controller) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	if err := c.servcie.ChangePassword(req.AccessToken, req.CurrentPassword, req.NewPassword, clientIP(ctx, "")); err != nil {
+		slog.Info(err.Error())
+		return nil, passwordError(err, "failed to change password")
+	}
+
+	return &pb.ChangePasswordResponse{
+		Success: true,
+	}, nil
+}
+
+func (c *controller) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	if err := c.servcie.RequestPasswordReset(req.Username, clientIP(ctx, "")); err != nil {
+		slog.Info(err.Error())
+		return nil, passwordError(err, "failed to request password reset")
+	}
+
+	return &pb.RequestPasswordResetResponse{
+		Success: true,
+	}, nil
+}
+
+func (c *controller) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	if err := c.servcie.ResetPassword(req.Token, req.NewPassword); err != nil {
+		slog.Info(err.Error())
+		return nil, passwordError(err, "failed to reset password")
+	}
+
+	return &pb.ResetPasswordResponse{
+		Success: true,
+	}, nil
+}
+
+// passwordError marks passwords the caller got wrong as invalid arguments and
+// throttled attempts as exhausted, so the gateway can answer them with 400
+// and 429 instead of 500.
+func passwordError(err error, msg string) error {
+	switch {
+	case errors.Is(err, service.ErrTooManyAttempts):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, passwd.ErrPasswordTooShort), errors.Is(err, passwd.ErrPasswordTooLong), errors.Is(err, passwd.ErrPasswordBreached),
+		errors.Is(err, service.ErrWrongPassword), errors.Is(err, service.ErrInvalidResetToken):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+}
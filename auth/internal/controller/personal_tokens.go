@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/avran02/fileshare/auth/internal/models"
+	pb "github.com/avran02/fileshare/proto/authpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (c *controller) CreatePersonalToken(ctx context.Context, req *pb.CreatePersonalTokenRequest) (*pb.CreatePersonalTokenResponse, error) {
+	token, personalToken, err := c.servcie.CreatePersonalToken(req.AccessToken, req.Name, req.Scopes, int(req.ExpiresInDays))
+	if err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to create personal token: %w", err)
+	}
+
+	return &pb.CreatePersonalTokenResponse{
+		Token: token,
+		Info:  personalTokenToPb(personalToken),
+	}, nil
+}
+
+func (c *controller) ListPersonalTokens(ctx context.Context, req *pb.ListPersonalTokensRequest) (*pb.ListPersonalTokensResponse, error) {
+	tokens, err := c.servcie.ListPersonalTokens(req.AccessToken)
+	if err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to list personal tokens: %w", err)
+	}
+
+	resp := &pb.ListPersonalTokensResponse{
+		Tokens: make([]*pb.PersonalTokenInfo, len(tokens)),
+	}
+	for i, token := range tokens {
+		resp.Tokens[i] = personalTokenToPb(token)
+	}
+
+	return resp, nil
+}
+
+func (c *controller) RevokePersonalToken(ctx context.Context, req *pb.RevokePersonalTokenRequest) (*pb.RevokePersonalTokenResponse, error) {
+	if err := c.servcie.RevokePersonalToken(req.AccessToken, req.Id); err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to revoke personal token: %w", err)
+	}
+
+	return &pb.RevokePersonalTokenResponse{
+		Success: true,
+	}, nil
+}
+
+func personalTokenToPb(token models.PersonalToken) *pb.PersonalTokenInfo {
+	info := &pb.PersonalTokenInfo{
+		Id:        token.ID,
+		Name:      token.Name,
+		Prefix:    token.Prefix,
+		Scopes:    token.Scopes,
+		CreatedAt: timestamppb.New(token.CreatedAt),
+		ExpiresAt: timestamppb.New(token.ExpiresAt),
+	}
+
+	if token.LastUsedAt != nil {
+		info.LastUsedAt = timestamppb.New(*token.LastUsedAt)
+	}
+
+	return info
+}
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	pb "github.com/avran02/fileshare/proto/authpb"
+)
+
+func (c *controller) VerifyLoginTOTP(ctx context.Context, req *pb.VerifyLoginTOTPRequest) (*pb.VerifyLoginTOTPResponse, error) {
+	accessToken, refreshToken, err := c.servcie.VerifyLoginTOTP(req.ChallengeToken, req.Code, req.UserAgent, clientIP(ctx, req.Ip))
+	if err != nil {
+		slog.Info(err.Error())
+		return nil, loginError(err)
+	}
+
+	return &pb.VerifyLoginTOTPResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func (c *controller) BeginTOTPEnrollment(ctx context.Context, req *pb.BeginTOTPEnrollmentRequest) (*pb.BeginTOTPEnrollmentResponse, error) {
+	secret, uri, err := c.servcie.BeginTOTPEnrollment(req.Token)
+	if err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to begin totp enrollment: %w", err)
+	}
+
+	return &pb.BeginTOTPEnrollmentResponse{
+		Secret:          secret,
+		ProvisioningURI: uri,
+	}, nil
+}
+
+func (c *controller) ConfirmTOTPEnrollment(ctx context.Context, req *pb.ConfirmTOTPEnrollmentRequest) (*pb.ConfirmTOTPEnrollmentResponse, error) {
+	codes, login, err := c.servcie.ConfirmTOTPEnrollment(req.Token, req.Code, req.UserAgent, clientIP(ctx, req.Ip))
+	if err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to confirm totp enrollment: %w", err)
+	}
+
+	return &pb.ConfirmTOTPEnrollmentResponse{
+		RecoveryCodes: codes,
+		AccessToken:   login.AccessToken,
+		RefreshToken:  login.RefreshToken,
+	}, nil
+}
+
+func (c *controller) DisableTOTP(ctx context.Context, req *pb.DisableTOTPRequest) (*pb.DisableTOTPResponse, error) {
+	if err := c.servcie.DisableTOTP(req.AccessToken, req.Code); err != nil {
+		slog.Info(err.Error())
+		return nil, fmt.Errorf("failed to disable totp: %w", err)
+	}
+
+	return &pb.DisableTOTPResponse{
+		Success: true,
+	}, nil
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+const (
+	AttemptScopeUsername = "username"
+	AttemptScopeIP       = "ip"
+
+	// password reset requests are counted apart from failed logins
+	AttemptScopeResetUsername = "reset_username"
+	AttemptScopeResetIP       = "reset_ip"
+)
+
+// LoginAttempts counts recent failed logins, or password reset requests, for
+// a username or a client IP.
+type LoginAttempts struct {
+	Scope        string
+	Subject      string
+	Failures     int
+	LastFailedAt time.Time
+	LockedUntil  *time.Time
+}
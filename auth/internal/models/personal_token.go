@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PersonalToken is a long-lived API key. Only the hash of the secret is stored.
+type PersonalToken struct {
+	ID         string
+	UserID     string
+	Name       string
+	Prefix     string
+	TokenHash  string
+	Scopes     []string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	LastUsedAt *time.Time
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Revocation records a session that ended while its access token was still valid.
+type Revocation struct {
+	ID        int64
+	UserID    string
+	SessionID string
+	RevokedAt time.Time
+	ExpiresAt time.Time
+}
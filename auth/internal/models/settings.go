@@ -0,0 +1,6 @@
+package models
+
+// SecuritySettings are instance-wide settings admins can change at runtime.
+type SecuritySettings struct {
+	TOTPRequired bool
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey []byte
+	CreatedAt  time.Time
+	// ExpiresAt is nil while the key signs new tokens. Retired keys only verify until then.
+	ExpiresAt *time.Time
+}
@@ -7,6 +7,7 @@ import (
 type Token struct {
 	ID        uint64
 	UserID    string
+	SessionID string
 	Type      string
 	Token     string
 	ExpiresAt time.Time
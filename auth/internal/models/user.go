@@ -1,7 +1,23 @@
 package models
 
+import "time"
+
 type User struct {
 	ID       string
 	Username string
 	Password string
+	Role     string
+	Disabled bool
+	// TOTPSecret is set once enrollment starts, TOTPEnabled only after the first code was verified.
+	TOTPSecret   string
+	TOTPEnabled  bool
+	TOTPLastStep int64
+	FailedLogins int
+	LockedUntil  *time.Time
+}
+
+type RecoveryCode struct {
+	ID       int64
+	UserID   string
+	CodeHash string
 }
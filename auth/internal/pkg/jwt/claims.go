@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 var oneWeekDuration = 7 * 24 * time.Hour
@@ -11,18 +12,42 @@ var oneWeekDuration = 7 * 24 * time.Hour
 const (
 	accessTokenType  = "access"
 	refreshTokenType = "refresh"
+
+	// ChallengeMFA lets a user whose password checked out finish logging in with a second factor.
+	ChallengeMFA = "mfa"
+	// ChallengeMFASetup lets a user who must have 2FA but has none enroll before logging in.
+	ChallengeMFASetup = "mfa_setup"
+
+	challengeLifetime = 5 * time.Minute
 )
 
-type claims struct { // TODO: add roles
-	Type string `json:"type"`
+// Challenge is what a challenge token says about the login it belongs to.
+type Challenge struct {
+	ID        string
+	UserID    string
+	ExpiresAt time.Time
+}
+
+// Payload is what a token says about its bearer.
+type Payload struct {
+	UserID    string
+	Role      string
+	SessionID string
+	IsAccess  bool
+}
+
+type claims struct {
+	Type      string `json:"type"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-func newClaims(userId string, isAccess bool, exp int) claims { //nolint
+func newClaims(payload Payload, exp int) claims {
 	var expTime time.Time
 	var tokenType string
 
-	if isAccess {
+	if payload.IsAccess {
 		expTime = time.Now().Add(time.Duration(exp) * time.Second)
 		tokenType = accessTokenType
 	} else {
@@ -31,9 +56,12 @@ func newClaims(userId string, isAccess bool, exp int) claims { //nolint
 	}
 
 	return claims{
-		Type: tokenType,
+		Type:      tokenType,
+		Role:      payload.Role,
+		SessionID: payload.SessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			Subject:   userId,
+			ID:        uuid.NewString(),
+			Subject:   payload.UserID,
 			ExpiresAt: jwt.NewNumericDate(expTime),
 		},
 	}
@@ -7,4 +7,8 @@ var (
 	ErrWrongTokenType = errors.New("wrong token type")
 	ErrExpiredToken   = errors.New("token expired")
 	ErrEmptyToken     = errors.New("token is empty")
+	ErrUnknownKey     = errors.New("unknown signing key")
+	ErrNoSigningKey   = errors.New("no active signing key")
+
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
 )
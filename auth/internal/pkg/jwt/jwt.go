@@ -1,84 +1,347 @@
 package jwt
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/avran02/fileshare/auth/internal/config"
 	"github.com/avran02/fileshare/auth/internal/models"
+	pb "github.com/avran02/fileshare/proto/authpb"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	keyCheckInterval  = time.Minute
+	minReloadInterval = 10 * time.Second
 )
 
 type JwtGenerator interface {
-	Generate(id string, isAccess bool) (models.Token, error)
-	Validate(token string) (userId string, isRefresh bool, err error) //nolint
+	Generate(payload Payload) (models.Token, error)
+	Validate(token string) (Payload, error)
+	GenerateChallenge(userID, kind string) (string, error)
+	ValidateChallenge(token, kind string) (Challenge, error)
+
+	JWKS() []JWK
+	RunRotation(ctx context.Context)
 }
 
 type jwtToken struct {
 	config.JWT
+	store KeyStore
+
+	mu         sync.RWMutex
+	keys       map[string]*signingKey
+	current    *signingKey
+	reloadedAt time.Time
 }
 
-func (j *jwtToken) Generate(id string, isAccess bool) (models.Token, error) {
-	var tokenType string
-	claims := newClaims(id, isAccess, j.Exp)
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+func (j *jwtToken) Generate(payload Payload) (models.Token, error) {
+	j.mu.RLock()
+	key := j.current
+	j.mu.RUnlock()
 
-	signedToken, err := token.SignedString([]byte(j.Secret))
-	if err != nil {
-		return models.Token{}, fmt.Errorf("failed to sign token: %w", err)
+	if key == nil {
+		return models.Token{}, ErrNoSigningKey
 	}
 
-	if isAccess {
-		tokenType = accessTokenType
-	} else {
-		tokenType = refreshTokenType
+	claims := newClaims(payload, j.Exp)
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+
+	signedToken, err := token.SignedString(key.private)
+	if err != nil {
+		return models.Token{}, fmt.Errorf("failed to sign token: %w", err)
 	}
 
 	return models.Token{
-		UserID:    id,
-		Type:      tokenType,
+		UserID:    payload.UserID,
+		SessionID: payload.SessionID,
+		Type:      claims.Type,
 		Token:     signedToken,
 		ExpiresAt: claims.ExpiresAt.Time,
 	}, nil
 }
 
-func (j *jwtToken) Validate(token string) (userID string, isAccess bool, err error) {
-	if token == "" {
-		return "", false, ErrEmptyToken
+func (j *jwtToken) Validate(token string) (Payload, error) {
+	claims, err := j.parse(token)
+	if err != nil {
+		return Payload{}, err
 	}
 
-	parsedToken, err := jwt.ParseWithClaims(token, &claims{}, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, ErrInvalidToken
-		}
-		return []byte(j.Secret), nil
+	if claims.Type != refreshTokenType && claims.Type != accessTokenType {
+		return Payload{}, ErrInvalidToken
+	}
+
+	role := claims.Role
+	if role == "" {
+		role = pb.RoleUser
+	}
+
+	return Payload{
+		UserID:    claims.Subject,
+		Role:      role,
+		SessionID: claims.SessionID,
+		IsAccess:  claims.Type == accessTokenType,
+	}, nil
+}
+
+// GenerateChallenge issues a short-lived token proving the password step of a login
+// passed. It is only good for finishing that login with a second factor.
+func (j *jwtToken) GenerateChallenge(userID, kind string) (string, error) {
+	j.mu.RLock()
+	key := j.current
+	j.mu.RUnlock()
+
+	if key == nil {
+		return "", ErrNoSigningKey
+	}
+
+	token := jwt.NewWithClaims(key.method, claims{
+		Type: kind,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(challengeLifetime)),
+		},
 	})
+	token.Header["kid"] = key.id
+
+	signedToken, err := token.SignedString(key.private)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return signedToken, nil
+}
+
+// ValidateChallenge checks a challenge signed with one of the signing keys. The
+// caller is responsible for spending its ID so that it can't be replayed.
+func (j *jwtToken) ValidateChallenge(token, kind string) (Challenge, error) {
+	claims, err := j.parse(token)
+	if err != nil {
+		return Challenge{}, err
+	}
+
+	if claims.Type != kind {
+		return Challenge{}, ErrWrongTokenType
+	}
+
+	if claims.ID == "" || claims.Subject == "" {
+		return Challenge{}, ErrInvalidToken
+	}
+
+	return Challenge{
+		ID:        claims.ID,
+		UserID:    claims.Subject,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
+
+func (j *jwtToken) parse(token string) (*claims, error) {
+	if token == "" {
+		return nil, ErrEmptyToken
+	}
+
+	methods := []string{AlgorithmRS256, AlgorithmEdDSA}
+	parsedToken, err := jwt.ParseWithClaims(token, &claims{}, j.verificationKey, jwt.WithValidMethods(methods))
 	if err != nil {
-		return "", false, fmt.Errorf("failed to parse token: %w", err)
+		return nil, fmt.Errorf("failed to parse token: %w", err)
 	}
 
 	if !parsedToken.Valid {
-		return "", false, ErrInvalidToken
+		return nil, ErrInvalidToken
 	}
 
 	claims, ok := parsedToken.Claims.(*claims)
 	if !ok {
-		return "", false, ErrInvalidToken
+		return nil, ErrInvalidToken
 	}
 
-	if claims.Type != refreshTokenType && claims.Type != accessTokenType {
-		return "", false, ErrInvalidToken
+	if claims.ExpiresAt == nil || time.Now().After(claims.ExpiresAt.Time) {
+		return nil, ErrExpiredToken
+	}
+
+	return claims, nil
+}
+
+// verificationKey picks the key a token was signed with by its kid header.
+func (j *jwtToken) verificationKey(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		return nil, ErrInvalidToken
+	}
+
+	j.mu.RLock()
+	key, ok := j.keys[kid]
+	j.mu.RUnlock()
+
+	if !ok {
+		// another instance may have rotated the key since the last reload
+		j.mu.RLock()
+		stale := time.Since(j.reloadedAt) >= minReloadInterval
+		j.mu.RUnlock()
+		if !stale {
+			return nil, ErrUnknownKey
+		}
+
+		if err := j.reload(); err != nil {
+			return nil, err
+		}
+
+		j.mu.RLock()
+		key, ok = j.keys[kid]
+		j.mu.RUnlock()
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+	}
+
+	if t.Method.Alg() != key.method.Alg() {
+		return nil, ErrInvalidToken
+	}
+
+	return key.private.Public(), nil
+}
+
+// JWKS returns the public halves of all keys tokens may still be signed with.
+func (j *jwtToken) JWKS() []JWK {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	keys := make([]JWK, 0, len(j.keys))
+	for _, key := range j.keys {
+		keys = append(keys, key.jwk())
+	}
+
+	return keys
+}
+
+// rotate makes a fresh key the signing key. Previous keys keep verifying
+// until every token they could have signed has expired.
+func (j *jwtToken) rotate() error {
+	key, err := generateKey(uuid.NewString(), j.Algorithm)
+	if err != nil {
+		return err
+	}
+
+	if err = j.store.CreateSigningKey(key); err != nil {
+		return fmt.Errorf("failed to store signing key: %w", err)
+	}
+
+	if err = j.store.RetireSigningKeys(key.ID, time.Now().Add(j.maxTokenLifetime())); err != nil {
+		return fmt.Errorf("failed to retire signing keys: %w", err)
+	}
+
+	slog.Info("rotated signing key, new kid: " + key.ID)
+	return j.reload()
+}
+
+// RunRotation rotates the signing key once it is older than the configured
+// rotation interval and drops keys nothing can be verified with anymore.
+func (j *jwtToken) RunRotation(ctx context.Context) {
+	ticker := time.NewTicker(keyCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := j.store.DeleteExpiredSigningKeys(); err != nil {
+			slog.Error("failed to delete expired signing keys: " + err.Error())
+		}
+
+		if err := j.reload(); err != nil {
+			slog.Error("failed to reload signing keys: " + err.Error())
+			continue
+		}
+
+		if !j.rotationDue() {
+			continue
+		}
+
+		if err := j.rotate(); err != nil {
+			slog.Error("failed to rotate signing key: " + err.Error())
+		}
+	}
+}
+
+func (j *jwtToken) rotationDue() bool {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	if j.current == nil || j.current.method.Alg() != j.Algorithm {
+		return true
+	}
+
+	return j.Rotation > 0 && time.Since(j.current.createdAt) >= j.Rotation
+}
+
+func (j *jwtToken) reload() error {
+	stored, err := j.store.ListSigningKeys()
+	if err != nil {
+		return fmt.Errorf("failed to list signing keys: %w", err)
+	}
+
+	keys := make(map[string]*signingKey, len(stored))
+	var current *signingKey
+	for _, s := range stored {
+		key, err := parseKey(s)
+		if err != nil {
+			slog.Error(err.Error())
+			continue
+		}
+
+		keys[key.id] = key
+		if key.active && (current == nil || key.createdAt.After(current.createdAt)) {
+			current = key
+		}
 	}
 
-	if time.Now().After(claims.ExpiresAt.Time) {
-		return "", false, ErrExpiredToken
+	j.mu.Lock()
+	j.keys = keys
+	j.current = current
+	j.reloadedAt = time.Now()
+	j.mu.Unlock()
+
+	return nil
+}
+
+func (j *jwtToken) maxTokenLifetime() time.Duration {
+	accessLifetime := time.Duration(j.Exp) * time.Second
+	if accessLifetime > oneWeekDuration {
+		return accessLifetime
 	}
 
-	return claims.Subject, claims.Type == accessTokenType, nil
+	return oneWeekDuration
 }
 
-func New(config config.JWT) JwtGenerator {
-	return &jwtToken{
-		JWT: config,
+func New(config config.JWT, store KeyStore) JwtGenerator {
+	if config.Algorithm == "" {
+		config.Algorithm = AlgorithmEdDSA
+	}
+
+	j := &jwtToken{
+		JWT:   config,
+		store: store,
+	}
+
+	if err := j.reload(); err != nil {
+		log.Fatal("can't load signing keys:\n", err)
 	}
+
+	if j.rotationDue() {
+		if err := j.rotate(); err != nil {
+			log.Fatal("can't create signing key:\n", err)
+		}
+	}
+
+	return j
 }
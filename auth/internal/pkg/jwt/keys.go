@@ -0,0 +1,131 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/avran02/fileshare/auth/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+
+	rsaKeyBits = 2048
+)
+
+// KeyStore persists signing keys, so every auth instance signs and verifies with the same set.
+type KeyStore interface {
+	ListSigningKeys() ([]models.SigningKey, error)
+	CreateSigningKey(key models.SigningKey) error
+	RetireSigningKeys(exceptID string, verifyUntil time.Time) error
+	DeleteExpiredSigningKeys() error
+}
+
+// JWK is a public verification key as published in the JWKS document.
+type JWK struct {
+	Kid string
+	Kty string
+	Alg string
+	Use string
+	N   string
+	E   string
+	Crv string
+	X   string
+}
+
+type signingKey struct {
+	id        string
+	method    jwt.SigningMethod
+	private   crypto.Signer
+	createdAt time.Time
+	active    bool
+}
+
+func (k *signingKey) jwk() JWK {
+	key := JWK{
+		Kid: k.id,
+		Alg: k.method.Alg(),
+		Use: "sig",
+	}
+
+	switch pub := k.private.Public().(type) {
+	case *rsa.PublicKey:
+		key.Kty = "RSA"
+		key.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		key.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		key.Kty = "OKP"
+		key.Crv = "Ed25519"
+		key.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+
+	return key
+}
+
+func generateKey(id, algorithm string) (models.SigningKey, error) {
+	var private crypto.Signer
+	var err error
+
+	switch algorithm {
+	case AlgorithmRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgorithmEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return models.SigningKey{}, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, algorithm)
+	}
+	if err != nil {
+		return models.SigningKey{}, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return models.SigningKey{}, fmt.Errorf("failed to marshal key: %w", err)
+	}
+
+	return models.SigningKey{
+		ID:         id,
+		Algorithm:  algorithm,
+		PrivateKey: der,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+func parseKey(key models.SigningKey) (*signingKey, error) {
+	var method jwt.SigningMethod
+	switch key.Algorithm {
+	case AlgorithmRS256:
+		method = jwt.SigningMethodRS256
+	case AlgorithmEdDSA:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, key.Algorithm)
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(key.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key %s: %w", key.ID, err)
+	}
+
+	private, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, key.Algorithm)
+	}
+
+	return &signingKey{
+		id:        key.ID,
+		method:    method,
+		private:   private,
+		createdAt: key.CreatedAt,
+		active:    key.ExpiresAt == nil,
+	}, nil
+}
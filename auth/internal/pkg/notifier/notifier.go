@@ -0,0 +1,38 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/avran02/fileshare/auth/internal/config"
+)
+
+const (
+	TypeLog  = "log"
+	TypeFile = "file"
+)
+
+type Message struct {
+	Username string
+	Subject  string
+	Body     string
+}
+
+// Notifier delivers messages to users out of band, e.g. password reset links.
+type Notifier interface {
+	Send(msg Message) error
+}
+
+func New(conf config.Notifier) (Notifier, error) {
+	switch conf.Type {
+	case "", TypeLog:
+		return &logNotifier{}, nil
+	case TypeFile:
+		if conf.Path == "" {
+			return nil, errors.New("file notifier needs a path")
+		}
+		return &fileNotifier{path: conf.Path}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier type %q", conf.Type)
+	}
+}
@@ -0,0 +1,138 @@
+package passwd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/avran02/fileshare/auth/internal/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("unsupported password hashing algorithm")
+	ErrMalformedHash        = errors.New("malformed password hash")
+)
+
+// Hasher hashes passwords into self-describing strings, so hashes made with
+// older algorithms or parameters keep verifying after the config changes.
+type Hasher interface {
+	Hash(password string) (string, error)
+	Verify(password, encoded string) (bool, error)
+	// NeedsRehash reports whether encoded was made with another algorithm
+	// or weaker parameters than the configured ones.
+	NeedsRehash(encoded string) bool
+}
+
+type hasher struct {
+	algorithm  string
+	argon2     argon2Params
+	bcryptCost int
+}
+
+func (h *hasher) Hash(password string) (string, error) {
+	if h.algorithm == AlgorithmBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash password: %w", err)
+		}
+		return string(hash), nil
+	}
+
+	return hashArgon2id(password, h.argon2)
+}
+
+func (h *hasher) Verify(password, encoded string) (bool, error) {
+	switch algorithmOf(encoded) {
+	case AlgorithmArgon2id:
+		return verifyArgon2id(password, encoded)
+	case AlgorithmBcrypt:
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("%w: %w", ErrMalformedHash, err)
+		}
+		return true, nil
+	default:
+		return false, ErrMalformedHash
+	}
+}
+
+func (h *hasher) NeedsRehash(encoded string) bool {
+	algorithm := algorithmOf(encoded)
+	if algorithm != h.algorithm {
+		return true
+	}
+
+	if algorithm == AlgorithmBcrypt {
+		cost, err := bcrypt.Cost([]byte(encoded))
+		return err != nil || cost < h.bcryptCost
+	}
+
+	params, _, _, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+
+	return params.memory < h.argon2.memory || params.time < h.argon2.time || params.threads < h.argon2.threads
+}
+
+func algorithmOf(encoded string) string {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return AlgorithmArgon2id
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return AlgorithmBcrypt
+	default:
+		return ""
+	}
+}
+
+func New(conf config.Password) (Hasher, error) {
+	h := &hasher{
+		algorithm: conf.Algorithm,
+		argon2: argon2Params{
+			memory:  conf.Argon2.Memory,
+			time:    conf.Argon2.Time,
+			threads: conf.Argon2.Parallelism,
+		},
+		bcryptCost: conf.BcryptCost,
+	}
+
+	if h.algorithm == "" {
+		h.algorithm = AlgorithmArgon2id
+	}
+
+	if h.algorithm != AlgorithmArgon2id && h.algorithm != AlgorithmBcrypt {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, h.algorithm)
+	}
+
+	if h.argon2.memory == 0 {
+		h.argon2.memory = defaultArgon2Memory
+	}
+
+	if h.argon2.time == 0 {
+		h.argon2.time = defaultArgon2Time
+	}
+
+	if h.argon2.threads == 0 {
+		h.argon2.threads = defaultArgon2Threads
+	}
+
+	if h.bcryptCost == 0 {
+		h.bcryptCost = bcrypt.DefaultCost
+	}
+
+	if h.bcryptCost < bcrypt.MinCost || h.bcryptCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	return h, nil
+}
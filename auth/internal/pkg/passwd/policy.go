@@ -0,0 +1,85 @@
+package passwd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/avran02/fileshare/auth/internal/config"
+)
+
+const (
+	defaultMinLength = 8
+	// hashing is deliberately slow, so very long inputs are refused
+	maxLength = 256
+)
+
+var (
+	ErrPasswordTooShort = errors.New("password is too short")
+	ErrPasswordTooLong  = errors.New("password is too long")
+	ErrPasswordBreached = errors.New("password appears in a list of breached passwords")
+)
+
+// Policy decides whether a password is acceptable for new or changed accounts.
+type Policy interface {
+	Validate(password string) error
+}
+
+type policy struct {
+	minLength int
+	breached  map[string]struct{}
+}
+
+func (p *policy) Validate(password string) error {
+	if utf8.RuneCountInString(password) < p.minLength {
+		return fmt.Errorf("%w: at least %d characters are required", ErrPasswordTooShort, p.minLength)
+	}
+
+	if len(password) > maxLength {
+		return ErrPasswordTooLong
+	}
+
+	if _, ok := p.breached[password]; ok {
+		return ErrPasswordBreached
+	}
+
+	return nil
+}
+
+// NewPolicy loads the breached password list, a file with one password per line.
+func NewPolicy(conf config.Password) (Policy, error) {
+	p := &policy{
+		minLength: conf.MinLength,
+		breached:  make(map[string]struct{}),
+	}
+
+	if p.minLength <= 0 {
+		p.minLength = defaultMinLength
+	}
+
+	if conf.BreachedList == "" {
+		return p, nil
+	}
+
+	f, err := os.Open(conf.BreachedList)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimRight(scanner.Text(), "\r"); line != "" {
+			p.breached[line] = struct{}{}
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached password list: %w", err)
+	}
+
+	return p, nil
+}
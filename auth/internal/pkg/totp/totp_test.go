@@ -0,0 +1,80 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 seed of the RFC 6238 appendix B test vectors.
+var rfcSecret = encoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestValidateRFC6238Vectors(t *testing.T) {
+	// the RFC lists 8 digit codes, a 6 digit code is their last 6 digits
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+		{unix: 20000000000, code: "353130"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, time.Unix(tt.unix, 0))
+			if !ok {
+				t.Fatalf("Validate(%q) at %d failed", tt.code, tt.unix)
+			}
+			if want := tt.unix / period; step != want {
+				t.Errorf("step = %d, want %d", step, want)
+			}
+		})
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	// 287082 is the code of step 1, valid from 30s to 59s
+	tests := []struct {
+		name string
+		unix int64
+		ok   bool
+	}{
+		{name: "two steps early", unix: -31, ok: false},
+		{name: "one step early", unix: 0, ok: true},
+		{name: "current step", unix: 30, ok: true},
+		{name: "one step late", unix: 60, ok: true},
+		{name: "two steps late", unix: 90, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, "287082", time.Unix(tt.unix, 0))
+			if ok != tt.ok {
+				t.Fatalf("Validate at %d = %v, want %v", tt.unix, ok, tt.ok)
+			}
+			if ok && step != 1 {
+				t.Errorf("step = %d, want 1", step)
+			}
+		})
+	}
+}
+
+func TestValidateRejectsMalformedInput(t *testing.T) {
+	codes := map[string]string{
+		"short code":   "28708",
+		"8 digit code": "94287082",
+		"wrong code":   "287083",
+	}
+	for name, code := range codes {
+		if _, ok := Validate(rfcSecret, code, time.Unix(59, 0)); ok {
+			t.Errorf("%s: Validate(%q) succeeded", name, code)
+		}
+	}
+
+	if _, ok := Validate("not base32!", "287082", time.Unix(59, 0)); ok {
+		t.Error("Validate() succeeded with an invalid secret")
+	}
+}
@@ -6,36 +6,98 @@ import (
 	"fmt"
 	"log"
 	"log/slog"
+	"strconv"
+	"time"
 
 	"github.com/avran02/fileshare/auth/internal/config"
 	"github.com/avran02/fileshare/auth/internal/models"
+	pb "github.com/avran02/fileshare/proto/authpb"
 	"github.com/google/uuid"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
-var ErrUserNotFound = errors.New("user does not exist")
+var (
+	ErrUserNotFound    = errors.New("user does not exist")
+	ErrSessionNotFound = errors.New("session does not exist")
+	ErrTokenNotFound   = errors.New("token does not exist")
+	ErrTokenReused     = errors.New("refresh token was already used")
+
+	ErrPersonalTokenNotFound = errors.New("personal access token does not exist")
+	ErrResetTokenNotFound    = errors.New("password reset token does not exist or expired")
+)
 
 type Repo interface {
-	CreateUser(username, password string) error
+	CreateUser(username, password, role string) error
 	FindUserByUsername(username string) (models.User, error)
-	DeleteUserTokensAndWriteNew(userID string, accessToken, refreshToken models.Token) error
+	FindUserByID(id string) (models.User, error)
 	CheckTokenExists(token string) (bool, error)
-	DeleteAllUserTokens(userID string) error
-	ReplaceUserAccessToken(accessToken models.Token) error
+
+	CreateSession(session models.Session, accessToken, refreshToken models.Token) error
+	RotateRefreshToken(oldToken string, accessToken, refreshToken models.Token) error
+	TouchSession(sessionID string) error
+	ListSessions(userID string) ([]models.Session, error)
+	DeleteSession(userID, sessionID string) error
+	DeleteOtherSessions(userID, keepSessionID string) error
+
+	ListUsers(search string, limit, offset int) ([]models.User, int, error)
+	SetUserDisabled(id string, disabled bool) error
+	SetUserRole(id, role string) error
+	DemoteAdmins(keep []string) (int64, error)
+	DeleteUser(id string) error
+	RevokeUserSessions(userID string) error
+
+	ListRevocations(afterID int64, since time.Time) ([]models.Revocation, error)
+
+	SetTOTPSecret(userID, secret string) error
+	EnableTOTP(userID string, recoveryCodeHashes []string) error
+	DisableTOTP(userID string) error
+	UseTOTPStep(userID string, step int64) (bool, error)
+	ListRecoveryCodes(userID string) ([]models.RecoveryCode, error)
+	UseRecoveryCode(id int64) (bool, error)
+	UseChallenge(id string, expiresAt time.Time) (bool, error)
+	DeleteExpiredChallenges() error
+
+	GetLoginAttempts(scope, subject string) (models.LoginAttempts, error)
+	RecordLoginFailure(scope, subject string, window time.Duration) (failures int, err error)
+	LockLogin(scope, subject string, until time.Time) error
+	ClearLoginAttempts(scope, subject string) error
+	ListLoginLockouts() ([]models.LoginAttempts, error)
+	DeleteStaleLoginAttempts() error
+
+	ChangePassword(userID, keepSessionID, passwordHash string) error
+	UpgradePasswordHash(userID, oldHash, newHash string) error
+	CreatePasswordReset(reset models.PasswordReset) error
+	ResetPassword(tokenHash, passwordHash string) (userID string, err error)
+	DeleteExpiredPasswordResets() error
+
+	GetSecuritySettings() (models.SecuritySettings, error)
+	UpdateSecuritySettings(settings models.SecuritySettings) error
+
+	CreatePersonalToken(token models.PersonalToken) error
+	FindPersonalTokenByHash(hash string) (models.PersonalToken, error)
+	ListPersonalTokens(userID string) ([]models.PersonalToken, error)
+	DeletePersonalToken(userID, id string) error
+	TouchPersonalToken(id string) error
+	DeleteExpiredRevocations() error
+
+	ListSigningKeys() ([]models.SigningKey, error)
+	CreateSigningKey(key models.SigningKey) error
+	RetireSigningKeys(exceptID string, verifyUntil time.Time) error
+	DeleteExpiredSigningKeys() error
 }
 
 type repo struct {
 	*sql.DB
 }
 
-func (r *repo) CreateUser(username, password string) error {
+func (r *repo) CreateUser(username, password, role string) error {
 	id := uuid.New().String()
 	query := `
-        INSERT INTO users (id, username, password)
-        VALUES ($1, $2, $3)
+        INSERT INTO users (id, username, password, role)
+        VALUES ($1, $2, $3, $4)
     `
 
-	_, err := r.Exec(query, id, username, password)
+	_, err := r.Exec(query, id, username, password, role)
 	if err != nil {
 		return err
 	}
@@ -43,26 +105,58 @@ func (r *repo) CreateUser(username, password string) error {
 	return nil
 }
 
+const selectUsers = `
+	SELECT id, username, password, role, disabled, COALESCE(totp_secret, ''), totp_enabled, totp_last_step,
+		COALESCE(login_attempts.failures, 0), login_attempts.locked_until
+	FROM users
+	LEFT JOIN login_attempts ON login_attempts.scope = 'username' AND login_attempts.subject = users.username
+`
+
+func scanUser(row interface{ Scan(dest ...any) error }, user *models.User) error {
+	var lockedUntil sql.NullTime
+	err := row.Scan(&user.ID, &user.Username, &user.Password, &user.Role, &user.Disabled, &user.TOTPSecret, &user.TOTPEnabled, &user.TOTPLastStep,
+		&user.FailedLogins, &lockedUntil)
+	if err != nil {
+		return err
+	}
+
+	if lockedUntil.Valid {
+		user.LockedUntil = &lockedUntil.Time
+	}
+
+	return nil
+}
+
 func (r *repo) FindUserByUsername(username string) (models.User, error) {
 	user := models.User{}
-	query := "SELECT * FROM users WHERE username = $1"
 
-	row := r.DB.QueryRow(query, username)
-	if err := row.Scan(&user.ID, &user.Username, &user.Password); err != nil {
+	row := r.DB.QueryRow(selectUsers+" WHERE username = $1", username)
+	if err := scanUser(row, &user); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return user, nil
+			return user, ErrUserNotFound
 		}
+		return user, fmt.Errorf("failed to find user: %w", err)
 	}
 
-	if user.ID == "" {
-		return user, ErrUserNotFound
+	return user, nil
+}
+
+func (r *repo) FindUserByID(id string) (models.User, error) {
+	user := models.User{}
+
+	row := r.DB.QueryRow(selectUsers+" WHERE id = $1", id)
+	if err := scanUser(row, &user); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user, ErrUserNotFound
+		}
+		return user, fmt.Errorf("failed to find user: %w", err)
 	}
 
 	return user, nil
 }
 
 func (r *repo) CheckTokenExists(token string) (bool, error) {
-	query := "SELECT EXISTS(SELECT 1 FROM tokens WHERE token = $1)"
+	query := "SELECT EXISTS(SELECT 1 FROM tokens WHERE token = $1 AND rotated_at IS NULL)"
 
 	row := r.DB.QueryRow(query, token)
 	var exists bool
@@ -75,35 +169,82 @@ func (r *repo) CheckTokenExists(token string) (bool, error) {
 	return exists, nil
 }
 
-func (r *repo) ReplaceUserAccessToken(accessToken models.Token) error {
-	query := "UPDATE tokens SET token = $1, expires_at = $2 WHERE user_id = $3 AND type = 'access'"
-
-	slog.Info(fmt.Sprint(accessToken.ExpiresAt))
-
-	_, err := r.Exec(query, accessToken.Token, accessToken.ExpiresAt, accessToken.UserID)
+// RotateRefreshToken swaps oldToken for a new refresh token of the same session and
+// replaces the session's access token. The old token is kept as rotated so that a
+// second use of it can be told apart from an unknown token.
+func (r *repo) RotateRefreshToken(oldToken string, accessToken, refreshToken models.Token) error {
+	tx, err := r.DB.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback() //nolint:errcheck
 
-	return nil
+	var oldID uint64
+	var rotatedAt sql.NullTime
+	row := tx.QueryRow("SELECT id, rotated_at FROM tokens WHERE token = $1 AND type = 'refresh' FOR UPDATE", oldToken)
+	if err = row.Scan(&oldID, &rotatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTokenNotFound
+		}
+		return fmt.Errorf("failed to find token: %w", err)
+	}
+
+	if rotatedAt.Valid {
+		return ErrTokenReused
+	}
+
+	query := `
+		INSERT INTO tokens (user_id, session_id, type, token, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	var newID uint64
+	row = tx.QueryRow(query, refreshToken.UserID, refreshToken.SessionID, refreshToken.Type, refreshToken.Token, refreshToken.ExpiresAt)
+	if err = row.Scan(&newID); err != nil {
+		return fmt.Errorf("failed to write token: %w", err)
+	}
+
+	if _, err = tx.Exec("UPDATE tokens SET rotated_at = NOW(), replaced_by = $1 WHERE id = $2", newID, oldID); err != nil {
+		return fmt.Errorf("failed to mark token as rotated: %w", err)
+	}
+
+	query = "UPDATE tokens SET token = $1, expires_at = $2 WHERE session_id = $3 AND type = 'access'"
+	if _, err = tx.Exec(query, accessToken.Token, accessToken.ExpiresAt, accessToken.SessionID); err != nil {
+		return fmt.Errorf("failed to replace access token: %w", err)
+	}
+
+	query = "DELETE FROM tokens WHERE session_id = $1 AND rotated_at IS NOT NULL AND expires_at < NOW()"
+	if _, err = tx.Exec(query, refreshToken.SessionID); err != nil {
+		return fmt.Errorf("failed to delete expired tokens: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return r.TouchSession(refreshToken.SessionID)
 }
 
-func (r *repo) DeleteUserTokensAndWriteNew(userID string, accessToken, refreshToken models.Token) error {
+func (r *repo) CreateSession(session models.Session, accessToken, refreshToken models.Token) error {
 	tx, err := r.DB.Begin()
 	if err != nil {
 		return err
 	}
 
-	deleteOldTokensQuery := "DELETE FROM tokens WHERE user_id = $1"
+	createSessionQuery := `
+		INSERT INTO sessions (id, user_id, user_agent, ip)
+		VALUES ($1, $2, $3, $4)
+	`
 
-	_, err = tx.Exec(deleteOldTokensQuery, userID)
+	_, err = tx.Exec(createSessionQuery, session.ID, session.UserID, session.UserAgent, session.IP)
 	if err != nil {
 		if err = tx.Rollback(); err != nil {
 			err = fmt.Errorf("failed to rollback transaction: %w", err)
 			slog.Error(err.Error())
 			return err
 		}
-		err = fmt.Errorf("failed to delete old tokens: %w", err)
+		err = fmt.Errorf("failed to create session: %w", err)
 		slog.Error(err.Error())
 		return err
 	}
@@ -143,24 +284,868 @@ func (r *repo) DeleteUserTokensAndWriteNew(userID string, accessToken, refreshTo
 	return nil
 }
 
-func (r *repo) DeleteAllUserTokens(userID string) error {
-	query := "DELETE FROM tokens WHERE user_id = $1 AND type = 'access'"
+// TouchSession bumps the session's last used time, at most once a minute.
+func (r *repo) TouchSession(sessionID string) error {
+	query := `
+		UPDATE sessions SET last_used_at = NOW()
+		WHERE id = $1 AND last_used_at < NOW() - INTERVAL '1 minute'
+	`
+
+	if _, err := r.Exec(query, sessionID); err != nil {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repo) ListSessions(userID string) ([]models.Session, error) {
+	query := `
+		SELECT id, user_id, user_agent, ip, created_at, last_used_at FROM sessions
+		WHERE user_id = $1
+		ORDER BY last_used_at DESC
+	`
+
+	rows, err := r.DB.Query(query, userID)
+	if err != nil {
+		err = fmt.Errorf("failed to list sessions: %w", err)
+		slog.Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]models.Session, 0)
+	for rows.Next() {
+		var session models.Session
+		if err = rows.Scan(&session.ID, &session.UserID, &session.UserAgent, &session.IP, &session.CreatedAt, &session.LastUsedAt); err != nil {
+			err = fmt.Errorf("failed to scan session: %w", err)
+			slog.Error(err.Error())
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err = rows.Err(); err != nil {
+		err = fmt.Errorf("failed to iterate sessions: %w", err)
+		slog.Error(err.Error())
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// DeleteSession ends a session of the user, removing its tokens with it.
+func (r *repo) DeleteSession(userID, sessionID string) error {
+	res, err := r.Exec(revokeSessionsQuery("id = $1 AND user_id = $2"), sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (r *repo) DeleteOtherSessions(userID, keepSessionID string) error {
+	if _, err := r.Exec(revokeSessionsQuery("user_id = $1 AND id <> $2"), userID, keepSessionID); err != nil {
+		return fmt.Errorf("failed to delete sessions: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repo) ListUsers(search string, limit, offset int) ([]models.User, int, error) {
+	pattern := "%" + search + "%"
+
+	var total int
+	row := r.DB.QueryRow("SELECT COUNT(*) FROM users WHERE username ILIKE $1", pattern)
+	if err := row.Scan(&total); err != nil {
+		err = fmt.Errorf("failed to count users: %w", err)
+		slog.Error(err.Error())
+		return nil, 0, err
+	}
+
+	query := selectUsers + `
+		WHERE username ILIKE $1
+		ORDER BY username
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.DB.Query(query, pattern, limit, offset)
+	if err != nil {
+		err = fmt.Errorf("failed to list users: %w", err)
+		slog.Error(err.Error())
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0, limit)
+	for rows.Next() {
+		var user models.User
+		if err = scanUser(rows, &user); err != nil {
+			err = fmt.Errorf("failed to scan user: %w", err)
+			slog.Error(err.Error())
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		err = fmt.Errorf("failed to iterate users: %w", err)
+		slog.Error(err.Error())
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+func (r *repo) SetUserDisabled(id string, disabled bool) error {
+	res, err := r.Exec("UPDATE users SET disabled = $1 WHERE id = $2", disabled, id)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return checkUserAffected(res)
+}
+
+func (r *repo) SetUserRole(id, role string) error {
+	res, err := r.Exec("UPDATE users SET role = $1 WHERE id = $2", role, id)
+	if err != nil {
+		return fmt.Errorf("failed to update user role: %w", err)
+	}
+
+	return checkUserAffected(res)
+}
+
+// DemoteAdmins makes every admin not listed in keep a regular user and
+// returns how many were demoted.
+func (r *repo) DemoteAdmins(keep []string) (int64, error) {
+	// a nil slice would be sent as NULL, which matches no admin at all
+	if keep == nil {
+		keep = []string{}
+	}
 
-	_, err := r.Exec(query, userID)
+	res, err := r.Exec("UPDATE users SET role = $1 WHERE role = $2 AND id <> ALL($3)", pb.RoleUser, pb.RoleAdmin, pq.Array(keep))
 	if err != nil {
+		return 0, fmt.Errorf("failed to demote admins: %w", err)
+	}
+
+	return res.RowsAffected()
+}
+
+func (r *repo) DeleteUser(id string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err = tx.Exec(revokeSessionsQuery("user_id = $1"), id); err != nil {
+		return fmt.Errorf("failed to revoke user sessions: %w", err)
+	}
+
+	res, err := tx.Exec("DELETE FROM users WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	if err = checkUserAffected(res); err != nil {
 		return err
 	}
 
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
-func (r *repo) transactionalWriteToken(tx *sql.Tx, token models.Token) error {
+func (r *repo) RevokeUserSessions(userID string) error {
+	if _, err := r.Exec(revokeSessionsQuery("user_id = $1"), userID); err != nil {
+		return fmt.Errorf("failed to revoke user sessions: %w", err)
+	}
+
+	return nil
+}
+
+// revokeSessionsQuery deletes the sessions matched by where and records each of them
+// as revoked until the last access token issued for it expires.
+func revokeSessionsQuery(where string) string {
+	return `
+		WITH revoked AS (
+			DELETE FROM sessions WHERE ` + where + `
+			RETURNING id, user_id
+		)
+		INSERT INTO revocations (user_id, session_id, expires_at)
+		SELECT revoked.user_id, revoked.id, COALESCE(
+			(SELECT MAX(expires_at) FROM tokens WHERE session_id = revoked.id AND type = 'access'),
+			NOW()
+		)
+		FROM revoked
+	`
+}
+
+// ListRevocations returns live revocations newer than afterID, plus those made since
+// the given time in case a slow transaction committed one with a lower id.
+func (r *repo) ListRevocations(afterID int64, since time.Time) ([]models.Revocation, error) {
+	query := `
+		SELECT id, user_id, session_id, revoked_at, expires_at FROM revocations
+		WHERE (id > $1 OR revoked_at > $2) AND expires_at > NOW()
+		ORDER BY id
+	`
+
+	rows, err := r.DB.Query(query, afterID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revocations: %w", err)
+	}
+	defer rows.Close()
+
+	revocations := make([]models.Revocation, 0)
+	for rows.Next() {
+		var revocation models.Revocation
+		if err = rows.Scan(&revocation.ID, &revocation.UserID, &revocation.SessionID, &revocation.RevokedAt, &revocation.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scan revocation: %w", err)
+		}
+		revocations = append(revocations, revocation)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate revocations: %w", err)
+	}
+
+	return revocations, nil
+}
+
+func (r *repo) DeleteExpiredRevocations() error {
+	if _, err := r.Exec("DELETE FROM revocations WHERE expires_at <= NOW()"); err != nil {
+		return fmt.Errorf("failed to delete expired revocations: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repo) ListSigningKeys() ([]models.SigningKey, error) {
 	query := `
-		INSERT INTO tokens (user_id, type, token, expires_at)
+		SELECT id, algorithm, private_key, created_at, expires_at FROM signing_keys
+		WHERE expires_at IS NULL OR expires_at > NOW()
+		ORDER BY created_at
+	`
+
+	rows, err := r.DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]models.SigningKey, 0)
+	for rows.Next() {
+		var key models.SigningKey
+		var expiresAt sql.NullTime
+		if err = rows.Scan(&key.ID, &key.Algorithm, &key.PrivateKey, &key.CreatedAt, &expiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scan signing key: %w", err)
+		}
+		if expiresAt.Valid {
+			key.ExpiresAt = &expiresAt.Time
+		}
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate signing keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (r *repo) CreateSigningKey(key models.SigningKey) error {
+	query := `
+		INSERT INTO signing_keys (id, algorithm, private_key, created_at)
 		VALUES ($1, $2, $3, $4)
 	`
 
-	_, err := tx.Exec(query, token.UserID, token.Type, token.Token, token.ExpiresAt)
+	if _, err := r.Exec(query, key.ID, key.Algorithm, key.PrivateKey, key.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create signing key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repo) RetireSigningKeys(exceptID string, verifyUntil time.Time) error {
+	query := "UPDATE signing_keys SET expires_at = $1 WHERE id <> $2 AND expires_at IS NULL"
+
+	if _, err := r.Exec(query, verifyUntil, exceptID); err != nil {
+		return fmt.Errorf("failed to retire signing keys: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repo) DeleteExpiredSigningKeys() error {
+	if _, err := r.Exec("DELETE FROM signing_keys WHERE expires_at <= NOW()"); err != nil {
+		return fmt.Errorf("failed to delete expired signing keys: %w", err)
+	}
+
+	return nil
+}
+
+// SetTOTPSecret starts enrollment, replacing any earlier secret that wasn't confirmed.
+func (r *repo) SetTOTPSecret(userID, secret string) error {
+	res, err := r.Exec("UPDATE users SET totp_secret = $1, totp_last_step = 0 WHERE id = $2 AND NOT totp_enabled", secret, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set totp secret: %w", err)
+	}
+
+	return checkUserAffected(res)
+}
+
+// EnableTOTP confirms enrollment and replaces the user's recovery codes.
+func (r *repo) EnableTOTP(userID string, recoveryCodeHashes []string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	res, err := tx.Exec("UPDATE users SET totp_enabled = TRUE WHERE id = $1 AND totp_secret IS NOT NULL", userID)
+	if err != nil {
+		return fmt.Errorf("failed to enable totp: %w", err)
+	}
+
+	if err = checkUserAffected(res); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec("DELETE FROM recovery_codes WHERE user_id = $1", userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	for _, hash := range recoveryCodeHashes {
+		if _, err = tx.Exec("INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)", userID, hash); err != nil {
+			return fmt.Errorf("failed to write recovery code: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repo) DisableTOTP(userID string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	res, err := tx.Exec("UPDATE users SET totp_secret = NULL, totp_enabled = FALSE, totp_last_step = 0 WHERE id = $1", userID)
+	if err != nil {
+		return fmt.Errorf("failed to disable totp: %w", err)
+	}
+
+	if err = checkUserAffected(res); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec("DELETE FROM recovery_codes WHERE user_id = $1", userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UseTOTPStep records the time step of an accepted code. It reports false when that
+// step or a later one was used already, which means the code is being replayed.
+func (r *repo) UseTOTPStep(userID string, step int64) (bool, error) {
+	res, err := r.Exec("UPDATE users SET totp_last_step = $1 WHERE id = $2 AND totp_last_step < $1", step, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update totp step: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+// UseChallenge marks a login challenge as spent. It reports false when it was
+// spent already.
+func (r *repo) UseChallenge(id string, expiresAt time.Time) (bool, error) {
+	res, err := r.Exec("INSERT INTO used_challenges (id, expires_at) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING", id, expiresAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to use challenge: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+func (r *repo) DeleteExpiredChallenges() error {
+	if _, err := r.Exec("DELETE FROM used_challenges WHERE expires_at <= NOW()"); err != nil {
+		return fmt.Errorf("failed to delete expired challenges: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repo) ListRecoveryCodes(userID string) ([]models.RecoveryCode, error) {
+	rows, err := r.DB.Query("SELECT id, user_id, code_hash FROM recovery_codes WHERE user_id = $1 AND used_at IS NULL", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recovery codes: %w", err)
+	}
+	defer rows.Close()
+
+	codes := make([]models.RecoveryCode, 0)
+	for rows.Next() {
+		var code models.RecoveryCode
+		if err = rows.Scan(&code.ID, &code.UserID, &code.CodeHash); err != nil {
+			return nil, fmt.Errorf("failed to scan recovery code: %w", err)
+		}
+		codes = append(codes, code)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate recovery codes: %w", err)
+	}
+
+	return codes, nil
+}
+
+// UseRecoveryCode marks the code as used, reporting false if it already was.
+func (r *repo) UseRecoveryCode(id int64) (bool, error) {
+	res, err := r.Exec("UPDATE recovery_codes SET used_at = NOW() WHERE id = $1 AND used_at IS NULL", id)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+const settingTOTPRequired = "totp_required"
+
+func (r *repo) GetSecuritySettings() (models.SecuritySettings, error) {
+	var settings models.SecuritySettings
+
+	rows, err := r.DB.Query("SELECT key, value FROM settings")
+	if err != nil {
+		return settings, fmt.Errorf("failed to get settings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key, value string
+		if err = rows.Scan(&key, &value); err != nil {
+			return settings, fmt.Errorf("failed to scan setting: %w", err)
+		}
+
+		if key == settingTOTPRequired {
+			settings.TOTPRequired = value == "true"
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return settings, fmt.Errorf("failed to iterate settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (r *repo) UpdateSecuritySettings(settings models.SecuritySettings) error {
+	query := `
+		INSERT INTO settings (key, value) VALUES ($1, $2)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value
+	`
+
+	if _, err := r.Exec(query, settingTOTPRequired, strconv.FormatBool(settings.TOTPRequired)); err != nil {
+		return fmt.Errorf("failed to update settings: %w", err)
+	}
+
+	return nil
+}
+
+const selectPersonalTokens = `
+	SELECT id, user_id, name, prefix, token_hash, scopes, created_at, expires_at, last_used_at
+	FROM personal_tokens
+`
+
+func (r *repo) CreatePersonalToken(token models.PersonalToken) error {
+	query := `
+		INSERT INTO personal_tokens (id, user_id, name, prefix, token_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.Exec(query, token.ID, token.UserID, token.Name, token.Prefix, token.TokenHash, pq.Array(token.Scopes), token.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create personal token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repo) FindPersonalTokenByHash(hash string) (models.PersonalToken, error) {
+	row := r.DB.QueryRow(selectPersonalTokens+" WHERE token_hash = $1", hash)
+
+	token, err := scanPersonalToken(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.PersonalToken{}, ErrPersonalTokenNotFound
+		}
+		return models.PersonalToken{}, fmt.Errorf("failed to find personal token: %w", err)
+	}
+
+	return token, nil
+}
+
+func (r *repo) ListPersonalTokens(userID string) ([]models.PersonalToken, error) {
+	rows, err := r.DB.Query(selectPersonalTokens+" WHERE user_id = $1 ORDER BY created_at DESC", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list personal tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := make([]models.PersonalToken, 0)
+	for rows.Next() {
+		token, err := scanPersonalToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan personal token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate personal tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+func (r *repo) DeletePersonalToken(userID, id string) error {
+	res, err := r.Exec("DELETE FROM personal_tokens WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete personal token: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return ErrPersonalTokenNotFound
+	}
+
+	return nil
+}
+
+// TouchPersonalToken bumps the token's last used time, at most once a minute.
+func (r *repo) TouchPersonalToken(id string) error {
+	query := `
+		UPDATE personal_tokens SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`
+
+	if _, err := r.Exec(query, id); err != nil {
+		return fmt.Errorf("failed to touch personal token: %w", err)
+	}
+
+	return nil
+}
+
+func scanPersonalToken(row interface{ Scan(dest ...any) error }) (models.PersonalToken, error) {
+	var token models.PersonalToken
+	var scopes pq.StringArray
+	var lastUsedAt sql.NullTime
+
+	err := row.Scan(&token.ID, &token.UserID, &token.Name, &token.Prefix, &token.TokenHash, &scopes, &token.CreatedAt, &token.ExpiresAt, &lastUsedAt)
+	if err != nil {
+		return models.PersonalToken{}, err
+	}
+
+	token.Scopes = scopes
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return token, nil
+}
+
+func (r *repo) GetLoginAttempts(scope, subject string) (models.LoginAttempts, error) {
+	query := `
+		SELECT scope, subject, failures, last_failed_at, locked_until FROM login_attempts
+		WHERE scope = $1 AND subject = $2
+	`
+
+	attempts, err := scanLoginAttempts(r.DB.QueryRow(query, scope, subject))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.LoginAttempts{Scope: scope, Subject: subject}, nil
+	}
+	if err != nil {
+		return models.LoginAttempts{}, fmt.Errorf("failed to get login attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// RecordLoginFailure counts a failed login and returns the number of failures so far.
+// The count starts over when the previous failure is older than window.
+func (r *repo) RecordLoginFailure(scope, subject string, window time.Duration) (int, error) {
+	query := `
+		INSERT INTO login_attempts (scope, subject, failures, last_failed_at)
+		VALUES ($1, $2, 1, NOW())
+		ON CONFLICT (scope, subject) DO UPDATE SET
+			failures = CASE
+				WHEN login_attempts.last_failed_at < NOW() - make_interval(secs => $3) THEN 1
+				ELSE login_attempts.failures + 1
+			END,
+			last_failed_at = NOW()
+		RETURNING failures
+	`
+
+	var failures int
+	if err := r.DB.QueryRow(query, scope, subject, window.Seconds()).Scan(&failures); err != nil {
+		return 0, fmt.Errorf("failed to record login failure: %w", err)
+	}
+
+	return failures, nil
+}
+
+func (r *repo) LockLogin(scope, subject string, until time.Time) error {
+	query := "UPDATE login_attempts SET locked_until = $1 WHERE scope = $2 AND subject = $3"
+	if _, err := r.Exec(query, until, scope, subject); err != nil {
+		return fmt.Errorf("failed to lock login: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repo) ClearLoginAttempts(scope, subject string) error {
+	if _, err := r.Exec("DELETE FROM login_attempts WHERE scope = $1 AND subject = $2", scope, subject); err != nil {
+		return fmt.Errorf("failed to clear login attempts: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repo) ListLoginLockouts() ([]models.LoginAttempts, error) {
+	query := `
+		SELECT scope, subject, failures, last_failed_at, locked_until FROM login_attempts
+		WHERE locked_until > NOW()
+		ORDER BY locked_until DESC
+	`
+
+	rows, err := r.DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list login lockouts: %w", err)
+	}
+	defer rows.Close()
+
+	lockouts := make([]models.LoginAttempts, 0)
+	for rows.Next() {
+		attempts, err := scanLoginAttempts(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan login lockout: %w", err)
+		}
+		lockouts = append(lockouts, attempts)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate login lockouts: %w", err)
+	}
+
+	return lockouts, nil
+}
+
+func (r *repo) DeleteStaleLoginAttempts() error {
+	query := `
+		DELETE FROM login_attempts
+		WHERE last_failed_at < NOW() - INTERVAL '1 day' AND (locked_until IS NULL OR locked_until < NOW())
+	`
+
+	if _, err := r.Exec(query); err != nil {
+		return fmt.Errorf("failed to delete stale login attempts: %w", err)
+	}
+
+	return nil
+}
+
+func scanLoginAttempts(row interface{ Scan(dest ...any) error }) (models.LoginAttempts, error) {
+	var attempts models.LoginAttempts
+	var lockedUntil sql.NullTime
+
+	if err := row.Scan(&attempts.Scope, &attempts.Subject, &attempts.Failures, &attempts.LastFailedAt, &lockedUntil); err != nil {
+		return models.LoginAttempts{}, err
+	}
+
+	if lockedUntil.Valid {
+		attempts.LockedUntil = &lockedUntil.Time
+	}
+
+	return attempts, nil
+}
+
+// ChangePassword sets a new password hash, voids pending reset tokens, deletes
+// personal access tokens and ends every session of the user except keepSessionID.
+func (r *repo) ChangePassword(userID, keepSessionID, passwordHash string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	res, err := tx.Exec("UPDATE users SET password = $1 WHERE id = $2", passwordHash, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if err = checkUserAffected(res); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec("UPDATE password_resets SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL", userID); err != nil {
+		return fmt.Errorf("failed to invalidate password resets: %w", err)
+	}
+
+	if _, err = tx.Exec(revokeSessionsQuery("user_id = $1 AND id <> $2"), userID, keepSessionID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	if _, err = tx.Exec("DELETE FROM personal_tokens WHERE user_id = $1", userID); err != nil {
+		return fmt.Errorf("failed to revoke personal access tokens: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UpgradePasswordHash swaps a password hash for a stronger one of the same password.
+// Nothing happens if the password was changed in the meantime.
+func (r *repo) UpgradePasswordHash(userID, oldHash, newHash string) error {
+	query := "UPDATE users SET password = $1 WHERE id = $2 AND password = $3"
+	if _, err := r.Exec(query, newHash, userID, oldHash); err != nil {
+		return fmt.Errorf("failed to update password hash: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repo) CreatePasswordReset(reset models.PasswordReset) error {
+	query := `
+		INSERT INTO password_resets (id, user_id, token_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	if _, err := r.Exec(query, reset.ID, reset.UserID, reset.TokenHash, reset.CreatedAt, reset.ExpiresAt); err != nil {
+		return fmt.Errorf("failed to create password reset: %w", err)
+	}
+
+	return nil
+}
+
+// ResetPassword consumes a live reset token, sets the new password hash, ends
+// all sessions of the token's user and deletes their personal access tokens.
+// Other pending tokens of the user are voided too.
+func (r *repo) ResetPassword(tokenHash, passwordHash string) (string, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	query := `
+		UPDATE password_resets SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id
+	`
+
+	var userID string
+	if err = tx.QueryRow(query, tokenHash).Scan(&userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrResetTokenNotFound
+		}
+		return "", fmt.Errorf("failed to use password reset: %w", err)
+	}
+
+	res, err := tx.Exec("UPDATE users SET password = $1 WHERE id = $2", passwordHash, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if err = checkUserAffected(res); err != nil {
+		return "", err
+	}
+
+	if _, err = tx.Exec("UPDATE password_resets SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL", userID); err != nil {
+		return "", fmt.Errorf("failed to invalidate password resets: %w", err)
+	}
+
+	if _, err = tx.Exec(revokeSessionsQuery("user_id = $1"), userID); err != nil {
+		return "", fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	if _, err = tx.Exec("DELETE FROM personal_tokens WHERE user_id = $1", userID); err != nil {
+		return "", fmt.Errorf("failed to revoke personal access tokens: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return userID, nil
+}
+
+func (r *repo) DeleteExpiredPasswordResets() error {
+	if _, err := r.Exec("DELETE FROM password_resets WHERE expires_at <= NOW() OR used_at IS NOT NULL"); err != nil {
+		return fmt.Errorf("failed to delete expired password resets: %w", err)
+	}
+
+	return nil
+}
+
+func checkUserAffected(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *repo) transactionalWriteToken(tx *sql.Tx, token models.Token) error {
+	query := `
+		INSERT INTO tokens (user_id, session_id, type, token, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := tx.Exec(query, token.UserID, token.SessionID, token.Type, token.Token, token.ExpiresAt)
 	if err != nil {
 		return err
 	}
@@ -38,6 +38,136 @@ func (s Server) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutRe
 	return s.Controller.Logout(ctx, req)
 }
 
+func (s Server) FindUser(ctx context.Context, req *pb.FindUserRequest) (*pb.FindUserResponse, error) {
+	slog.Info("Finding user")
+	return s.Controller.FindUser(ctx, req)
+}
+
+func (s Server) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	slog.Info("Changing password")
+	return s.Controller.ChangePassword(ctx, req)
+}
+
+func (s Server) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	slog.Info("Requesting password reset")
+	return s.Controller.RequestPasswordReset(ctx, req)
+}
+
+func (s Server) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	slog.Info("Resetting password")
+	return s.Controller.ResetPassword(ctx, req)
+}
+
+func (s Server) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	slog.Info("Listing sessions")
+	return s.Controller.ListSessions(ctx, req)
+}
+
+func (s Server) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	slog.Info("Revoking session")
+	return s.Controller.RevokeSession(ctx, req)
+}
+
+func (s Server) RevokeOtherSessions(ctx context.Context, req *pb.RevokeOtherSessionsRequest) (*pb.RevokeOtherSessionsResponse, error) {
+	slog.Info("Revoking other sessions")
+	return s.Controller.RevokeOtherSessions(ctx, req)
+}
+
+func (s Server) VerifyLoginTOTP(ctx context.Context, req *pb.VerifyLoginTOTPRequest) (*pb.VerifyLoginTOTPResponse, error) {
+	slog.Info("Verifying login code")
+	return s.Controller.VerifyLoginTOTP(ctx, req)
+}
+
+func (s Server) BeginTOTPEnrollment(ctx context.Context, req *pb.BeginTOTPEnrollmentRequest) (*pb.BeginTOTPEnrollmentResponse, error) {
+	slog.Info("Beginning TOTP enrollment")
+	return s.Controller.BeginTOTPEnrollment(ctx, req)
+}
+
+func (s Server) ConfirmTOTPEnrollment(ctx context.Context, req *pb.ConfirmTOTPEnrollmentRequest) (*pb.ConfirmTOTPEnrollmentResponse, error) {
+	slog.Info("Confirming TOTP enrollment")
+	return s.Controller.ConfirmTOTPEnrollment(ctx, req)
+}
+
+func (s Server) DisableTOTP(ctx context.Context, req *pb.DisableTOTPRequest) (*pb.DisableTOTPResponse, error) {
+	slog.Info("Disabling TOTP")
+	return s.Controller.DisableTOTP(ctx, req)
+}
+
+func (s Server) CreatePersonalToken(ctx context.Context, req *pb.CreatePersonalTokenRequest) (*pb.CreatePersonalTokenResponse, error) {
+	slog.Info("Creating personal token")
+	return s.Controller.CreatePersonalToken(ctx, req)
+}
+
+func (s Server) ListPersonalTokens(ctx context.Context, req *pb.ListPersonalTokensRequest) (*pb.ListPersonalTokensResponse, error) {
+	slog.Info("Listing personal tokens")
+	return s.Controller.ListPersonalTokens(ctx, req)
+}
+
+func (s Server) RevokePersonalToken(ctx context.Context, req *pb.RevokePersonalTokenRequest) (*pb.RevokePersonalTokenResponse, error) {
+	slog.Info("Revoking personal token")
+	return s.Controller.RevokePersonalToken(ctx, req)
+}
+
+func (s Server) GetJWKS(ctx context.Context, req *pb.GetJWKSRequest) (*pb.GetJWKSResponse, error) {
+	slog.Info("Getting JWKS")
+	return s.Controller.GetJWKS(ctx, req)
+}
+
+func (s Server) WatchRevocations(req *pb.WatchRevocationsRequest, stream pb.AuthService_WatchRevocationsServer) error {
+	slog.Info("Watching revocations")
+	return s.Controller.WatchRevocations(req, stream)
+}
+
+func (s Server) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	slog.Info("Listing users")
+	return s.Controller.ListUsers(ctx, req)
+}
+
+func (s Server) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+	slog.Info("Getting user")
+	return s.Controller.GetUser(ctx, req)
+}
+
+func (s Server) DisableUser(ctx context.Context, req *pb.DisableUserRequest) (*pb.DisableUserResponse, error) {
+	slog.Info("Disabling user")
+	return s.Controller.DisableUser(ctx, req)
+}
+
+func (s Server) EnableUser(ctx context.Context, req *pb.EnableUserRequest) (*pb.EnableUserResponse, error) {
+	slog.Info("Enabling user")
+	return s.Controller.EnableUser(ctx, req)
+}
+
+func (s Server) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+	slog.Info("Deleting user")
+	return s.Controller.DeleteUser(ctx, req)
+}
+
+func (s Server) ForceLogout(ctx context.Context, req *pb.ForceLogoutRequest) (*pb.ForceLogoutResponse, error) {
+	slog.Info("Forcing user logout")
+	return s.Controller.ForceLogout(ctx, req)
+}
+
+func (s Server) GetSecuritySettings(ctx context.Context, req *pb.GetSecuritySettingsRequest) (*pb.GetSecuritySettingsResponse, error) {
+	slog.Info("Getting security settings")
+	return s.Controller.GetSecuritySettings(ctx, req)
+}
+
+func (s Server) UpdateSecuritySettings(ctx context.Context, req *pb.UpdateSecuritySettingsRequest) (*pb.UpdateSecuritySettingsResponse, error) {
+	slog.Info("Updating security settings")
+	return s.Controller.UpdateSecuritySettings(ctx, req)
+}
+
+func (s Server) ListLoginLockouts(ctx context.Context, req *pb.ListLoginLockoutsRequest) (*pb.ListLoginLockoutsResponse, error) {
+	slog.Info("Listing login lockouts")
+	return s.Controller.ListLoginLockouts(ctx, req)
+}
+
+func (s Server) ClearLoginLockout(ctx context.Context, req *pb.ClearLoginLockoutRequest) (*pb.ClearLoginLockoutResponse, error) {
+	slog.Info("Clearing login lockout")
+	return s.Controller.ClearLoginLockout(ctx, req)
+}
+
 func New(controller controller.Controller) *Server {
 	return &Server{
 		UnimplementedAuthServiceServer: pb.UnimplementedAuthServiceServer{},
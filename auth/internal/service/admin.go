@@ -0,0 +1,128 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/avran02/fileshare/auth/internal/models"
+	pb "github.com/avran02/fileshare/proto/authpb"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+func (s *service) CheckAdmin(token string) (string, error) {
+	payload, err := s.validateAccessToken(token)
+	if err != nil {
+		return "", err
+	}
+
+	if payload.Role != pb.RoleAdmin {
+		slog.Error("user " + payload.UserID + " is not an admin")
+		return "", ErrNotAdmin
+	}
+
+	return payload.UserID, nil
+}
+
+func (s *service) ListUsers(search string, page, pageSize int) ([]models.User, int, error) {
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	users, total, err := s.repo.ListUsers(search, pageSize, (page-1)*pageSize)
+	if err != nil {
+		err = fmt.Errorf("failed to list users: %w", err)
+		slog.Error(err.Error())
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// SetUserDisabled disables or enables a user. Disabling also revokes all
+// their sessions, so they are logged out right away.
+func (s *service) SetUserDisabled(adminID, id string, disabled bool) error {
+	if disabled && adminID == id {
+		return ErrSelfAdminAction
+	}
+
+	if err := s.repo.SetUserDisabled(id, disabled); err != nil {
+		err = fmt.Errorf("failed to set user disabled: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if !disabled {
+		return nil
+	}
+
+	if err := s.repo.RevokeUserSessions(id); err != nil {
+		err = fmt.Errorf("failed to revoke sessions: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) DeleteUser(adminID, id string) error {
+	if adminID == id {
+		return ErrSelfAdminAction
+	}
+
+	if err := s.repo.DeleteUser(id); err != nil {
+		err = fmt.Errorf("failed to delete user: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) ForceLogout(id string) error {
+	if _, err := s.repo.FindUserByID(id); err != nil {
+		err = fmt.Errorf("failed to find user: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if err := s.repo.RevokeUserSessions(id); err != nil {
+		err = fmt.Errorf("failed to revoke sessions: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) GetSecuritySettings() (models.SecuritySettings, error) {
+	settings, err := s.repo.GetSecuritySettings()
+	if err != nil {
+		err = fmt.Errorf("failed to get security settings: %w", err)
+		slog.Error(err.Error())
+		return models.SecuritySettings{}, err
+	}
+
+	return settings, nil
+}
+
+func (s *service) UpdateSecuritySettings(settings models.SecuritySettings) error {
+	if err := s.repo.UpdateSecuritySettings(settings); err != nil {
+		err = fmt.Errorf("failed to update security settings: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/avran02/fileshare/auth/internal/models"
+)
+
+const (
+	// failures older than this are forgotten
+	loginFailureWindow = time.Hour
+
+	// an IP is shared by many users, so it gets more attempts than a single username
+	freeUsernameFailures = 5
+	freeIPFailures       = 20
+
+	// every password reset request counts, as each one sends a message
+	freeResetUsernameRequests = 3
+	freeResetIPRequests       = 10
+
+	loginBackoffBase = 30 * time.Second
+	maxLoginLockout  = 15 * time.Minute
+)
+
+var (
+	ErrInvalidCredentials  = errors.New("invalid username or password")
+	ErrTooManyAttempts     = errors.New("too many attempts, try again later")
+	ErrInvalidLockoutScope = errors.New("lockout scope must be username, ip, reset_username or reset_ip")
+)
+
+// checkLoginAllowed fails while the username or the client IP is locked out.
+func (s *service) checkLoginAllowed(username, ip string) error {
+	return s.checkAttemptsAllowed(loginAttemptKeys(username, ip))
+}
+
+// recordLoginFailure counts a failed attempt for both the username and the IP and
+// locks them out for exponentially longer once their free attempts are used up.
+func (s *service) recordLoginFailure(username, ip string) {
+	s.recordAttempt(loginAttemptKeys(username, ip))
+}
+
+func (s *service) checkAttemptsAllowed(keys []loginAttemptKey) error {
+	for _, key := range keys {
+		attempts, err := s.repo.GetLoginAttempts(key.scope, key.subject)
+		if err != nil {
+			err = fmt.Errorf("failed to get login attempts: %w", err)
+			slog.Error(err.Error())
+			return err
+		}
+
+		if attempts.LockedUntil != nil && time.Now().Before(*attempts.LockedUntil) {
+			slog.Warn("security: attempt rejected during lockout", "scope", key.scope, "subject", key.subject, "locked_until", *attempts.LockedUntil)
+			return fmt.Errorf("%w (locked until %s)", ErrTooManyAttempts, attempts.LockedUntil.Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
+
+func (s *service) recordAttempt(keys []loginAttemptKey) {
+	for _, key := range keys {
+		failures, err := s.repo.RecordLoginFailure(key.scope, key.subject, loginFailureWindow)
+		if err != nil {
+			slog.Error(err.Error())
+			continue
+		}
+
+		delay := loginBackoff(failures, key.free)
+		if delay == 0 {
+			continue
+		}
+
+		slog.Warn("security: locking out", "scope", key.scope, "subject", key.subject, "failures", failures, "for", delay.String())
+		if err = s.repo.LockLogin(key.scope, key.subject, time.Now().Add(delay)); err != nil {
+			slog.Error(err.Error())
+		}
+	}
+}
+
+func (s *service) clearLoginFailures(username string) {
+	if err := s.repo.ClearLoginAttempts(models.AttemptScopeUsername, username); err != nil {
+		slog.Error(err.Error())
+	}
+}
+
+func (s *service) ListLoginLockouts() ([]models.LoginAttempts, error) {
+	lockouts, err := s.repo.ListLoginLockouts()
+	if err != nil {
+		err = fmt.Errorf("failed to list login lockouts: %w", err)
+		slog.Error(err.Error())
+		return nil, err
+	}
+
+	return lockouts, nil
+}
+
+func (s *service) ClearLoginLockout(scope, subject string) error {
+	switch scope {
+	case models.AttemptScopeUsername, models.AttemptScopeIP, models.AttemptScopeResetUsername, models.AttemptScopeResetIP:
+	default:
+		return ErrInvalidLockoutScope
+	}
+
+	if err := s.repo.ClearLoginAttempts(scope, subject); err != nil {
+		err = fmt.Errorf("failed to clear login lockout: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+type loginAttemptKey struct {
+	scope   string
+	subject string
+	free    int
+}
+
+func loginAttemptKeys(username, ip string) []loginAttemptKey {
+	keys := []loginAttemptKey{{scope: models.AttemptScopeUsername, subject: username, free: freeUsernameFailures}}
+	if ip != "" {
+		keys = append(keys, loginAttemptKey{scope: models.AttemptScopeIP, subject: ip, free: freeIPFailures})
+	}
+
+	return keys
+}
+
+func resetAttemptKeys(username, ip string) []loginAttemptKey {
+	keys := []loginAttemptKey{{scope: models.AttemptScopeResetUsername, subject: username, free: freeResetUsernameRequests}}
+	if ip != "" {
+		keys = append(keys, loginAttemptKey{scope: models.AttemptScopeResetIP, subject: ip, free: freeResetIPRequests})
+	}
+
+	return keys
+}
+
+// loginBackoff doubles the lockout with every failure past the free ones, up to maxLoginLockout.
+func loginBackoff(failures, free int) time.Duration {
+	if failures <= free {
+		return 0
+	}
+
+	delay := loginBackoffBase
+	for i := free + 1; i < failures && delay < maxLoginLockout; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxLoginLockout)
+}
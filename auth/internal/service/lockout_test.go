@@ -0,0 +1,33 @@
+package service
+
+import "testing"
+
+func TestLoginBackoffFreeFailures(t *testing.T) {
+	for failures := 0; failures <= freeUsernameFailures; failures++ {
+		if got := loginBackoff(failures, freeUsernameFailures); got != 0 {
+			t.Errorf("loginBackoff(%d, %d) = %v, want no lockout", failures, freeUsernameFailures, got)
+		}
+	}
+}
+
+func TestLoginBackoffDoubles(t *testing.T) {
+	want := loginBackoffBase
+	for failures := freeIPFailures + 1; want < maxLoginLockout; failures++ {
+		if got := loginBackoff(failures, freeIPFailures); got != want {
+			t.Fatalf("loginBackoff(%d, %d) = %v, want %v", failures, freeIPFailures, got, want)
+		}
+		want *= 2
+	}
+}
+
+func TestLoginBackoffCap(t *testing.T) {
+	for _, failures := range []int{10, 100, 1000} {
+		if got := loginBackoff(failures, 0); got != maxLoginLockout {
+			t.Errorf("loginBackoff(%d, 0) = %v, want %v", failures, got, maxLoginLockout)
+		}
+	}
+
+	if got := loginBackoff(1, 0); got != loginBackoffBase {
+		t.Errorf("loginBackoff(1, 0) = %v, want %v", got, loginBackoffBase)
+	}
+}
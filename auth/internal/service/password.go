@@ -0,0 +1,203 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/avran02/fileshare/auth/internal/models"
+	"github.com/avran02/fileshare/auth/internal/pkg/notifier"
+	"github.com/avran02/fileshare/auth/internal/repo"
+	"github.com/google/uuid"
+)
+
+const (
+	resetTokenBytes      = 32
+	defaultResetLifetime = 30 * time.Minute
+)
+
+var (
+	ErrWrongPassword     = errors.New("current password is wrong")
+	ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+)
+
+// ChangePassword replaces the password of the token's owner and signs out
+// every other session, keeping the one the change was made from. Personal
+// access tokens are revoked too. Wrong current passwords count towards the
+// login lockout, so a stolen session can't be used to guess the password.
+func (s *service) ChangePassword(accessToken, currentPassword, newPassword, ip string) error {
+	payload, err := s.validateAccessToken(accessToken)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repo.FindUserByID(payload.UserID)
+	if err != nil {
+		err = fmt.Errorf("failed to find user: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if err = s.checkLoginAllowed(user.Username, ip); err != nil {
+		return err
+	}
+
+	ok, err := s.hasher.Verify(currentPassword, user.Password)
+	if err != nil {
+		slog.Error("failed to verify password of user " + user.ID + ": " + err.Error())
+	}
+
+	if !ok {
+		slog.Error("wrong current password for user " + user.ID)
+		s.recordLoginFailure(user.Username, ip)
+		return ErrWrongPassword
+	}
+
+	if err = s.policy.Validate(newPassword); err != nil {
+		slog.Info("new password of user " + user.ID + " rejected: " + err.Error())
+		return err
+	}
+
+	hashedPass, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		err = fmt.Errorf("failed to hash password: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if err = s.repo.ChangePassword(user.ID, payload.SessionID, hashedPass); err != nil {
+		err = fmt.Errorf("failed to change password: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// RequestPasswordReset sends a single-use reset link to the user. Unknown and
+// disabled users are ignored silently so the response doesn't reveal who exists.
+// Requests are limited per username and client IP, whether the user exists or not.
+func (s *service) RequestPasswordReset(username, ip string) error {
+	keys := resetAttemptKeys(username, ip)
+	if err := s.checkAttemptsAllowed(keys); err != nil {
+		return err
+	}
+	s.recordAttempt(keys)
+
+	user, err := s.repo.FindUserByUsername(username)
+	if errors.Is(err, repo.ErrUserNotFound) {
+		slog.Warn("password reset requested for unknown user " + username)
+		return nil
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to find user: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if user.Disabled {
+		slog.Warn("password reset requested for disabled user " + user.ID)
+		return nil
+	}
+
+	secret := make([]byte, resetTokenBytes)
+	if _, err = rand.Read(secret); err != nil {
+		err = fmt.Errorf("failed to generate reset token: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	lifetime := s.resetConf.Lifetime
+	if lifetime <= 0 {
+		lifetime = defaultResetLifetime
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(secret)
+	reset := models.PasswordReset{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(lifetime),
+	}
+
+	if err = s.repo.CreatePasswordReset(reset); err != nil {
+		err = fmt.Errorf("failed to create password reset: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	link := token
+	if s.resetConf.URL != "" {
+		link = strings.ReplaceAll(s.resetConf.URL, "%s", token)
+	}
+
+	err = s.notifier.Send(notifier.Message{
+		Username: user.Username,
+		Subject:  "Password reset",
+		Body: fmt.Sprintf("Use the link below to choose a new password. It is valid until %s and works once.\n\n%s",
+			reset.ExpiresAt.Format(time.RFC1123Z), link),
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to send password reset: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password using a reset token and signs the user out
+// everywhere, revoking personal access tokens too.
+func (s *service) ResetPassword(token, newPassword string) error {
+	if err := s.policy.Validate(newPassword); err != nil {
+		slog.Info("new password rejected: " + err.Error())
+		return err
+	}
+
+	hashedPass, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		err = fmt.Errorf("failed to hash password: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	userID, err := s.repo.ResetPassword(hashToken(token), hashedPass)
+	if errors.Is(err, repo.ErrResetTokenNotFound) {
+		slog.Error(err.Error())
+		return ErrInvalidResetToken
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to reset password: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	slog.Info("password of user " + userID + " was reset")
+	return nil
+}
+
+// upgradePasswordHash rehashes the password with the configured algorithm and
+// parameters once a login proved it right. Failing to do so doesn't fail the login.
+func (s *service) upgradePasswordHash(user models.User, password string) {
+	if !s.hasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	hashedPass, err := s.hasher.Hash(password)
+	if err != nil {
+		slog.Error("failed to rehash password of user " + user.ID + ": " + err.Error())
+		return
+	}
+
+	if err = s.repo.UpgradePasswordHash(user.ID, user.Password, hashedPass); err != nil {
+		slog.Error("failed to upgrade password hash of user " + user.ID + ": " + err.Error())
+		return
+	}
+
+	slog.Info("upgraded password hash of user " + user.ID)
+}
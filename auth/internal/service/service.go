@@ -3,40 +3,103 @@ package service
 import (
 	"errors"
 	"fmt"
+	"log"
 	"log/slog"
+	"strings"
+	"time"
 
+	"github.com/avran02/fileshare/auth/internal/config"
+	"github.com/avran02/fileshare/auth/internal/models"
 	"github.com/avran02/fileshare/auth/internal/pkg/jwt"
+	"github.com/avran02/fileshare/auth/internal/pkg/notifier"
+	"github.com/avran02/fileshare/auth/internal/pkg/passwd"
 	"github.com/avran02/fileshare/auth/internal/repo"
-	"golang.org/x/crypto/bcrypt"
+	pb "github.com/avran02/fileshare/proto/authpb"
+	"github.com/google/uuid"
 )
 
 var (
 	ErrUserNotFound     = errors.New("user already exists")
 	ErrTokenDoesntExist = errors.New("token doesn't exist")
+	ErrEmptyUserQuery   = errors.New("either user id or username must be set")
+	ErrUserDisabled     = errors.New("user is disabled")
+	ErrNotAdmin         = errors.New("admin role required")
+	ErrSelfAdminAction  = errors.New("admins can't disable or delete themselves")
 )
 
 type Service interface {
 	Register(id, username, password string) error
-	Login(username, password string) (accessToken, refreshToken string, err error)
-	RefreshToken(token string) (string, error)
-	ValidateToken(token string) (string, error)
+	Login(username, password, userAgent, ip string) (LoginResult, error)
+	RefreshToken(token string) (accessToken, refreshToken string, err error)
+	ValidateToken(token string) (userID, role string, scopes []string, err error)
 	Logout(token string) (bool, error)
+	FindUser(id, username string) (models.User, error)
+
+	ChangePassword(accessToken, currentPassword, newPassword, ip string) error
+	RequestPasswordReset(username, ip string) error
+	ResetPassword(token, newPassword string) error
+
+	ListSessions(token string) (sessions []models.Session, currentID string, err error)
+	RevokeSession(token, sessionID string) error
+	RevokeOtherSessions(token string) error
+
+	VerifyLoginTOTP(challengeToken, code, userAgent, ip string) (accessToken, refreshToken string, err error)
+	BeginTOTPEnrollment(token string) (secret, uri string, err error)
+	ConfirmTOTPEnrollment(token, code, userAgent, ip string) (recoveryCodes []string, login LoginResult, err error)
+	DisableTOTP(accessToken, code string) error
+
+	CreatePersonalToken(accessToken, name string, scopes []string, expiresInDays int) (string, models.PersonalToken, error)
+	ListPersonalTokens(accessToken string) ([]models.PersonalToken, error)
+	RevokePersonalToken(accessToken, id string) error
+
+	JWKS() []jwt.JWK
+	ListRevocations(afterID int64, since time.Time) ([]models.Revocation, error)
+
+	CheckAdmin(token string) (adminID string, err error)
+	ListUsers(search string, page, pageSize int) ([]models.User, int, error)
+	SetUserDisabled(adminID, id string, disabled bool) error
+	DeleteUser(adminID, id string) error
+	ForceLogout(id string) error
+	GetSecuritySettings() (models.SecuritySettings, error)
+	ListLoginLockouts() ([]models.LoginAttempts, error)
+	ClearLoginLockout(scope, subject string) error
+	UpdateSecuritySettings(settings models.SecuritySettings) error
+}
+
+// LoginResult holds either a token pair or, when a second factor is still needed,
+// a challenge token to finish the login with.
+type LoginResult struct {
+	AccessToken      string
+	RefreshToken     string
+	ChallengeToken   string
+	MFARequired      bool
+	MFASetupRequired bool
 }
 
 type service struct {
-	repo repo.Repo
-	jwt  jwt.JwtGenerator
+	repo      repo.Repo
+	jwt       jwt.JwtGenerator
+	notifier  notifier.Notifier
+	hasher    passwd.Hasher
+	policy    passwd.Policy
+	resetConf config.PasswordReset
+	dummyHash string
 }
 
 func (s *service) Register(id, username, password string) error {
-	hashedPass, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err := s.policy.Validate(password); err != nil {
+		slog.Info("password of new user " + username + " rejected: " + err.Error())
+		return err
+	}
+
+	hashedPass, err := s.hasher.Hash(password)
 	if err != nil {
 		err = fmt.Errorf("failed to hash password: %w", err)
 		slog.Error(err.Error())
 		return err
 	}
 
-	if err = s.repo.CreateUser(username, string(hashedPass)); err != nil {
+	if err = s.repo.CreateUser(username, hashedPass, pb.RoleUser); err != nil {
 		err = fmt.Errorf("failed to create user: %w", err)
 		slog.Error(err.Error())
 		return err
@@ -45,153 +108,352 @@ func (s *service) Register(id, username, password string) error {
 	return nil
 }
 
-func (s *service) Login(username, password string) (accessToken, refreshToken string, err error) {
+// Login checks the password of a user. Unknown users and wrong passwords fail the
+// same way and count towards the lockout of both the username and the client IP.
+func (s *service) Login(username, password, userAgent, ip string) (LoginResult, error) {
+	if err := s.checkLoginAllowed(username, ip); err != nil {
+		return LoginResult{}, err
+	}
+
 	user, err := s.repo.FindUserByUsername(username)
-	if err != nil {
+	if err != nil && !errors.Is(err, repo.ErrUserNotFound) {
 		err = fmt.Errorf("failed to find user: %w", err)
 		slog.Error(err.Error())
-		return "", "", err
+		return LoginResult{}, err
 	}
 
-	slog.Info("Found user: " + user.Username)
+	// verify against a dummy hash for unknown users so timing doesn't give them away
+	passwordHash := s.dummyHash
+	if user.ID != "" {
+		passwordHash = user.Password
+	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	ok, err := s.hasher.Verify(password, passwordHash)
 	if err != nil {
-		err = fmt.Errorf("failed to compare password: %w", err)
-		slog.Error(err.Error())
-		return "", "", err
+		slog.Error("failed to verify password of user " + user.ID + ": " + err.Error())
+	}
+
+	if !ok || user.ID == "" {
+		slog.Error("failed login for username " + username)
+		s.recordLoginFailure(username, ip)
+		return LoginResult{}, ErrInvalidCredentials
 	}
 
-	accessTokenModel, err := s.jwt.Generate(user.ID, true)
+	// a disabled account fails like a wrong password, so the answer doesn't
+	// confirm that the password was right
+	if user.Disabled {
+		slog.Error("login of disabled user " + user.Username)
+		s.recordLoginFailure(username, ip)
+		return LoginResult{}, ErrInvalidCredentials
+	}
+
+	s.upgradePasswordHash(user, password)
+
+	return s.loginWithSecondFactor(user, userAgent, ip)
+}
+
+// startSession creates a session for a fully authenticated user.
+func (s *service) startSession(user models.User, userAgent, ip string) (accessToken, refreshToken string, err error) {
+	session := models.Session{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		UserAgent: userAgent,
+		IP:        ip,
+	}
+
+	accessTokenModel, err := s.jwt.Generate(jwt.Payload{
+		UserID:    user.ID,
+		Role:      user.Role,
+		SessionID: session.ID,
+		IsAccess:  true,
+	})
 	if err != nil {
 		err = fmt.Errorf("failed to generate token: %w", err)
 		slog.Error(err.Error())
 		return "", "", err
 	}
 
-	refreshTokenModel, err := s.jwt.Generate(user.ID, false)
+	refreshTokenModel, err := s.jwt.Generate(jwt.Payload{
+		UserID:    user.ID,
+		Role:      user.Role,
+		SessionID: session.ID,
+	})
 	if err != nil {
 		err = fmt.Errorf("failed to generate token: %w", err)
 		slog.Error(err.Error())
 		return "", "", err
 	}
 
-	if err = s.repo.DeleteUserTokensAndWriteNew(user.ID, accessTokenModel, refreshTokenModel); err != nil {
-		err = fmt.Errorf("failed to delete tokens: %w", err)
+	if err = s.repo.CreateSession(session, accessTokenModel, refreshTokenModel); err != nil {
+		err = fmt.Errorf("failed to create session: %w", err)
 		slog.Error(err.Error())
 		return "", "", err
 	}
 
+	s.clearLoginFailures(user.Username)
+
 	return accessTokenModel.Token, refreshTokenModel.Token, nil
 }
 
-func (s *service) RefreshToken(token string) (string, error) {
-	userId, isAccessToken, err := s.jwt.Validate(token) //nolint
+// RefreshToken rotates the refresh token of a session. Presenting a refresh token
+// that was already rotated means it leaked, so the whole session is revoked.
+func (s *service) RefreshToken(token string) (accessToken, refreshToken string, err error) {
+	payload, err := s.jwt.Validate(token)
 	if err != nil {
 		err = fmt.Errorf("failed to validate token: %w", err)
 		slog.Error(err.Error())
-		return "", err
+		return "", "", err
 	}
 
-	if isAccessToken {
+	if payload.IsAccess {
 		slog.Error("expected refresh token, got access token: " + jwt.ErrWrongTokenType.Error())
-		return "", fmt.Errorf("expected refresh token, got access token: %w", jwt.ErrWrongTokenType)
+		return "", "", fmt.Errorf("expected refresh token, got access token: %w", jwt.ErrWrongTokenType)
 	}
 
-	tokenExists, err := s.repo.CheckTokenExists(token)
+	user, err := s.repo.FindUserByID(payload.UserID)
 	if err != nil {
-		err = fmt.Errorf("failed to check token exists: %w", err)
+		err = fmt.Errorf("failed to find user: %w", err)
 		slog.Error(err.Error())
-		return "", err
+		return "", "", err
 	}
 
-	if !tokenExists {
-		slog.Error("token " + token + " does not exist")
-		return "", fmt.Errorf("token %s does not exist: %w", token, ErrTokenDoesntExist)
+	if user.Disabled {
+		slog.Error("user " + payload.UserID + " is disabled")
+		return "", "", ErrUserDisabled
+	}
+
+	newAccessToken, err := s.jwt.Generate(jwt.Payload{
+		UserID:    user.ID,
+		Role:      user.Role,
+		SessionID: payload.SessionID,
+		IsAccess:  true,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to generate token: %w", err)
+		slog.Error(err.Error())
+		return "", "", err
 	}
 
-	newAccessToken, err := s.jwt.Generate(userId, true)
+	newRefreshToken, err := s.jwt.Generate(jwt.Payload{
+		UserID:    user.ID,
+		Role:      user.Role,
+		SessionID: payload.SessionID,
+	})
 	if err != nil {
 		err = fmt.Errorf("failed to generate token: %w", err)
 		slog.Error(err.Error())
-		return "", err
+		return "", "", err
 	}
 
-	if err = s.repo.ReplaceUserAccessToken(newAccessToken); err != nil {
-		err = fmt.Errorf("failed to write token: %w", err)
+	err = s.repo.RotateRefreshToken(token, newAccessToken, newRefreshToken)
+	switch {
+	case errors.Is(err, repo.ErrTokenReused):
+		slog.Warn("security: refresh token reuse detected, revoking session",
+			"user_id", payload.UserID,
+			"session_id", payload.SessionID,
+		)
+		if err = s.repo.DeleteSession(payload.UserID, payload.SessionID); err != nil && !errors.Is(err, repo.ErrSessionNotFound) {
+			err = fmt.Errorf("failed to revoke session: %w", err)
+			slog.Error(err.Error())
+			return "", "", err
+		}
+		return "", "", fmt.Errorf("token %s was already used: %w", token, ErrTokenDoesntExist)
+	case errors.Is(err, repo.ErrTokenNotFound):
+		slog.Error("token " + token + " does not exist")
+		return "", "", fmt.Errorf("token %s does not exist: %w", token, ErrTokenDoesntExist)
+	case err != nil:
+		err = fmt.Errorf("failed to rotate token: %w", err)
 		slog.Error(err.Error())
-		return "", err
+		return "", "", err
 	}
 
-	return newAccessToken.Token, nil
+	return newAccessToken.Token, newRefreshToken.Token, nil
 }
 
-func (s *service) ValidateToken(token string) (string, error) {
-	userID, isAccessToken, err := s.jwt.Validate(token)
+// ValidateToken accepts session access tokens and personal access tokens. Only the
+// latter come with scopes, session tokens grant full access.
+func (s *service) ValidateToken(token string) (userID, role string, scopes []string, err error) {
+	if strings.HasPrefix(token, pb.PersonalTokenPrefix) {
+		return s.validatePersonalToken(token)
+	}
+
+	payload, err := s.validateAccessToken(token)
 	if err != nil {
-		err = fmt.Errorf("failed to validate token: %w", err)
+		return "", "", nil, err
+	}
+
+	if err = s.repo.TouchSession(payload.SessionID); err != nil {
+		slog.Warn(err.Error())
+	}
+
+	return payload.UserID, payload.Role, nil, nil
+}
+
+// Logout ends the session the token belongs to, leaving other sessions alive.
+func (s *service) Logout(token string) (bool, error) {
+	payload, err := s.validateAccessToken(token)
+	if err != nil {
+		return false, err
+	}
+
+	err = s.repo.DeleteSession(payload.UserID, payload.SessionID)
+	if err != nil {
+		err = fmt.Errorf("failed to remove session: %w", err)
 		slog.Error(err.Error())
-		return "", err
+		return false, err
 	}
 
-	if !isAccessToken {
-		slog.Error("expected access token, got refresh token: " + jwt.ErrWrongTokenType.Error())
-		return "", fmt.Errorf("expected access token, got refresh token: %w", jwt.ErrWrongTokenType)
+	return true, nil
+}
+
+func (s *service) ListSessions(token string) (sessions []models.Session, currentID string, err error) {
+	payload, err := s.validateAccessToken(token)
+	if err != nil {
+		return nil, "", err
 	}
 
-	exists, err := s.repo.CheckTokenExists(token)
+	sessions, err = s.repo.ListSessions(payload.UserID)
 	if err != nil {
-		err = fmt.Errorf("failed to check token exists: %w", err)
+		err = fmt.Errorf("failed to list sessions: %w", err)
 		slog.Error(err.Error())
-		return "", err
+		return nil, "", err
 	}
 
-	if !exists {
-		slog.Error("token " + token + " does not exist")
-		return "", fmt.Errorf("token %s does not exist: %w", token, ErrTokenDoesntExist)
+	return sessions, payload.SessionID, nil
+}
+
+func (s *service) RevokeSession(token, sessionID string) error {
+	payload, err := s.validateAccessToken(token)
+	if err != nil {
+		return err
+	}
+
+	if err = s.repo.DeleteSession(payload.UserID, sessionID); err != nil {
+		err = fmt.Errorf("failed to revoke session: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) RevokeOtherSessions(token string) error {
+	payload, err := s.validateAccessToken(token)
+	if err != nil {
+		return err
+	}
+
+	if err = s.repo.DeleteOtherSessions(payload.UserID, payload.SessionID); err != nil {
+		err = fmt.Errorf("failed to revoke sessions: %w", err)
+		slog.Error(err.Error())
+		return err
 	}
 
-	return userID, nil
+	return nil
 }
 
-func (s *service) Logout(token string) (bool, error) {
-	userID, isAccessToken, err := s.jwt.Validate(token)
+// validateAccessToken checks that token is a live access token of an enabled user.
+func (s *service) validateAccessToken(token string) (jwt.Payload, error) {
+	payload, err := s.jwt.Validate(token)
 	if err != nil {
 		err = fmt.Errorf("failed to validate token: %w", err)
 		slog.Error(err.Error())
-		return false, err
+		return jwt.Payload{}, err
 	}
 
-	if !isAccessToken {
+	if !payload.IsAccess {
 		slog.Error("expected access token, got refresh token: " + jwt.ErrWrongTokenType.Error())
-		return false, fmt.Errorf("expected access token, got refresh token: %w", jwt.ErrWrongTokenType)
+		return jwt.Payload{}, fmt.Errorf("expected access token, got refresh token: %w", jwt.ErrWrongTokenType)
 	}
 
 	exists, err := s.repo.CheckTokenExists(token)
 	if err != nil {
 		err = fmt.Errorf("failed to check token exists: %w", err)
 		slog.Error(err.Error())
-		return false, err
+		return jwt.Payload{}, err
 	}
 
 	if !exists {
 		slog.Error("token " + token + " does not exist")
-		return false, fmt.Errorf("token %s does not exist: %w", token, ErrTokenDoesntExist)
+		return jwt.Payload{}, fmt.Errorf("token %s does not exist: %w", token, ErrTokenDoesntExist)
 	}
 
-	err = s.repo.DeleteAllUserTokens(userID)
+	user, err := s.repo.FindUserByID(payload.UserID)
 	if err != nil {
-		err = fmt.Errorf("failed to remove token: %w", err)
+		err = fmt.Errorf("failed to find user: %w", err)
 		slog.Error(err.Error())
-		return false, err
+		return jwt.Payload{}, err
 	}
 
-	return true, nil
+	if user.Disabled {
+		slog.Error("user " + payload.UserID + " is disabled")
+		return jwt.Payload{}, ErrUserDisabled
+	}
+
+	return payload, nil
+}
+
+func (s *service) JWKS() []jwt.JWK {
+	return s.jwt.JWKS()
 }
 
-func New(repo repo.Repo, jwt jwt.JwtGenerator) Service {
+func (s *service) ListRevocations(afterID int64, since time.Time) ([]models.Revocation, error) {
+	revocations, err := s.repo.ListRevocations(afterID, since)
+	if err != nil {
+		err = fmt.Errorf("failed to list revocations: %w", err)
+		slog.Error(err.Error())
+		return nil, err
+	}
+
+	return revocations, nil
+}
+
+func (s *service) FindUser(id, username string) (models.User, error) {
+	var user models.User
+	var err error
+
+	switch {
+	case id != "":
+		user, err = s.repo.FindUserByID(id)
+	case username != "":
+		user, err = s.repo.FindUserByUsername(username)
+	default:
+		return models.User{}, ErrEmptyUserQuery
+	}
+
+	if err != nil {
+		err = fmt.Errorf("failed to find user: %w", err)
+		slog.Error(err.Error())
+		return models.User{}, err
+	}
+
+	if user.ID == "" {
+		return models.User{}, repo.ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+func New(
+	repo repo.Repo,
+	jwt jwt.JwtGenerator,
+	hasher passwd.Hasher,
+	policy passwd.Policy,
+	notifier notifier.Notifier,
+	resetConf config.PasswordReset,
+) Service {
+	dummyHash, err := hasher.Hash(uuid.NewString())
+	if err != nil {
+		log.Fatal("can't hash dummy password:\n", err)
+	}
+
 	return &service{
-		repo: repo,
-		jwt:  jwt,
+		repo:      repo,
+		jwt:       jwt,
+		notifier:  notifier,
+		hasher:    hasher,
+		policy:    policy,
+		resetConf: resetConf,
+		dummyHash: dummyHash,
 	}
 }
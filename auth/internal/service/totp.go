@@ -0,0 +1,374 @@
+package service
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/avran02/fileshare/auth/internal/models"
+	"github.com/avran02/fileshare/auth/internal/pkg/jwt"
+	"github.com/avran02/fileshare/auth/internal/pkg/totp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	totpIssuer = "FileShare"
+
+	recoveryCodesCount  = 10
+	recoveryCodeHalf    = 5
+	recoveryCodeLength  = 2*recoveryCodeHalf + 1
+	recoveryCodeCharset = "abcdefghijklmnopqrstuvwxyz234567"
+)
+
+var (
+	ErrInvalidCode        = errors.New("invalid two-factor code")
+	ErrTOTPNotEnrolled    = errors.New("two-factor authentication is not set up")
+	ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTOTPRequired       = errors.New("two-factor authentication is required for all accounts")
+	ErrChallengeUsed      = errors.New("login challenge was already used")
+)
+
+// loginWithSecondFactor finishes a login whose password checked out. Users with 2FA get
+// a challenge to answer with a code, users who must enroll first get a setup challenge.
+func (s *service) loginWithSecondFactor(user models.User, userAgent, ip string) (LoginResult, error) {
+	if user.TOTPEnabled {
+		challenge, err := s.jwt.GenerateChallenge(user.ID, jwt.ChallengeMFA)
+		if err != nil {
+			err = fmt.Errorf("failed to generate challenge: %w", err)
+			slog.Error(err.Error())
+			return LoginResult{}, err
+		}
+
+		return LoginResult{ChallengeToken: challenge, MFARequired: true}, nil
+	}
+
+	settings, err := s.repo.GetSecuritySettings()
+	if err != nil {
+		err = fmt.Errorf("failed to get security settings: %w", err)
+		slog.Error(err.Error())
+		return LoginResult{}, err
+	}
+
+	if settings.TOTPRequired {
+		challenge, err := s.jwt.GenerateChallenge(user.ID, jwt.ChallengeMFASetup)
+		if err != nil {
+			err = fmt.Errorf("failed to generate challenge: %w", err)
+			slog.Error(err.Error())
+			return LoginResult{}, err
+		}
+
+		return LoginResult{ChallengeToken: challenge, MFASetupRequired: true}, nil
+	}
+
+	accessToken, refreshToken, err := s.startSession(user, userAgent, ip)
+	if err != nil {
+		return LoginResult{}, err
+	}
+
+	return LoginResult{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+func (s *service) VerifyLoginTOTP(challengeToken, code, userAgent, ip string) (accessToken, refreshToken string, err error) {
+	challenge, err := s.jwt.ValidateChallenge(challengeToken, jwt.ChallengeMFA)
+	if err != nil {
+		err = fmt.Errorf("failed to validate challenge: %w", err)
+		slog.Error(err.Error())
+		return "", "", err
+	}
+
+	user, err := s.repo.FindUserByID(challenge.UserID)
+	if err != nil {
+		err = fmt.Errorf("failed to find user: %w", err)
+		slog.Error(err.Error())
+		return "", "", err
+	}
+
+	if user.Disabled {
+		slog.Error("user " + user.ID + " is disabled")
+		return "", "", ErrUserDisabled
+	}
+
+	if err = s.checkLoginAllowed(user.Username, ip); err != nil {
+		return "", "", err
+	}
+
+	if err = s.checkSecondFactor(user, code); err != nil {
+		if errors.Is(err, ErrInvalidCode) {
+			s.recordLoginFailure(user.Username, ip)
+		}
+		return "", "", err
+	}
+
+	if err = s.useChallenge(challenge); err != nil {
+		return "", "", err
+	}
+
+	return s.startSession(user, userAgent, ip)
+}
+
+// BeginTOTPEnrollment generates a new secret for the user. token is either an access
+// token or the setup challenge handed out by Login when 2FA is required.
+func (s *service) BeginTOTPEnrollment(token string) (secret, uri string, err error) {
+	user, _, err := s.enrollingUser(token)
+	if err != nil {
+		return "", "", err
+	}
+
+	if user.TOTPEnabled {
+		return "", "", ErrTOTPAlreadyEnabled
+	}
+
+	secret, err = totp.GenerateSecret()
+	if err != nil {
+		slog.Error(err.Error())
+		return "", "", err
+	}
+
+	if err = s.repo.SetTOTPSecret(user.ID, secret); err != nil {
+		err = fmt.Errorf("failed to set totp secret: %w", err)
+		slog.Error(err.Error())
+		return "", "", err
+	}
+
+	return secret, totp.ProvisioningURI(secret, totpIssuer, user.Username), nil
+}
+
+// ConfirmTOTPEnrollment enables 2FA once the user proved their app generates valid
+// codes. When enrolling through a setup challenge the login is finished as well.
+func (s *service) ConfirmTOTPEnrollment(token, code, userAgent, ip string) ([]string, LoginResult, error) {
+	user, challenge, err := s.enrollingUser(token)
+	if err != nil {
+		return nil, LoginResult{}, err
+	}
+	viaChallenge := challenge.ID != ""
+
+	if user.TOTPEnabled {
+		return nil, LoginResult{}, ErrTOTPAlreadyEnabled
+	}
+
+	if user.TOTPSecret == "" {
+		return nil, LoginResult{}, ErrTOTPNotEnrolled
+	}
+
+	if err = s.checkTOTPCode(user, code); err != nil {
+		return nil, LoginResult{}, err
+	}
+
+	if viaChallenge {
+		if err = s.useChallenge(challenge); err != nil {
+			return nil, LoginResult{}, err
+		}
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, LoginResult{}, err
+	}
+
+	if err = s.repo.EnableTOTP(user.ID, hashes); err != nil {
+		err = fmt.Errorf("failed to enable totp: %w", err)
+		slog.Error(err.Error())
+		return nil, LoginResult{}, err
+	}
+
+	if !viaChallenge {
+		return codes, LoginResult{}, nil
+	}
+
+	accessToken, refreshToken, err := s.startSession(user, userAgent, ip)
+	if err != nil {
+		return nil, LoginResult{}, err
+	}
+
+	return codes, LoginResult{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+func (s *service) DisableTOTP(accessToken, code string) error {
+	payload, err := s.validateAccessToken(accessToken)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repo.FindUserByID(payload.UserID)
+	if err != nil {
+		err = fmt.Errorf("failed to find user: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if !user.TOTPEnabled {
+		return ErrTOTPNotEnrolled
+	}
+
+	settings, err := s.repo.GetSecuritySettings()
+	if err != nil {
+		err = fmt.Errorf("failed to get security settings: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if settings.TOTPRequired {
+		return ErrTOTPRequired
+	}
+
+	if err = s.checkSecondFactor(user, code); err != nil {
+		return err
+	}
+
+	if err = s.repo.DisableTOTP(user.ID); err != nil {
+		err = fmt.Errorf("failed to disable totp: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// enrollingUser finds the user behind an access token or a setup challenge. The
+// challenge is returned empty for access tokens. Setup challenges are only honored
+// while 2FA is required, as that is the only time Login hands them out.
+func (s *service) enrollingUser(token string) (models.User, jwt.Challenge, error) {
+	challenge, challengeErr := s.jwt.ValidateChallenge(token, jwt.ChallengeMFASetup)
+	userID := challenge.UserID
+	if challengeErr != nil {
+		payload, err := s.validateAccessToken(token)
+		if err != nil {
+			return models.User{}, jwt.Challenge{}, err
+		}
+		challenge, userID = jwt.Challenge{}, payload.UserID
+	} else {
+		settings, err := s.repo.GetSecuritySettings()
+		if err != nil {
+			err = fmt.Errorf("failed to get security settings: %w", err)
+			slog.Error(err.Error())
+			return models.User{}, jwt.Challenge{}, err
+		}
+
+		if !settings.TOTPRequired {
+			slog.Error("setup challenge used while two-factor authentication is optional")
+			return models.User{}, jwt.Challenge{}, jwt.ErrInvalidToken
+		}
+	}
+
+	user, err := s.repo.FindUserByID(userID)
+	if err != nil {
+		err = fmt.Errorf("failed to find user: %w", err)
+		slog.Error(err.Error())
+		return models.User{}, jwt.Challenge{}, err
+	}
+
+	if user.Disabled {
+		slog.Error("user " + user.ID + " is disabled")
+		return models.User{}, jwt.Challenge{}, ErrUserDisabled
+	}
+
+	return user, challenge, nil
+}
+
+// useChallenge spends a challenge once it has been answered, so a leaked one
+// can't finish another login.
+func (s *service) useChallenge(challenge jwt.Challenge) error {
+	fresh, err := s.repo.UseChallenge(challenge.ID, challenge.ExpiresAt)
+	if err != nil {
+		err = fmt.Errorf("failed to use challenge: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if !fresh {
+		slog.Error("replayed challenge for user " + challenge.UserID)
+		return ErrChallengeUsed
+	}
+
+	return nil
+}
+
+// checkSecondFactor accepts a current TOTP code or an unused recovery code.
+func (s *service) checkSecondFactor(user models.User, code string) error {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if len(code) != recoveryCodeLength {
+		return s.checkTOTPCode(user, code)
+	}
+
+	codes, err := s.repo.ListRecoveryCodes(user.ID)
+	if err != nil {
+		err = fmt.Errorf("failed to list recovery codes: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	for _, recoveryCode := range codes {
+		if bcrypt.CompareHashAndPassword([]byte(recoveryCode.CodeHash), []byte(code)) != nil {
+			continue
+		}
+
+		used, err := s.repo.UseRecoveryCode(recoveryCode.ID)
+		if err != nil {
+			err = fmt.Errorf("failed to use recovery code: %w", err)
+			slog.Error(err.Error())
+			return err
+		}
+
+		if !used {
+			break
+		}
+
+		slog.Info("user " + user.ID + " logged in with a recovery code")
+		return nil
+	}
+
+	slog.Error("invalid recovery code for user " + user.ID)
+	return ErrInvalidCode
+}
+
+func (s *service) checkTOTPCode(user models.User, code string) error {
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+	if !ok {
+		slog.Error("invalid totp code for user " + user.ID)
+		return ErrInvalidCode
+	}
+
+	fresh, err := s.repo.UseTOTPStep(user.ID, step)
+	if err != nil {
+		err = fmt.Errorf("failed to use totp step: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if !fresh {
+		slog.Error("replayed totp code for user " + user.ID)
+		return ErrInvalidCode
+	}
+
+	return nil
+}
+
+func generateRecoveryCodes() (codes, hashes []string, err error) {
+	codes = make([]string, recoveryCodesCount)
+	hashes = make([]string, recoveryCodesCount)
+
+	buf := make([]byte, 2*recoveryCodeHalf)
+	for i := range codes {
+		if _, err = rand.Read(buf); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+
+		chars := make([]byte, len(buf))
+		for j, b := range buf {
+			chars[j] = recoveryCodeCharset[int(b)%len(recoveryCodeCharset)]
+		}
+		codes[i] = string(chars[:recoveryCodeHalf]) + "-" + string(chars[recoveryCodeHalf:])
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(codes[i]), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to hash recovery code: %w", err)
+		}
+		hashes[i] = string(hash)
+	}
+
+	return codes, hashes, nil
+}
@@ -0,0 +1,4 @@
+-- +migrate Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+
+ALTER TABLE users DROP COLUMN IF EXISTS disabled;
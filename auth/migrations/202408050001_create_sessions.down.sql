@@ -0,0 +1,12 @@
+-- +migrate Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+
+DROP INDEX IF EXISTS tokens_token_idx;
+
+DELETE FROM tokens;
+
+ALTER TABLE tokens
+    DROP COLUMN IF EXISTS session_id,
+    ALTER COLUMN token TYPE VARCHAR(255);
+
+DROP TABLE IF EXISTS sessions;
@@ -0,0 +1,6 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+ALTER TABLE tokens
+    ADD COLUMN IF NOT EXISTS rotated_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS replaced_by BIGINT REFERENCES tokens(id) ON DELETE SET NULL;
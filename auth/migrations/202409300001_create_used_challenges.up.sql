@@ -0,0 +1,7 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+CREATE TABLE IF NOT EXISTS used_challenges (
+    id VARCHAR(255) PRIMARY KEY NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
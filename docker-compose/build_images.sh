@@ -9,5 +9,5 @@ OLD_TAG="latest"
 docker rmi $(docker images -q fileshare/gateway:$OLD_TAG) 2>/dev/null
 docker rmi $(docker images -q fileshare/files:$OLD_TAG) 2>/dev/null
 
-docker build -t fileshare/gateway:$NEW_TAG -f $GATEWAY_DOCKERFILE ../gateway
-docker build -t fileshare/files:$NEW_TAG -f $FILES_DOCKERFILE ../files
+docker build -t fileshare/gateway:$NEW_TAG -f $GATEWAY_DOCKERFILE ..
+docker build -t fileshare/files:$NEW_TAG -f $FILES_DOCKERFILE ..
@@ -1,12 +1,15 @@
 package app
 
 import (
+	"context"
 	"log/slog"
 	"net"
 	"os"
+	"time"
 
 	"github.com/avran02/fileshare/files/internal/config"
 	"github.com/avran02/fileshare/files/internal/controller"
+	"github.com/avran02/fileshare/files/internal/repo"
 	"github.com/avran02/fileshare/files/internal/server"
 	"github.com/avran02/fileshare/files/internal/service"
 	pb "github.com/avran02/fileshare/proto/filespb"
@@ -23,6 +26,11 @@ type App struct {
 	Controller controller.FileServerController
 	Server     server.FileServer
 	Service    service.FilesService
+	Trash      service.TrashService
+	Versions   service.VersionService
+	Quota      service.QuotaService
+	Uploads    service.UploadService
+	Repo       repo.Repo
 }
 
 func (app *App) Run() {
@@ -33,6 +41,11 @@ func (app *App) Run() {
 		os.Exit(1)
 	}
 
+	go app.purgeTrash(context.Background())
+	go app.purgeQuotaReservations(context.Background())
+	go app.purgeUploads(context.Background())
+	go app.pruneVersions(context.Background())
+
 	slog.Info("Listening on " + host)
 
 	grpcServer := grpc.NewServer(opts...)
@@ -49,10 +62,149 @@ func (app *App) Run() {
 	}
 }
 
+// purgeTrash permanently deletes trash kept longer than the configured age.
+func (app *App) purgeTrash(ctx context.Context) {
+	ticker := time.NewTicker(app.Config.Trash.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		items, err := app.Trash.ListExpiredItems(ctx)
+		if err != nil {
+			slog.Error(err.Error())
+			continue
+		}
+
+		for _, item := range items {
+			if err = app.Service.PurgeTrash(ctx, item); err != nil {
+				slog.Error(err.Error())
+				continue
+			}
+
+			if err = app.Trash.RemoveItem(ctx, item.UserID, item.ID); err != nil {
+				slog.Error(err.Error())
+			}
+
+			if err = app.Quota.InvalidateUsage(ctx, item.UserID); err != nil {
+				slog.Error(err.Error())
+			}
+		}
+
+		if len(items) > 0 {
+			slog.Info("Purged expired trash items")
+		}
+	}
+}
+
+// purgeQuotaReservations drops the reservations of uploads which stopped
+// without finishing or cancelling them.
+func (app *App) purgeQuotaReservations(ctx context.Context) {
+	ticker := time.NewTicker(config.QuotaReservationPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := app.Quota.RemoveIdleReservations(ctx); err != nil {
+			slog.Error(err.Error())
+		}
+	}
+}
+
+// purgeUploads aborts upload sessions abandoned past their expiry.
+func (app *App) purgeUploads(ctx context.Context) {
+	ticker := time.NewTicker(app.Config.Upload.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		sessions, err := app.Uploads.ListExpiredSessions(ctx)
+		if err != nil {
+			slog.Error(err.Error())
+			continue
+		}
+
+		for _, session := range sessions {
+			if err = app.Service.AbortMultipartUpload(ctx, session); err != nil {
+				slog.Error(err.Error())
+			}
+
+			if err = app.Uploads.RemoveSession(ctx, session.UserID, session.ID); err != nil {
+				slog.Error(err.Error())
+			}
+
+			if err = app.Quota.CancelReservation(ctx, session.ID); err != nil {
+				slog.Error(err.Error())
+			}
+		}
+
+		if len(sessions) > 0 {
+			slog.Info("Aborted expired upload sessions")
+		}
+	}
+}
+
+// pruneVersions applies the retention policies to files which aren't
+// written again, as writes only prune the file being written.
+func (app *App) pruneVersions(ctx context.Context) {
+	ticker := time.NewTicker(app.Config.Versions.PruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		policies, err := app.Versions.ListRetentionPolicies(ctx)
+		if err != nil {
+			slog.Error(err.Error())
+			continue
+		}
+
+		for _, policy := range policies {
+			removed, err := app.Service.PruneBucketVersions(ctx, policy.UserID, policy)
+			if err != nil {
+				slog.Error(err.Error())
+			}
+
+			if removed == 0 {
+				continue
+			}
+
+			if err = app.Quota.InvalidateUsage(ctx, policy.UserID); err != nil {
+				slog.Error(err.Error())
+			}
+		}
+	}
+}
+
 func New() *App {
 	conf := config.New()
+	repo := repo.New(&conf.DB)
+	shareService := service.NewShareService(repo)
+	linkService := service.NewLinkService(repo)
+	versionService := service.NewVersionService(repo)
+	trashService := service.NewTrashService(repo, conf.Trash)
+	quotaService := service.NewQuotaService(repo, conf.Quota)
+	uploadService := service.NewUploadService(repo, conf.Upload)
 	service := service.New(conf.Minio)
-	controller := controller.New(service)
+	controller := controller.New(service, shareService, linkService, versionService, trashService, quotaService, uploadService)
 	server := server.New(controller)
 
 	return &App{
@@ -60,5 +212,10 @@ func New() *App {
 		Controller: controller,
 		Server:     server,
 		Service:    service,
+		Trash:      trashService,
+		Versions:   versionService,
+		Quota:      quotaService,
+		Uploads:    uploadService,
+		Repo:       repo,
 	}
 }
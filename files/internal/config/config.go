@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -10,6 +11,12 @@ import (
 type Config struct {
 	Minio  Minio  `yaml:"minio"`
 	Server Server `yaml:"server"`
+	DB     DB     `yaml:"db"`
+	Trash  Trash  `yaml:"trash"`
+	Quota  Quota  `yaml:"quota"`
+	Upload Upload `yaml:"upload"`
+
+	Versions Versions `yaml:"versions"`
 }
 
 type Minio struct {
@@ -23,6 +30,38 @@ type Server struct {
 	Host string `yaml:"host"`
 }
 
+type DB struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+}
+
+type Trash struct {
+	MaxAge        time.Duration `yaml:"maxAge"`
+	PurgeInterval time.Duration `yaml:"purgeInterval"`
+}
+
+// Quota is the storage limit of users without an override, 0 meaning unlimited.
+type Quota struct {
+	DefaultBytes int64 `yaml:"defaultBytes"`
+}
+
+type Versions struct {
+	PruneInterval time.Duration `yaml:"pruneInterval"`
+}
+
+type Upload struct {
+	ChunkSize     int64         `yaml:"chunkSize"`
+	SessionTTL    time.Duration `yaml:"sessionTTL"`
+	PurgeInterval time.Duration `yaml:"purgeInterval"`
+
+	// MaxChunkSize bounds how far the chunk size grows for large files, and
+	// so the largest file a session can upload and the memory a chunk takes.
+	MaxChunkSize int64 `yaml:"maxChunkSize"`
+}
+
 func New() *Config {
 	confFile, err := os.Open("config.yml")
 	if err != nil {
@@ -38,5 +77,29 @@ func New() *Config {
 	}
 
 	confFile.Close()
+
+	if config.Trash.MaxAge <= 0 {
+		config.Trash.MaxAge = DefaultTrashMaxAge
+	}
+	if config.Trash.PurgeInterval <= 0 {
+		config.Trash.PurgeInterval = DefaultTrashPurgeInterval
+	}
+	if config.Upload.ChunkSize < MinUploadChunkSize {
+		config.Upload.ChunkSize = MinUploadChunkSize
+	}
+	if config.Upload.MaxChunkSize < config.Upload.ChunkSize {
+		config.Upload.MaxChunkSize = max(config.Upload.ChunkSize, DefaultUploadMaxChunkSize)
+	}
+	if config.Upload.SessionTTL <= 0 {
+		config.Upload.SessionTTL = DefaultUploadSessionTTL
+	}
+	if config.Upload.PurgeInterval <= 0 {
+		config.Upload.PurgeInterval = DefaultUploadPurgeInterval
+	}
+
+	if config.Versions.PruneInterval <= 0 {
+		config.Versions.PruneInterval = DefaultVersionPruneInterval
+	}
+
 	return config
 }
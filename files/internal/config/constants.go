@@ -1,7 +1,27 @@
 package config
 
+import "time"
+
 const (
 	DefaultLocation  = "us-east-1"
 	DefaultFilesPath = "tmp/files"
 	StreamChunkSize  = 1024 * 1024 // 1 MB
+
+	DefaultTrashMaxAge        = 30 * 24 * time.Hour
+	DefaultTrashPurgeInterval = time.Hour
+
+	DefaultVersionPruneInterval = 24 * time.Hour
+
+	// QuotaReservationMaxIdle is how long an upload may go without claiming
+	// more of the quota before its reservation is considered abandoned.
+	QuotaReservationMaxIdle       = 6 * time.Hour
+	QuotaReservationPurgeInterval = time.Hour
+
+	// MinUploadChunkSize is the smallest part MinIO accepts in a multipart upload.
+	MinUploadChunkSize         = 5 * 1024 * 1024
+	DefaultUploadMaxChunkSize  = 64 * 1024 * 1024
+	DefaultUploadSessionTTL    = 24 * time.Hour
+	DefaultUploadPurgeInterval = time.Hour
+	// UploadStagingBucket keeps the data of upload chunks that are not complete yet.
+	UploadStagingBucket = "upload-staging"
 )
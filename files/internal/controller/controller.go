@@ -6,11 +6,15 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"strings"
 
 	"github.com/avran02/fileshare/files/internal/config"
 	"github.com/avran02/fileshare/files/internal/dto"
+	"github.com/avran02/fileshare/files/internal/models"
 	"github.com/avran02/fileshare/files/internal/service"
 	pb "github.com/avran02/fileshare/proto/filespb"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type FileServerController interface {
@@ -18,15 +22,62 @@ type FileServerController interface {
 	DownloadFile(req *pb.DownloadFileRequest, stream pb.FileService_DownloadFileServer) error
 	UploadFile(stream pb.FileService_UploadFileServer) error
 	RemoveFile(ctx context.Context, req *pb.RemoveFileRequest) (*pb.RemoveFileResponse, error)
+	CreateDirectory(ctx context.Context, req *pb.CreateDirectoryRequest) (*pb.CreateDirectoryResponse, error)
+	RemoveDirectory(ctx context.Context, req *pb.RemoveDirectoryRequest) (*pb.RemoveDirectoryResponse, error)
+	MoveFile(ctx context.Context, req *pb.MoveFileRequest) (*pb.MoveFileResponse, error)
+	CopyFile(ctx context.Context, req *pb.CopyFileRequest) (*pb.CopyFileResponse, error)
+
+	ListVersions(ctx context.Context, req *pb.ListVersionsRequest) (*pb.ListVersionsResponse, error)
+	RestoreVersion(ctx context.Context, req *pb.RestoreVersionRequest) (*pb.RestoreVersionResponse, error)
+	DeleteVersion(ctx context.Context, req *pb.DeleteVersionRequest) (*pb.DeleteVersionResponse, error)
+	GetRetentionPolicy(ctx context.Context, req *pb.GetRetentionPolicyRequest) (*pb.GetRetentionPolicyResponse, error)
+	SetRetentionPolicy(ctx context.Context, req *pb.SetRetentionPolicyRequest) (*pb.SetRetentionPolicyResponse, error)
+
+	CreateUploadSession(ctx context.Context, req *pb.CreateUploadSessionRequest) (*pb.CreateUploadSessionResponse, error)
+	GetUploadSession(ctx context.Context, req *pb.GetUploadSessionRequest) (*pb.GetUploadSessionResponse, error)
+	UploadChunk(stream pb.FileService_UploadChunkServer) error
+	AppendUpload(stream pb.FileService_AppendUploadServer) error
+	CompleteUploadSession(ctx context.Context, req *pb.CompleteUploadSessionRequest) (*pb.CompleteUploadSessionResponse, error)
+	AbortUploadSession(ctx context.Context, req *pb.AbortUploadSessionRequest) (*pb.AbortUploadSessionResponse, error)
+
+	GetUsage(ctx context.Context, req *pb.GetUsageRequest) (*pb.GetUsageResponse, error)
+	GetQuota(ctx context.Context, req *pb.GetQuotaRequest) (*pb.GetQuotaResponse, error)
+	SetQuota(ctx context.Context, req *pb.SetQuotaRequest) (*pb.SetQuotaResponse, error)
+
+	ListTrash(ctx context.Context, req *pb.ListTrashRequest) (*pb.ListTrashResponse, error)
+	RestoreTrash(ctx context.Context, req *pb.RestoreTrashRequest) (*pb.RestoreTrashResponse, error)
+	EmptyTrash(ctx context.Context, req *pb.EmptyTrashRequest) (*pb.EmptyTrashResponse, error)
 	RegisterUser(ctx context.Context, req *pb.RegisterUserRequest) (*pb.RegisterUserResponse, error)
+	DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error)
+
+	CreateShare(ctx context.Context, req *pb.CreateShareRequest) (*pb.CreateShareResponse, error)
+	RemoveShare(ctx context.Context, req *pb.RemoveShareRequest) (*pb.RemoveShareResponse, error)
+	ListShares(ctx context.Context, req *pb.ListSharesRequest) (*pb.ListSharesResponse, error)
+	ListIncomingShares(ctx context.Context, req *pb.ListIncomingSharesRequest) (*pb.ListIncomingSharesResponse, error)
+
+	CreateLink(ctx context.Context, req *pb.CreateLinkRequest) (*pb.CreateLinkResponse, error)
+	RevokeLink(ctx context.Context, req *pb.RevokeLinkRequest) (*pb.RevokeLinkResponse, error)
+	ListLinks(ctx context.Context, req *pb.ListLinksRequest) (*pb.ListLinksResponse, error)
+	ResolveLink(ctx context.Context, req *pb.ResolveLinkRequest) (*pb.ResolveLinkResponse, error)
 }
 
 type fileServerController struct {
-	Service service.FilesService
+	Service        service.FilesService
+	ShareService   service.ShareService
+	LinkService    service.LinkService
+	VersionService service.VersionService
+	TrashService   service.TrashService
+	QuotaService   service.QuotaService
+	UploadService  service.UploadService
 }
 
 func (c fileServerController) ListFiles(ctx context.Context, req *pb.ListFilesRequest) (*pb.ListFilesResponse, error) {
-	files, err := c.Service.ListFiles(ctx, req.UserID, req.FilePath)
+	bucketName, filePath, err := c.resolveRequestBucket(ctx, req.UserID, req.OwnerID, req.ShareID, req.LinkToken, req.LinkPassword, req.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	files, err := c.Service.ListFiles(ctx, bucketName, filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list files: %w", err)
 	}
@@ -38,11 +89,37 @@ func (c fileServerController) DownloadFile(req *pb.DownloadFileRequest, stream p
 	ctx := stream.Context()
 	streamErrChan := make(chan error, 1)
 
-	file, err := c.Service.DownloadFile(ctx, req.UserID, req.FilePath)
+	bucketName, filePath, err := c.resolveRequestBucket(ctx, req.UserID, req.OwnerID, req.ShareID, req.LinkToken, req.LinkPassword, req.FilePath)
 	if err != nil {
 		return fmt.Errorf("failed to download file: %w", err)
 	}
 
+	file, metadata, err := c.Service.DownloadFile(ctx, bucketName, filePath, req.VersionID, req.Offset, req.Length)
+	if err != nil {
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+
+	if req.LinkToken != "" {
+		if err = c.LinkService.RecordDownload(ctx, req.LinkToken, metadata.Offset, metadata.Length, metadata.Size); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to download file: %w", err)
+		}
+	}
+
+	if err = stream.Send(&pb.DownloadFileResponse{
+		Metadata: &pb.FileMetadata{
+			Size:         metadata.Size,
+			Etag:         metadata.ETag,
+			LastModified: timestamppb.New(metadata.LastModified),
+			ContentType:  metadata.ContentType,
+			Offset:       metadata.Offset,
+			Length:       metadata.Length,
+		},
+	}); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to send file metadata: %w", err)
+	}
+
 	go c.asyncSendFile(stream, file, streamErrChan)
 
 	if err = <-streamErrChan; err != nil {
@@ -70,12 +147,51 @@ func (c fileServerController) UploadFile(stream pb.FileService_UploadFileServer)
 		return ErrNotEmptyFirstChunk
 	}
 
-	requestDTO, err := dto.NewUploadFileStreamRequest(r.UserID, r.FilePath)
+	bucketName, filePath := r.UserID, r.FilePath
+	var (
+		dropLink *models.Link
+		maxSize  int64
+		uploaded bool
+	)
+	if r.LinkToken != "" {
+		link, reservedPath, size, err := c.reserveDropUpload(ctx, r)
+		if err != nil {
+			return fmt.Errorf("failed to upload file through link: %w", err)
+		}
+		dropLink, bucketName, filePath, maxSize = &link, link.OwnerID, reservedPath, size
+
+		defer func() {
+			if uploaded {
+				return
+			}
+			if err := c.LinkService.CancelDropUpload(context.WithoutCancel(ctx), link, filePath); err != nil {
+				slog.Error(err.Error())
+			}
+		}()
+	}
+
+	requestDTO, err := dto.NewUploadFileStreamRequest(bucketName, filePath)
 	if err != nil {
 		slog.Error(err.Error())
 		return fmt.Errorf("failed to get upload file request: %w", err)
 	}
 	defer requestDTO.CloseReader()
+	requestDTO.MaxSize = maxSize
+
+	reservationID := uuid.NewString()
+	defer func() {
+		if !uploaded {
+			c.cancelQuota(context.WithoutCancel(ctx), reservationID)
+		}
+	}()
+	requestDTO.Reserve = func(size int64) error {
+		if dropLink != nil {
+			if err := c.LinkService.GrowDropUpload(ctx, *dropLink, filePath, size); err != nil {
+				return err
+			}
+		}
+		return c.reserveQuota(ctx, bucketName, reservationID, size)
+	}
 
 	go c.asyncGetFileFromGrpcStream(stream, requestDTO, streamErrChan)
 
@@ -91,7 +207,17 @@ func (c fileServerController) UploadFile(stream pb.FileService_UploadFileServer)
 		return err
 	}
 
-	if err = stream.SendAndClose(&pb.UploadFileResponse{Success: true}); err != nil {
+	if dropLink != nil {
+		if err = c.LinkService.FinishDropUpload(ctx, *dropLink, filePath, requestDTO.Size()); err != nil {
+			slog.Error(err.Error())
+			return err
+		}
+	}
+	uploaded = true
+	c.finishQuota(ctx, bucketName, reservationID, requestDTO.Size())
+	c.pruneVersions(ctx, bucketName, filePath)
+
+	if err = stream.SendAndClose(&pb.UploadFileResponse{Success: true, FilePath: filePath}); err != nil {
 		err = fmt.Errorf("failed to send upload file response: %w", err)
 		slog.Error(err.Error())
 		return err
@@ -101,15 +227,114 @@ func (c fileServerController) UploadFile(stream pb.FileService_UploadFileServer)
 }
 
 func (c fileServerController) RemoveFile(ctx context.Context, req *pb.RemoveFileRequest) (*pb.RemoveFileResponse, error) {
-	err := c.Service.RemoveFile(ctx, req.UserID, req.FilePath)
+	item, _, err := c.moveToTrash(ctx, req.UserID, req.FilePath)
 	if err != nil {
 		return &pb.RemoveFileResponse{
-			Success: false,
+			Success:     false,
+			TrashItemID: item.ID,
 		}, err
 	}
 
 	return &pb.RemoveFileResponse{
+		Success:     true,
+		TrashItemID: item.ID,
+	}, nil
+}
+
+func (c fileServerController) CreateDirectory(ctx context.Context, req *pb.CreateDirectoryRequest) (*pb.CreateDirectoryResponse, error) {
+	err := c.Service.CreateDirectory(ctx, req.UserID, req.FilePath)
+	if err != nil {
+		return &pb.CreateDirectoryResponse{
+			Success: false,
+		}, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	return &pb.CreateDirectoryResponse{
+		Success: true,
+	}, nil
+}
+
+func (c fileServerController) RemoveDirectory(ctx context.Context, req *pb.RemoveDirectoryRequest) (*pb.RemoveDirectoryResponse, error) {
+	if req.Recursive {
+		item, moved, err := c.moveToTrash(ctx, req.UserID, strings.TrimSuffix(req.FilePath, "/")+"/")
+		if err != nil {
+			return &pb.RemoveDirectoryResponse{
+				Success:     false,
+				Removed:     moved,
+				TrashItemID: item.ID,
+			}, fmt.Errorf("failed to remove directory: %w", err)
+		}
+
+		return &pb.RemoveDirectoryResponse{
+			Success:     true,
+			Removed:     moved,
+			TrashItemID: item.ID,
+		}, nil
+	}
+
+	removed, err := c.Service.RemoveDirectory(ctx, req.UserID, req.FilePath, false)
+	if err != nil {
+		return &pb.RemoveDirectoryResponse{
+			Success: false,
+		}, fmt.Errorf("failed to remove directory: %w", err)
+	}
+
+	return &pb.RemoveDirectoryResponse{
 		Success: true,
+		Removed: removed,
+	}, nil
+}
+
+func (c fileServerController) MoveFile(ctx context.Context, req *pb.MoveFileRequest) (*pb.MoveFileResponse, error) {
+	moved, failed, err := c.Service.MoveFile(ctx, req.UserID, req.SrcPath, req.DstPath, req.Overwrite)
+	if err != nil {
+		return &pb.MoveFileResponse{
+			Success: false,
+		}, fmt.Errorf("failed to move file: %w", err)
+	}
+
+	// the old versions of moved files are gone
+	c.invalidateUsage(ctx, req.UserID)
+
+	return &pb.MoveFileResponse{
+		Success: len(failed) == 0,
+		Moved:   moved,
+		Failed:  failed,
+	}, nil
+}
+
+// CopyFile copies within the caller's bucket, or from another user's bucket
+// into the caller's one when the source is shared with them.
+func (c fileServerController) CopyFile(ctx context.Context, req *pb.CopyFileRequest) (*pb.CopyFileResponse, error) {
+	srcBucket, srcPath, err := c.resolveBucket(ctx, req.UserID, req.OwnerID, req.ShareID, req.SrcPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy file: %w", err)
+	}
+
+	var (
+		reservationID = uuid.NewString()
+		reserved      int64
+	)
+	copied, failed, err := c.Service.CopyFile(ctx, srcBucket, srcPath, req.UserID, req.DstPath, req.Overwrite, func(size int64) error {
+		reserved = size
+		return c.reserveQuota(ctx, req.UserID, reservationID, size)
+	})
+	if err != nil {
+		c.cancelQuota(context.WithoutCancel(ctx), reservationID)
+		return &pb.CopyFileResponse{
+			Success: false,
+		}, fmt.Errorf("failed to copy file: %w", err)
+	}
+
+	c.finishQuota(ctx, req.UserID, reservationID, reserved)
+	if len(failed) != 0 {
+		c.invalidateUsage(ctx, req.UserID)
+	}
+
+	return &pb.CopyFileResponse{
+		Success: len(failed) == 0,
+		Copied:  copied,
+		Failed:  failed,
 	}, nil
 }
 
@@ -126,6 +351,229 @@ func (c fileServerController) RegisterUser(ctx context.Context, req *pb.Register
 	}, nil
 }
 
+func (c fileServerController) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+	if err := c.ShareService.RemoveUserShares(ctx, req.UserID); err != nil {
+		return &pb.DeleteUserResponse{Success: false}, err
+	}
+
+	if err := c.LinkService.RemoveUserLinks(ctx, req.UserID); err != nil {
+		return &pb.DeleteUserResponse{Success: false}, err
+	}
+
+	if err := c.VersionService.RemoveUserRetentionPolicy(ctx, req.UserID); err != nil {
+		return &pb.DeleteUserResponse{Success: false}, err
+	}
+
+	if err := c.TrashService.RemoveUserTrash(ctx, req.UserID); err != nil {
+		return &pb.DeleteUserResponse{Success: false}, err
+	}
+
+	if err := c.QuotaService.RemoveUserQuota(ctx, req.UserID); err != nil {
+		return &pb.DeleteUserResponse{Success: false}, err
+	}
+
+	if err := c.UploadService.RemoveUserSessions(ctx, req.UserID); err != nil {
+		return &pb.DeleteUserResponse{Success: false}, err
+	}
+
+	if err := c.Service.RemoveUser(ctx, req.UserID); err != nil {
+		return &pb.DeleteUserResponse{Success: false}, err
+	}
+
+	return &pb.DeleteUserResponse{
+		Success: true,
+	}, nil
+}
+
+func (c fileServerController) CreateShare(ctx context.Context, req *pb.CreateShareRequest) (*pb.CreateShareResponse, error) {
+	exists, err := c.Service.PathExists(ctx, req.OwnerID, req.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create share: %w", err)
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("failed to create share: %w", ErrPathNotFound)
+	}
+
+	share, err := c.ShareService.CreateShare(ctx, req.OwnerID, req.RecipientID, req.FilePath, req.Permission)
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to create share: %w", err)
+	}
+
+	return &pb.CreateShareResponse{
+		Share: shareToPb(share),
+	}, nil
+}
+
+func (c fileServerController) RemoveShare(ctx context.Context, req *pb.RemoveShareRequest) (*pb.RemoveShareResponse, error) {
+	err := c.ShareService.RemoveShare(ctx, req.OwnerID, req.ShareID)
+	if err != nil {
+		return &pb.RemoveShareResponse{
+			Success: false,
+		}, err
+	}
+
+	return &pb.RemoveShareResponse{
+		Success: true,
+	}, nil
+}
+
+func (c fileServerController) ListShares(ctx context.Context, req *pb.ListSharesRequest) (*pb.ListSharesResponse, error) {
+	shares, err := c.ShareService.ListShares(ctx, req.OwnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shares: %w", err)
+	}
+
+	resp := &pb.ListSharesResponse{
+		Shares: make([]*pb.ShareInfo, len(shares)),
+	}
+	for i, share := range shares {
+		resp.Shares[i] = shareToPb(share)
+	}
+
+	return resp, nil
+}
+
+func (c fileServerController) ListIncomingShares(ctx context.Context, req *pb.ListIncomingSharesRequest) (*pb.ListIncomingSharesResponse, error) {
+	shares, err := c.ShareService.ListIncomingShares(ctx, req.RecipientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list incoming shares: %w", err)
+	}
+
+	resp := &pb.ListIncomingSharesResponse{
+		Shares: make([]*pb.ShareInfo, len(shares)),
+	}
+	for i, share := range shares {
+		resp.Shares[i] = shareToPb(share)
+	}
+
+	return resp, nil
+}
+
+func (c fileServerController) CreateLink(ctx context.Context, req *pb.CreateLinkRequest) (*pb.CreateLinkResponse, error) {
+	if req.Kind != models.LinkKindDrop {
+		exists, err := c.Service.PathExists(ctx, req.OwnerID, req.FilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create link: %w", err)
+		}
+
+		if !exists {
+			return nil, fmt.Errorf("failed to create link: %w", ErrPathNotFound)
+		}
+	}
+
+	link := models.Link{
+		Kind:         req.Kind,
+		OwnerID:      req.OwnerID,
+		Path:         req.FilePath,
+		MaxDownloads: req.MaxDownloads,
+		MaxFiles:     req.MaxFiles,
+		MaxBytes:     req.MaxBytes,
+	}
+	if req.ExpiresAt != nil {
+		expiresAt := req.ExpiresAt.AsTime()
+		link.ExpiresAt = &expiresAt
+	}
+
+	link, err := c.LinkService.CreateLink(ctx, link, req.Password)
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to create link: %w", err)
+	}
+
+	return &pb.CreateLinkResponse{
+		Link: linkToPb(link),
+	}, nil
+}
+
+func (c fileServerController) RevokeLink(ctx context.Context, req *pb.RevokeLinkRequest) (*pb.RevokeLinkResponse, error) {
+	err := c.LinkService.RevokeLink(ctx, req.OwnerID, req.LinkID)
+	if err != nil {
+		return &pb.RevokeLinkResponse{
+			Success: false,
+		}, err
+	}
+
+	return &pb.RevokeLinkResponse{
+		Success: true,
+	}, nil
+}
+
+func (c fileServerController) ListLinks(ctx context.Context, req *pb.ListLinksRequest) (*pb.ListLinksResponse, error) {
+	links, err := c.LinkService.ListLinks(ctx, req.OwnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list links: %w", err)
+	}
+
+	resp := &pb.ListLinksResponse{
+		Links: make([]*pb.LinkInfo, len(links)),
+	}
+	for i, link := range links {
+		resp.Links[i] = linkToPb(link)
+	}
+
+	return resp, nil
+}
+
+func (c fileServerController) ResolveLink(ctx context.Context, req *pb.ResolveLinkRequest) (*pb.ResolveLinkResponse, error) {
+	_, filePath, err := c.LinkService.ResolveLink(ctx, req.Token, req.Password, req.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve link: %w", err)
+	}
+
+	return &pb.ResolveLinkResponse{
+		FilePath: filePath,
+	}, nil
+}
+
+// reserveDropUpload claims a free name in a drop link's folder for the file
+// an anonymous uploader sends.
+func (c fileServerController) reserveDropUpload(ctx context.Context, r *pb.UploadFileRequest) (link models.Link, filePath string, maxSize int64, err error) {
+	link, err = c.LinkService.ResolveDropLink(ctx, r.LinkToken, r.LinkPassword)
+	if err != nil {
+		return models.Link{}, "", 0, err
+	}
+
+	filePath, maxSize, err = c.LinkService.ReserveDropUpload(ctx, link, r.FilePath, func(ctx context.Context, path string) (bool, error) {
+		return c.Service.PathExists(ctx, link.OwnerID, path)
+	})
+	if err != nil {
+		return models.Link{}, "", 0, err
+	}
+
+	return link, filePath, maxSize, nil
+}
+
+// resolveRequestBucket is resolveBucket for requests that may come through a
+// public link instead of an authenticated user.
+func (c fileServerController) resolveRequestBucket(ctx context.Context, userID, ownerID, shareID, linkToken, linkPassword, filePath string) (bucketName, path string, err error) {
+	if linkToken != "" {
+		return c.LinkService.ResolveLink(ctx, linkToken, linkPassword, filePath)
+	}
+
+	return c.resolveBucket(ctx, userID, ownerID, shareID, filePath)
+}
+
+// resolveBucket returns the bucket and path a request should be served from:
+// the caller's own bucket, or the owner's one if it was shared with the caller
+// either directly or through a share ID.
+func (c fileServerController) resolveBucket(ctx context.Context, userID, ownerID, shareID, filePath string) (bucketName, path string, err error) {
+	if shareID != "" {
+		return c.ShareService.ResolveShare(ctx, shareID, userID, filePath)
+	}
+
+	if ownerID == "" || ownerID == userID {
+		return userID, filePath, nil
+	}
+
+	if err := c.ShareService.CheckAccess(ctx, ownerID, userID, filePath); err != nil {
+		return "", "", err
+	}
+
+	return ownerID, filePath, nil
+}
+
 func (c fileServerController) asyncSendFile(stream pb.FileService_DownloadFileServer, file io.ReadCloser, streamErrChan chan error) {
 	defer close(streamErrChan)
 	defer file.Close()
@@ -172,6 +620,7 @@ func (c fileServerController) asyncGetFileFromGrpcStream(stream pb.FileService_U
 
 			err = fmt.Errorf("failed to receive upload file request: %w", err)
 			slog.Error(err.Error())
+			requestDTO.AbortWriter(err)
 			streamErrChan <- err
 			return
 		}
@@ -186,8 +635,48 @@ func (c fileServerController) asyncGetFileFromGrpcStream(stream pb.FileService_U
 	}
 }
 
-func New(service service.FilesService) FileServerController {
+func shareToPb(share models.Share) *pb.ShareInfo {
+	return &pb.ShareInfo{
+		Id:          share.ID,
+		OwnerID:     share.OwnerID,
+		RecipientID: share.RecipientID,
+		FilePath:    share.Path,
+		Permission:  share.Permission,
+		CreatedAt:   timestamppb.New(share.CreatedAt),
+	}
+}
+
+func linkToPb(link models.Link) *pb.LinkInfo {
+	info := &pb.LinkInfo{
+		Id:            link.ID,
+		Token:         link.Token,
+		Kind:          link.Kind,
+		OwnerID:       link.OwnerID,
+		FilePath:      link.Path,
+		MaxDownloads:  link.MaxDownloads,
+		Downloads:     link.Downloads,
+		MaxFiles:      link.MaxFiles,
+		Files:         link.Files,
+		MaxBytes:      link.MaxBytes,
+		UploadedBytes: link.UploadedBytes,
+		HasPassword:   link.PasswordHash != "",
+		CreatedAt:     timestamppb.New(link.CreatedAt),
+	}
+	if link.ExpiresAt != nil {
+		info.ExpiresAt = timestamppb.New(*link.ExpiresAt)
+	}
+
+	return info
+}
+
+func New(service service.FilesService, shareService service.ShareService, linkService service.LinkService, versionService service.VersionService, trashService service.TrashService, quotaService service.QuotaService, uploadService service.UploadService) FileServerController {
 	return fileServerController{
-		Service: service,
+		Service:        service,
+		ShareService:   shareService,
+		LinkService:    linkService,
+		VersionService: versionService,
+		TrashService:   trashService,
+		QuotaService:   quotaService,
+		UploadService:  uploadService,
 	}
 }
@@ -2,4 +2,8 @@ package controller
 
 import "errors"
 
-var ErrNotEmptyFirstChunk = errors.New("first chunk is not empty")
+var (
+	ErrNotEmptyFirstChunk = errors.New("first chunk is not empty")
+	ErrPathNotFound       = errors.New("path not found")
+	ErrPartialTrash       = errors.New("some objects could not be moved to trash")
+)
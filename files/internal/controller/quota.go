@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/avran02/fileshare/files/internal/models"
+	pb "github.com/avran02/fileshare/proto/filespb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (c fileServerController) GetUsage(ctx context.Context, req *pb.GetUsageRequest) (*pb.GetUsageResponse, error) {
+	quota, err := c.QuotaService.GetQuota(ctx, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage: %w", err)
+	}
+
+	usage, err := c.Service.Usage(ctx, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage: %w", err)
+	}
+
+	resp := &pb.GetUsageResponse{
+		UsedBytes:  usage.UsedBytes,
+		LimitBytes: quota.LimitBytes,
+		TrashBytes: usage.TrashBytes,
+		Folders:    make([]*pb.FolderUsage, len(usage.Folders)),
+	}
+	for i, folder := range usage.Folders {
+		resp.Folders[i] = &pb.FolderUsage{
+			Name:  folder.Name,
+			Bytes: folder.Bytes,
+		}
+	}
+
+	return resp, nil
+}
+
+func (c fileServerController) GetQuota(ctx context.Context, req *pb.GetQuotaRequest) (*pb.GetQuotaResponse, error) {
+	quota, err := c.QuotaService.GetQuota(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetQuotaResponse{
+		Quota: quotaToPb(quota),
+	}, nil
+}
+
+// SetQuota overrides the user's quota, or with useDefault set drops the override
+// so the configured default applies again.
+func (c fileServerController) SetQuota(ctx context.Context, req *pb.SetQuotaRequest) (*pb.SetQuotaResponse, error) {
+	var (
+		quota models.Quota
+		err   error
+	)
+	if req.UseDefault {
+		quota, err = c.QuotaService.ResetQuota(ctx, req.UserID)
+	} else {
+		quota, err = c.QuotaService.SetQuota(ctx, req.UserID, req.LimitBytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.SetQuotaResponse{
+		Quota: quotaToPb(quota),
+	}, nil
+}
+
+// reserveQuota claims bytes more of the user's quota for the write holding
+// reservationID. The storage is only walked when the usage has to be recounted.
+func (c fileServerController) reserveQuota(ctx context.Context, userID, reservationID string, bytes int64) error {
+	return c.QuotaService.Reserve(ctx, userID, reservationID, bytes, func(ctx context.Context) (int64, error) {
+		usage, err := c.Service.Usage(ctx, userID)
+		return usage.UsedBytes, err
+	})
+}
+
+// finishQuota counts what a write stored in place of its reservation. Failing
+// to do so doesn't fail the write.
+func (c fileServerController) finishQuota(ctx context.Context, userID, reservationID string, storedBytes int64) {
+	if err := c.QuotaService.FinishReservation(ctx, userID, reservationID, storedBytes); err != nil {
+		slog.Error(err.Error())
+		c.invalidateUsage(ctx, userID)
+	}
+}
+
+func (c fileServerController) cancelQuota(ctx context.Context, reservationID string) {
+	if err := c.QuotaService.CancelReservation(ctx, reservationID); err != nil {
+		slog.Error(err.Error())
+	}
+}
+
+// invalidateUsage has the usage recounted after space was freed.
+func (c fileServerController) invalidateUsage(ctx context.Context, userID string) {
+	if err := c.QuotaService.InvalidateUsage(ctx, userID); err != nil {
+		slog.Error(err.Error())
+	}
+}
+
+func quotaToPb(quota models.Quota) *pb.Quota {
+	info := &pb.Quota{
+		LimitBytes: quota.LimitBytes,
+		IsDefault:  quota.IsDefault,
+	}
+	if !quota.UpdatedAt.IsZero() {
+		info.UpdatedAt = timestamppb.New(quota.UpdatedAt)
+	}
+
+	return info
+}
@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/avran02/fileshare/files/internal/models"
+	pb "github.com/avran02/fileshare/proto/filespb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (c fileServerController) ListTrash(ctx context.Context, req *pb.ListTrashRequest) (*pb.ListTrashResponse, error) {
+	items, err := c.TrashService.ListItems(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListTrashResponse{
+		Items: make([]*pb.TrashItem, len(items)),
+	}
+	for i, item := range items {
+		resp.Items[i] = trashItemToPb(item)
+	}
+
+	return resp, nil
+}
+
+// RestoreTrash moves a trashed item back. The item stays in the trash until
+// all of it is restored, so a failed restore can be retried.
+func (c fileServerController) RestoreTrash(ctx context.Context, req *pb.RestoreTrashRequest) (*pb.RestoreTrashResponse, error) {
+	item, err := c.TrashService.GetItem(ctx, req.UserID, req.ItemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore from trash: %w", err)
+	}
+
+	filePath, restored, failed, err := c.Service.RestoreFromTrash(ctx, item, req.FilePath, req.Conflict)
+	if err != nil {
+		return &pb.RestoreTrashResponse{
+			Success: false,
+		}, fmt.Errorf("failed to restore from trash: %w", err)
+	}
+
+	if len(failed) == 0 {
+		if err = c.purgeTrashItem(ctx, item); err != nil {
+			slog.Error(err.Error())
+		}
+	}
+
+	return &pb.RestoreTrashResponse{
+		Success:  len(failed) == 0,
+		FilePath: filePath,
+		Restored: restored,
+		Failed:   failed,
+	}, nil
+}
+
+// EmptyTrash permanently deletes one trashed item, or all of them if no item
+// is given.
+func (c fileServerController) EmptyTrash(ctx context.Context, req *pb.EmptyTrashRequest) (*pb.EmptyTrashResponse, error) {
+	var items []models.TrashItem
+	if req.ItemID != "" {
+		item, err := c.TrashService.GetItem(ctx, req.UserID, req.ItemID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to empty trash: %w", err)
+		}
+		items = append(items, item)
+	} else {
+		var err error
+		items, err = c.TrashService.ListItems(ctx, req.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to empty trash: %w", err)
+		}
+	}
+
+	var removed int64
+	for _, item := range items {
+		if err := c.purgeTrashItem(ctx, item); err != nil {
+			return &pb.EmptyTrashResponse{
+				Success: false,
+				Removed: removed,
+			}, fmt.Errorf("failed to empty trash: %w", err)
+		}
+		removed++
+	}
+
+	return &pb.EmptyTrashResponse{
+		Success: true,
+		Removed: removed,
+	}, nil
+}
+
+// moveToTrash moves a path into the trash and records where it came from. If
+// the record can't be stored, the objects are put back.
+func (c fileServerController) moveToTrash(ctx context.Context, userID, filePath string) (models.TrashItem, int64, error) {
+	item, moved, failed, err := c.Service.MoveToTrash(ctx, userID, filePath)
+	if err != nil {
+		return models.TrashItem{}, 0, fmt.Errorf("failed to move to trash: %w", err)
+	}
+
+	stored, err := c.TrashService.AddItem(ctx, item)
+	if err != nil {
+		if _, _, _, restoreErr := c.Service.RestoreFromTrash(context.WithoutCancel(ctx), item, item.Path, models.RestoreConflictFail); restoreErr != nil {
+			slog.Error("failed to put back " + item.Path + " after trashing failed: " + restoreErr.Error())
+		}
+		return models.TrashItem{}, 0, fmt.Errorf("failed to move to trash: %w", err)
+	}
+
+	// the old versions of trashed files are gone
+	c.invalidateUsage(ctx, userID)
+
+	if len(failed) != 0 {
+		return stored, moved, fmt.Errorf("%w: %s", ErrPartialTrash, strings.Join(failed, ", "))
+	}
+
+	return stored, moved, nil
+}
+
+func (c fileServerController) purgeTrashItem(ctx context.Context, item models.TrashItem) error {
+	if err := c.Service.PurgeTrash(ctx, item); err != nil {
+		return fmt.Errorf("failed to purge trash item %s: %w", item.ID, err)
+	}
+	c.invalidateUsage(ctx, item.UserID)
+
+	return c.TrashService.RemoveItem(ctx, item.UserID, item.ID)
+}
+
+func trashItemToPb(item models.TrashItem) *pb.TrashItem {
+	return &pb.TrashItem{
+		Id:        item.ID,
+		FilePath:  item.Path,
+		IsDir:     item.IsDir,
+		Size:      item.Size,
+		DeletedAt: timestamppb.New(item.DeletedAt),
+		ExpiresAt: timestamppb.New(item.ExpiresAt),
+	}
+}
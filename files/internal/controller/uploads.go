@@ -0,0 +1,318 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/avran02/fileshare/files/internal/dto"
+	"github.com/avran02/fileshare/files/internal/models"
+	"github.com/avran02/fileshare/files/internal/repo"
+	"github.com/avran02/fileshare/files/internal/service"
+	pb "github.com/avran02/fileshare/proto/filespb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (c fileServerController) CreateUploadSession(ctx context.Context, req *pb.CreateUploadSessionRequest) (*pb.CreateUploadSessionResponse, error) {
+	session, err := c.UploadService.NewSession(req.UserID, req.FilePath, req.TotalSize, req.Checksum)
+	if err != nil {
+		return nil, uploadError(fmt.Errorf("failed to create upload session: %w", err))
+	}
+
+	// the session holds its whole size of the quota until it is completed
+	if err = c.reserveQuota(ctx, req.UserID, session.ID, req.TotalSize); err != nil {
+		return nil, uploadError(fmt.Errorf("failed to create upload session: %w", err))
+	}
+
+	session.UploadID, err = c.Service.StartMultipartUpload(ctx, session.UserID, session.Path)
+	if err != nil {
+		c.cancelQuota(context.WithoutCancel(ctx), session.ID)
+		return nil, fmt.Errorf("failed to create upload session: %w", err)
+	}
+
+	created, err := c.UploadService.AddSession(ctx, session)
+	if err != nil {
+		c.cancelQuota(context.WithoutCancel(ctx), session.ID)
+		if abortErr := c.Service.AbortMultipartUpload(context.WithoutCancel(ctx), session); abortErr != nil {
+			slog.Error(abortErr.Error())
+		}
+		return nil, err
+	}
+
+	return &pb.CreateUploadSessionResponse{
+		Session: uploadSessionToPb(created),
+	}, nil
+}
+
+func (c fileServerController) GetUploadSession(ctx context.Context, req *pb.GetUploadSessionRequest) (*pb.GetUploadSessionResponse, error) {
+	session, err := c.UploadService.GetSession(ctx, req.UserID, req.SessionID)
+	if err != nil {
+		return nil, uploadError(err)
+	}
+
+	chunks, err := c.Service.UploadedChunks(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upload session: %w", err)
+	}
+
+	offset, err := c.Service.UploadOffset(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upload session: %w", err)
+	}
+
+	resp := &pb.GetUploadSessionResponse{
+		Session:        uploadSessionToPb(session),
+		UploadedChunks: make([]int32, len(chunks)),
+		Offset:         offset,
+	}
+	for i, chunk := range chunks {
+		resp.UploadedChunks[i] = int32(chunk)
+	}
+
+	return resp, nil
+}
+
+// UploadChunk receives the session and chunk number in the first message and
+// the chunk content in the following ones, the same way UploadFile does.
+func (c fileServerController) UploadChunk(stream pb.FileService_UploadChunkServer) error {
+	slog.Info("Upload chunk")
+
+	streamErrChan := make(chan error, 1)
+	ctx := stream.Context()
+
+	r, err := stream.Recv()
+	if err != nil {
+		slog.Error(err.Error())
+		return fmt.Errorf("failed to receive upload chunk request: %w", err)
+	}
+
+	if len(r.Content) != 0 {
+		slog.Warn("Content should be empty")
+		return ErrNotEmptyFirstChunk
+	}
+
+	session, err := c.UploadService.GetSession(ctx, r.UserID, r.SessionID)
+	if err != nil {
+		return uploadError(err)
+	}
+
+	chunk := int(r.Chunk)
+	if chunk < 1 || chunk > session.ChunkCount() {
+		return fmt.Errorf("failed to upload chunk: %w", service.ErrInvalidChunk)
+	}
+
+	requestDTO, err := dto.NewUploadFileStreamRequest(session.UserID, session.Path)
+	if err != nil {
+		slog.Error(err.Error())
+		return fmt.Errorf("failed to get upload chunk request: %w", err)
+	}
+	defer requestDTO.CloseReader()
+	requestDTO.MaxSize = session.ChunkLength(chunk)
+	requestDTO.SizeLimitErr = service.ErrChunkSizeMismatch
+
+	go c.asyncGetContentFromGrpcStream(func() ([]byte, error) {
+		req, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		return req.Content, nil
+	}, requestDTO, streamErrChan)
+
+	size, err := c.Service.UploadChunk(ctx, session, chunk, requestDTO)
+	if err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = service.ErrChunkSizeMismatch
+		}
+		err = fmt.Errorf("failed to upload chunk: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if err = <-streamErrChan; err != nil {
+		err = fmt.Errorf("failed while uploading chunk from stream: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if err = stream.SendAndClose(&pb.UploadChunkResponse{Success: true, Chunk: r.Chunk, Size: size}); err != nil {
+		err = fmt.Errorf("failed to send upload chunk response: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// AppendUpload continues the upload from offset with the content of the
+// stream, for clients which upload the file sequentially.
+func (c fileServerController) AppendUpload(stream pb.FileService_AppendUploadServer) error {
+	slog.Info("Append to upload")
+
+	streamErrChan := make(chan error, 1)
+	ctx := stream.Context()
+
+	r, err := stream.Recv()
+	if err != nil {
+		slog.Error(err.Error())
+		return fmt.Errorf("failed to receive append upload request: %w", err)
+	}
+
+	if len(r.Content) != 0 {
+		slog.Warn("Content should be empty")
+		return ErrNotEmptyFirstChunk
+	}
+
+	session, err := c.UploadService.GetSession(ctx, r.UserID, r.SessionID)
+	if err != nil {
+		return uploadError(err)
+	}
+
+	requestDTO, err := dto.NewUploadFileStreamRequest(session.UserID, session.Path)
+	if err != nil {
+		slog.Error(err.Error())
+		return fmt.Errorf("failed to get append upload request: %w", err)
+	}
+	defer requestDTO.CloseReader()
+	requestDTO.MaxSize = session.TotalSize - r.Offset
+	requestDTO.SizeLimitErr = service.ErrUploadSizeExceeded
+
+	go c.asyncGetContentFromGrpcStream(func() ([]byte, error) {
+		req, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		return req.Content, nil
+	}, requestDTO, streamErrChan)
+
+	offset, err := c.Service.AppendUpload(ctx, session, r.Offset, requestDTO)
+	if err != nil {
+		err = fmt.Errorf("failed to append to upload: %w", err)
+		slog.Error(err.Error())
+		return uploadError(err)
+	}
+
+	if err = <-streamErrChan; err != nil {
+		err = fmt.Errorf("failed while appending to upload from stream: %w", err)
+		slog.Error(err.Error())
+		return uploadError(err)
+	}
+
+	if err = stream.SendAndClose(&pb.AppendUploadResponse{Offset: offset}); err != nil {
+		err = fmt.Errorf("failed to send append upload response: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c fileServerController) CompleteUploadSession(ctx context.Context, req *pb.CompleteUploadSessionRequest) (*pb.CompleteUploadSessionResponse, error) {
+	session, err := c.UploadService.GetSession(ctx, req.UserID, req.SessionID)
+	if err != nil {
+		return &pb.CompleteUploadSessionResponse{Success: false}, uploadError(err)
+	}
+
+	if err = c.Service.CompleteMultipartUpload(ctx, session); err != nil {
+		// the multipart upload is consumed even if the result is discarded
+		if errors.Is(err, service.ErrChecksumMismatch) {
+			if removeErr := c.UploadService.RemoveSession(ctx, session.UserID, session.ID); removeErr != nil {
+				slog.Error(removeErr.Error())
+			}
+			c.cancelQuota(ctx, session.ID)
+		}
+		return &pb.CompleteUploadSessionResponse{Success: false}, fmt.Errorf("failed to complete upload: %w", err)
+	}
+
+	if err = c.UploadService.RemoveSession(ctx, session.UserID, session.ID); err != nil {
+		slog.Error(err.Error())
+	}
+	c.finishQuota(ctx, session.UserID, session.ID, session.TotalSize)
+	c.pruneVersions(ctx, session.UserID, session.Path)
+
+	return &pb.CompleteUploadSessionResponse{
+		Success:  true,
+		FilePath: session.Path,
+	}, nil
+}
+
+func (c fileServerController) AbortUploadSession(ctx context.Context, req *pb.AbortUploadSessionRequest) (*pb.AbortUploadSessionResponse, error) {
+	session, err := c.UploadService.GetSession(ctx, req.UserID, req.SessionID)
+	if err != nil {
+		return &pb.AbortUploadSessionResponse{Success: false}, uploadError(err)
+	}
+
+	if err = c.Service.AbortMultipartUpload(ctx, session); err != nil {
+		return &pb.AbortUploadSessionResponse{Success: false}, fmt.Errorf("failed to abort upload: %w", err)
+	}
+
+	if err = c.UploadService.RemoveSession(ctx, session.UserID, session.ID); err != nil {
+		return &pb.AbortUploadSessionResponse{Success: false}, err
+	}
+	c.cancelQuota(ctx, session.ID)
+
+	return &pb.AbortUploadSessionResponse{Success: true}, nil
+}
+
+// asyncGetContentFromGrpcStream writes the content received by recv into the
+// request until the client closes the stream.
+func (c fileServerController) asyncGetContentFromGrpcStream(recv func() ([]byte, error), requestDTO *dto.UploadFileStreamRequest, streamErrChan chan error) {
+	defer close(streamErrChan)
+	defer requestDTO.CloseWriter()
+
+	for {
+		content, err := recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			err = fmt.Errorf("failed to receive upload chunk request: %w", err)
+			slog.Error(err.Error())
+			requestDTO.AbortWriter(err)
+			streamErrChan <- err
+			return
+		}
+
+		_, err = requestDTO.Write(content)
+		if err != nil {
+			err = fmt.Errorf("failed to write upload chunk request: %w", err)
+			slog.Error(err.Error())
+			streamErrChan <- err
+			return
+		}
+	}
+}
+
+// uploadError gives upload failures a status code, so the gateway can answer
+// resumable upload clients with the status they expect.
+func uploadError(err error) error {
+	switch {
+	case errors.Is(err, repo.ErrUploadSessionNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrUploadSessionExpired):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrOffsetMismatch):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, service.ErrUploadSizeExceeded), errors.Is(err, service.ErrQuotaExceeded), errors.Is(err, service.ErrUploadTooLarge):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	default:
+		return err
+	}
+}
+
+func uploadSessionToPb(session models.UploadSession) *pb.UploadSession {
+	return &pb.UploadSession{
+		Id:         session.ID,
+		FilePath:   session.Path,
+		TotalSize:  session.TotalSize,
+		ChunkSize:  session.ChunkSize,
+		ChunkCount: int32(session.ChunkCount()),
+		Checksum:   session.Checksum,
+		CreatedAt:  timestamppb.New(session.CreatedAt),
+		ExpiresAt:  timestamppb.New(session.ExpiresAt),
+	}
+}
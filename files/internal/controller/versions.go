@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/avran02/fileshare/files/internal/models"
+	pb "github.com/avran02/fileshare/proto/filespb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (c fileServerController) ListVersions(ctx context.Context, req *pb.ListVersionsRequest) (*pb.ListVersionsResponse, error) {
+	versions, err := c.Service.ListVersions(ctx, req.UserID, req.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+
+	resp := &pb.ListVersionsResponse{
+		Versions: make([]*pb.VersionInfo, len(versions)),
+	}
+	for i, version := range versions {
+		resp.Versions[i] = &pb.VersionInfo{
+			VersionID:      version.ID,
+			Size:           version.Size,
+			LastModified:   timestamppb.New(version.LastModified),
+			IsLatest:       version.IsLatest,
+			IsDeleteMarker: version.IsDeleteMarker,
+		}
+	}
+
+	return resp, nil
+}
+
+func (c fileServerController) RestoreVersion(ctx context.Context, req *pb.RestoreVersionRequest) (*pb.RestoreVersionResponse, error) {
+	versionID, err := c.Service.RestoreVersion(ctx, req.UserID, req.FilePath, req.VersionID)
+	if err != nil {
+		return &pb.RestoreVersionResponse{
+			Success: false,
+		}, fmt.Errorf("failed to restore version: %w", err)
+	}
+
+	c.invalidateUsage(ctx, req.UserID)
+	c.pruneVersions(ctx, req.UserID, req.FilePath)
+
+	return &pb.RestoreVersionResponse{
+		Success:   true,
+		VersionID: versionID,
+	}, nil
+}
+
+func (c fileServerController) DeleteVersion(ctx context.Context, req *pb.DeleteVersionRequest) (*pb.DeleteVersionResponse, error) {
+	err := c.Service.DeleteVersion(ctx, req.UserID, req.FilePath, req.VersionID)
+	if err != nil {
+		return &pb.DeleteVersionResponse{
+			Success: false,
+		}, fmt.Errorf("failed to delete version: %w", err)
+	}
+	c.invalidateUsage(ctx, req.UserID)
+
+	return &pb.DeleteVersionResponse{
+		Success: true,
+	}, nil
+}
+
+func (c fileServerController) GetRetentionPolicy(ctx context.Context, req *pb.GetRetentionPolicyRequest) (*pb.GetRetentionPolicyResponse, error) {
+	policy, err := c.VersionService.GetRetentionPolicy(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetRetentionPolicyResponse{
+		Policy: retentionPolicyToPb(policy),
+	}, nil
+}
+
+func (c fileServerController) SetRetentionPolicy(ctx context.Context, req *pb.SetRetentionPolicyRequest) (*pb.SetRetentionPolicyResponse, error) {
+	policy, err := c.VersionService.SetRetentionPolicy(ctx, req.UserID, req.KeepVersions, req.KeepDays)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.SetRetentionPolicyResponse{
+		Policy: retentionPolicyToPb(policy),
+	}, nil
+}
+
+// pruneVersions applies the owner's retention policy to a file that just got
+// a new version. Failing to prune doesn't fail the write that caused it.
+func (c fileServerController) pruneVersions(ctx context.Context, bucketName, filePath string) {
+	policy, err := c.VersionService.GetRetentionPolicy(ctx, bucketName)
+	if err != nil {
+		slog.Error(err.Error())
+		return
+	}
+
+	removed, err := c.Service.PruneVersions(ctx, bucketName, filePath, policy)
+	if err != nil {
+		slog.Error("failed to prune versions of " + filePath + ": " + err.Error())
+	}
+
+	if removed > 0 {
+		c.invalidateUsage(ctx, bucketName)
+	}
+}
+
+func retentionPolicyToPb(policy models.RetentionPolicy) *pb.RetentionPolicy {
+	info := &pb.RetentionPolicy{
+		KeepVersions: policy.KeepVersions,
+		KeepDays:     policy.KeepDays,
+	}
+	if !policy.UpdatedAt.IsZero() {
+		info.UpdatedAt = timestamppb.New(policy.UpdatedAt)
+	}
+
+	return info
+}
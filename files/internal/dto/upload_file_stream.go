@@ -5,9 +5,14 @@ import (
 	"io"
 )
 
+// reservationStep is how many bytes an upload claims at once, so that
+// reserving room doesn't cost a round trip for every chunk.
+const reservationStep = 16 * 1024 * 1024
+
 var (
 	ErrEmptyUserID   = errors.New("empty user id")
 	ErrEmptyFilePath = errors.New("empty file path")
+	ErrFileTooLarge  = errors.New("file is too large")
 )
 
 type UploadFileStreamRequest struct {
@@ -16,6 +21,16 @@ type UploadFileStreamRequest struct {
 	reader   *io.PipeReader
 	writer   *io.PipeWriter
 
+	// MaxSize limits how many bytes can be written, 0 meaning unlimited.
+	MaxSize int64
+	// SizeLimitErr is reported instead of ErrFileTooLarge once MaxSize is hit.
+	SizeLimitErr error
+	// Reserve claims room for size more bytes before they are written. An
+	// error aborts the upload.
+	Reserve  func(size int64) error
+	size     int64
+	reserved int64
+
 	Content []byte
 }
 
@@ -24,13 +39,62 @@ func (r *UploadFileStreamRequest) Read(buf []byte) (int, error) {
 }
 
 func (r *UploadFileStreamRequest) Write(buf []byte) (int, error) {
-	return (*r.writer).Write(buf)
+	if r.MaxSize > 0 && r.size+int64(len(buf)) > r.MaxSize {
+		err := ErrFileTooLarge
+		if r.SizeLimitErr != nil {
+			err = r.SizeLimitErr
+		}
+		r.writer.CloseWithError(err)
+		return 0, err
+	}
+
+	if err := r.reserve(int64(len(buf))); err != nil {
+		r.writer.CloseWithError(err)
+		return 0, err
+	}
+
+	n, err := (*r.writer).Write(buf)
+	r.size += int64(n)
+	return n, err
+}
+
+// reserve makes sure n more bytes are reserved. It claims a whole step when
+// it can and only the missing bytes otherwise.
+func (r *UploadFileStreamRequest) reserve(n int64) error {
+	missing := r.size + n - r.reserved
+	if r.Reserve == nil || missing <= 0 {
+		return nil
+	}
+
+	step := max(missing, reservationStep)
+	if err := r.Reserve(step); err != nil {
+		if step == missing {
+			return err
+		}
+		if err = r.Reserve(missing); err != nil {
+			return err
+		}
+		step = missing
+	}
+
+	r.reserved += step
+	return nil
+}
+
+func (r *UploadFileStreamRequest) Size() int64 {
+	return r.size
 }
 
 func (r *UploadFileStreamRequest) CloseWriter() {
 	r.writer.Close()
 }
 
+// AbortWriter makes the reader fail with err instead of seeing a clean EOF,
+// so a broken stream doesn't pass for a complete file.
+func (r *UploadFileStreamRequest) AbortWriter(err error) {
+	r.writer.CloseWithError(err)
+}
+
 func (r *UploadFileStreamRequest) CloseReader() {
 	r.reader.Close()
 }
@@ -0,0 +1,116 @@
+package dto
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+var errNoRoom = errors.New("no room")
+
+func TestReserve(t *testing.T) {
+	const mib = 1024 * 1024
+
+	tests := []struct {
+		name string
+		// room is how many bytes the Reserve hook grants in total
+		room   int64
+		writes []int64
+		claims []int64
+		err    error
+	}{
+		{
+			name:   "first write claims a step",
+			room:   100 * mib,
+			writes: []int64{mib},
+			claims: []int64{reservationStep},
+		},
+		{
+			name:   "writes within the step claim nothing more",
+			room:   100 * mib,
+			writes: []int64{mib, mib, 14 * mib},
+			claims: []int64{reservationStep},
+		},
+		{
+			name:   "next step once the first is used",
+			room:   100 * mib,
+			writes: []int64{16 * mib, mib},
+			claims: []int64{reservationStep, reservationStep},
+		},
+		{
+			name:   "write larger than a step",
+			room:   100 * mib,
+			writes: []int64{40 * mib},
+			claims: []int64{40 * mib},
+		},
+		{
+			name:   "falls back to the missing bytes",
+			room:   20 * mib,
+			writes: []int64{16 * mib, 4 * mib},
+			claims: []int64{reservationStep, 4 * mib},
+		},
+		{
+			name:   "no room left",
+			room:   20 * mib,
+			writes: []int64{16 * mib, 5 * mib},
+			claims: []int64{reservationStep},
+			err:    errNoRoom,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var claims []int64
+			granted := int64(0)
+			r := &UploadFileStreamRequest{Reserve: func(size int64) error {
+				if granted+size > tt.room {
+					return errNoRoom
+				}
+				granted += size
+				claims = append(claims, size)
+				return nil
+			}}
+
+			var err error
+			for _, n := range tt.writes {
+				if err = r.reserve(n); err != nil {
+					break
+				}
+				r.size += n
+			}
+
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("reserve() error = %v, want %v", err, tt.err)
+			}
+			if !slices.Equal(claims, tt.claims) {
+				t.Errorf("claims = %v, want %v", claims, tt.claims)
+			}
+		})
+	}
+}
+
+func TestReserveWithoutHook(t *testing.T) {
+	r := &UploadFileStreamRequest{}
+	if err := r.reserve(reservationStep * 4); err != nil {
+		t.Errorf("reserve() without a hook error = %v", err)
+	}
+}
+
+func TestAbortWriter(t *testing.T) {
+	r, err := NewUploadFileStreamRequest("user", "file")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errBroken := errors.New("stream broken")
+	go func() {
+		_, _ = r.Write([]byte("partial"))
+		r.AbortWriter(errBroken)
+		r.CloseWriter()
+	}()
+
+	if _, err = io.ReadAll(r); !errors.Is(err, errBroken) {
+		t.Errorf("reading an aborted upload: error = %v, want %v", err, errBroken)
+	}
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+const (
+	EntryTypeFile      = "file"
+	EntryTypeDirectory = "directory"
+)
+
+// FileMetadata describes a downloaded file and which of its bytes are sent,
+// starting at Offset.
+type FileMetadata struct {
+	Size         int64
+	ETag         string
+	LastModified time.Time
+	ContentType  string
+	Offset       int64
+	Length       int64
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	LinkKindDownload = "download"
+	LinkKindDrop     = "drop"
+)
+
+type Link struct {
+	ID            string
+	Token         string
+	Kind          string
+	OwnerID       string
+	Path          string
+	PasswordHash  string
+	ExpiresAt     *time.Time
+	MaxDownloads  int64
+	Downloads     int64
+	MaxFiles      int64
+	Files         int64
+	MaxBytes      int64
+	UploadedBytes int64
+	LockedUntil   *time.Time
+	CreatedAt     time.Time
+}
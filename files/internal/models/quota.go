@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Quota is the storage limit of a user, 0 meaning unlimited. IsDefault is set
+// when no admin override exists and the limit comes from the config.
+type Quota struct {
+	UserID     string
+	LimitBytes int64
+	IsDefault  bool
+	UpdatedAt  time.Time
+}
+
+type FolderUsage struct {
+	Name  string
+	Bytes int64
+}
+
+// Usage counts every stored version of a file, since old versions take up
+// space as well.
+type Usage struct {
+	UsedBytes  int64
+	TrashBytes int64
+	Folders    []FolderUsage
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+const (
+	PermissionRead = "read"
+)
+
+type Share struct {
+	ID          string
+	OwnerID     string
+	RecipientID string
+	Path        string
+	Permission  string
+	CreatedAt   time.Time
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+const (
+	RestoreConflictFail      = "fail"
+	RestoreConflictOverwrite = "overwrite"
+	RestoreConflictRename    = "rename"
+)
+
+type TrashItem struct {
+	ID        string
+	UserID    string
+	Path      string
+	IsDir     bool
+	Size      int64
+	DeletedAt time.Time
+	ExpiresAt time.Time
+}
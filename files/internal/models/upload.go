@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// UploadSession is a resumable upload backed by a MinIO multipart upload.
+// Chunks are numbered from 1 and all but the last one are ChunkSize long.
+type UploadSession struct {
+	ID        string
+	UserID    string
+	Path      string
+	UploadID  string
+	TotalSize int64
+	ChunkSize int64
+	Checksum  string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+func (s UploadSession) ChunkCount() int {
+	return int((s.TotalSize + s.ChunkSize - 1) / s.ChunkSize)
+}
+
+// ChunkLength returns the exact size the chunk must have.
+func (s UploadSession) ChunkLength(chunk int) int64 {
+	if chunk == s.ChunkCount() {
+		return s.TotalSize - int64(chunk-1)*s.ChunkSize
+	}
+	return s.ChunkSize
+}
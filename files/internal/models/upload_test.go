@@ -0,0 +1,43 @@
+package models
+
+import "testing"
+
+func TestUploadSessionChunks(t *testing.T) {
+	tests := []struct {
+		name      string
+		totalSize int64
+		chunkSize int64
+		count     int
+		last      int64
+	}{
+		{name: "single partial chunk", totalSize: 10, chunkSize: 64, count: 1, last: 10},
+		{name: "single full chunk", totalSize: 64, chunkSize: 64, count: 1, last: 64},
+		{name: "exact multiple", totalSize: 192, chunkSize: 64, count: 3, last: 64},
+		{name: "short last chunk", totalSize: 200, chunkSize: 64, count: 4, last: 8},
+		{name: "one byte over", totalSize: 65, chunkSize: 64, count: 2, last: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := UploadSession{TotalSize: tt.totalSize, ChunkSize: tt.chunkSize}
+
+			if got := s.ChunkCount(); got != tt.count {
+				t.Fatalf("ChunkCount() = %d, want %d", got, tt.count)
+			}
+
+			var sum int64
+			for chunk := 1; chunk < tt.count; chunk++ {
+				if got := s.ChunkLength(chunk); got != tt.chunkSize {
+					t.Errorf("ChunkLength(%d) = %d, want %d", chunk, got, tt.chunkSize)
+				}
+				sum += tt.chunkSize
+			}
+			if got := s.ChunkLength(tt.count); got != tt.last {
+				t.Errorf("ChunkLength(%d) = %d, want %d", tt.count, got, tt.last)
+			}
+			if sum+tt.last != tt.totalSize {
+				t.Errorf("chunks add up to %d, want %d", sum+tt.last, tt.totalSize)
+			}
+		})
+	}
+}
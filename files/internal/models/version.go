@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type Version struct {
+	ID             string
+	Size           int64
+	LastModified   time.Time
+	IsLatest       bool
+	IsDeleteMarker bool
+}
+
+// RetentionPolicy limits how many old versions of a file are kept. A zero
+// field sets no limit, and with both set an old version is kept while it
+// satisfies either of them.
+type RetentionPolicy struct {
+	UserID       string
+	KeepVersions int64
+	KeepDays     int64
+	UpdatedAt    time.Time
+}
@@ -0,0 +1,1134 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"time"
+
+	"github.com/avran02/fileshare/files/internal/config"
+	"github.com/avran02/fileshare/files/internal/models"
+	_ "github.com/lib/pq"
+)
+
+var (
+	ErrShareNotFound = errors.New("share does not exist")
+	ErrLinkNotFound  = errors.New("link does not exist")
+	ErrLinkExhausted = errors.New("link limit reached")
+	ErrPathTaken     = errors.New("path is already taken")
+
+	ErrTrashItemNotFound = errors.New("trash item does not exist")
+	ErrQuotaNotFound     = errors.New("quota does not exist")
+	ErrQuotaExceeded     = errors.New("storage quota exceeded")
+
+	ErrUploadSessionNotFound = errors.New("upload session does not exist")
+)
+
+type Repo interface {
+	CreateShare(ctx context.Context, share models.Share) (models.Share, error)
+	DeleteShare(ctx context.Context, ownerID, shareID string) error
+	GetShare(ctx context.Context, shareID string) (models.Share, error)
+	ListSharesByOwner(ctx context.Context, ownerID string) ([]models.Share, error)
+	ListSharesByOwnerAndRecipient(ctx context.Context, ownerID, recipientID string) ([]models.Share, error)
+	ListSharesByRecipient(ctx context.Context, recipientID string) ([]models.Share, error)
+	DeleteUserShares(ctx context.Context, userID string) error
+
+	CreateLink(ctx context.Context, link models.Link) (models.Link, error)
+	DeleteLink(ctx context.Context, ownerID, linkID string) error
+	GetLinkByToken(ctx context.Context, token string) (models.Link, error)
+	ListLinksByOwner(ctx context.Context, ownerID string) ([]models.Link, error)
+	IncrementLinkDownloads(ctx context.Context, token string, offset, length, size int64) error
+	RecordLinkPasswordFailure(ctx context.Context, linkID string, window time.Duration) (int, error)
+	LockLink(ctx context.Context, linkID string, until time.Time) error
+	ReserveDropUpload(ctx context.Context, linkID, ownerID, path string) (int64, error)
+	GrowDropUpload(ctx context.Context, linkID, path string, bytes int64) error
+	FinishDropUpload(ctx context.Context, linkID, path string, size int64) error
+	DeleteDropUpload(ctx context.Context, linkID, path string) error
+	DeleteUserLinks(ctx context.Context, ownerID string) error
+
+	GetRetentionPolicy(ctx context.Context, userID string) (models.RetentionPolicy, error)
+	SetRetentionPolicy(ctx context.Context, policy models.RetentionPolicy) (models.RetentionPolicy, error)
+	DeleteRetentionPolicy(ctx context.Context, userID string) error
+	ListRetentionPolicies(ctx context.Context) ([]models.RetentionPolicy, error)
+
+	CreateTrashItem(ctx context.Context, item models.TrashItem) (models.TrashItem, error)
+	GetTrashItem(ctx context.Context, userID, itemID string) (models.TrashItem, error)
+	ListTrashItems(ctx context.Context, userID string) ([]models.TrashItem, error)
+	ListTrashItemsDeletedBefore(ctx context.Context, before time.Time, limit int) ([]models.TrashItem, error)
+	DeleteTrashItem(ctx context.Context, userID, itemID string) error
+	DeleteUserTrashItems(ctx context.Context, userID string) error
+
+	GetQuota(ctx context.Context, userID string) (models.Quota, error)
+	SetQuota(ctx context.Context, quota models.Quota) (models.Quota, error)
+	DeleteQuota(ctx context.Context, userID string) error
+
+	ReserveStorage(ctx context.Context, reservationID, userID string, limitBytes, bytes int64, count func(ctx context.Context) (int64, error)) error
+	FinishStorageReservation(ctx context.Context, reservationID, userID string, storedBytes int64) error
+	DeleteStorageReservation(ctx context.Context, reservationID string) error
+	DeleteIdleStorageReservations(ctx context.Context, before time.Time) error
+	MarkStorageUsageStale(ctx context.Context, userID string) error
+	DeleteStorageUsage(ctx context.Context, userID string) error
+
+	CreateUploadSession(ctx context.Context, session models.UploadSession) (models.UploadSession, error)
+	GetUploadSession(ctx context.Context, userID, sessionID string) (models.UploadSession, error)
+	ListUploadSessionsExpiredBefore(ctx context.Context, before time.Time, limit int) ([]models.UploadSession, error)
+	DeleteUploadSession(ctx context.Context, userID, sessionID string) error
+	DeleteUserUploadSessions(ctx context.Context, userID string) error
+}
+
+type repo struct {
+	*sql.DB
+}
+
+func (r *repo) CreateShare(ctx context.Context, share models.Share) (models.Share, error) {
+	query := `
+		INSERT INTO shares (id, owner_id, recipient_id, path, permission)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (owner_id, recipient_id, path)
+		DO UPDATE SET permission = EXCLUDED.permission
+		RETURNING id, created_at
+	`
+
+	row := r.QueryRowContext(ctx, query, share.ID, share.OwnerID, share.RecipientID, share.Path, share.Permission)
+	if err := row.Scan(&share.ID, &share.CreatedAt); err != nil {
+		err = fmt.Errorf("failed to create share: %w", err)
+		slog.Error(err.Error())
+		return models.Share{}, err
+	}
+
+	return share, nil
+}
+
+func (r *repo) DeleteShare(ctx context.Context, ownerID, shareID string) error {
+	query := "DELETE FROM shares WHERE id = $1 AND owner_id = $2"
+
+	res, err := r.ExecContext(ctx, query, shareID, ownerID)
+	if err != nil {
+		err = fmt.Errorf("failed to delete share: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return ErrShareNotFound
+	}
+
+	return nil
+}
+
+func (r *repo) GetShare(ctx context.Context, shareID string) (models.Share, error) {
+	query := `
+		SELECT id, owner_id, recipient_id, path, permission, created_at
+		FROM shares WHERE id = $1
+	`
+
+	var share models.Share
+	row := r.QueryRowContext(ctx, query, shareID)
+	if err := row.Scan(&share.ID, &share.OwnerID, &share.RecipientID, &share.Path, &share.Permission, &share.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Share{}, ErrShareNotFound
+		}
+		err = fmt.Errorf("failed to get share: %w", err)
+		slog.Error(err.Error())
+		return models.Share{}, err
+	}
+
+	return share, nil
+}
+
+func (r *repo) ListSharesByOwner(ctx context.Context, ownerID string) ([]models.Share, error) {
+	query := `
+		SELECT id, owner_id, recipient_id, path, permission, created_at
+		FROM shares WHERE owner_id = $1
+		ORDER BY created_at DESC
+	`
+
+	return r.queryShares(ctx, query, ownerID)
+}
+
+func (r *repo) ListSharesByOwnerAndRecipient(ctx context.Context, ownerID, recipientID string) ([]models.Share, error) {
+	query := `
+		SELECT id, owner_id, recipient_id, path, permission, created_at
+		FROM shares WHERE owner_id = $1 AND recipient_id = $2
+	`
+
+	return r.queryShares(ctx, query, ownerID, recipientID)
+}
+
+func (r *repo) ListSharesByRecipient(ctx context.Context, recipientID string) ([]models.Share, error) {
+	query := `
+		SELECT id, owner_id, recipient_id, path, permission, created_at
+		FROM shares WHERE recipient_id = $1
+		ORDER BY created_at DESC
+	`
+
+	return r.queryShares(ctx, query, recipientID)
+}
+
+func (r *repo) DeleteUserShares(ctx context.Context, userID string) error {
+	query := "DELETE FROM shares WHERE owner_id = $1 OR recipient_id = $1"
+
+	if _, err := r.ExecContext(ctx, query, userID); err != nil {
+		err = fmt.Errorf("failed to delete user shares: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (r *repo) queryShares(ctx context.Context, query string, args ...any) ([]models.Share, error) {
+	rows, err := r.QueryContext(ctx, query, args...)
+	if err != nil {
+		err = fmt.Errorf("failed to query shares: %w", err)
+		slog.Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	shares := make([]models.Share, 0)
+	for rows.Next() {
+		var share models.Share
+		if err = rows.Scan(&share.ID, &share.OwnerID, &share.RecipientID, &share.Path, &share.Permission, &share.CreatedAt); err != nil {
+			err = fmt.Errorf("failed to scan share: %w", err)
+			slog.Error(err.Error())
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+
+	if err = rows.Err(); err != nil {
+		err = fmt.Errorf("failed to iterate shares: %w", err)
+		slog.Error(err.Error())
+		return nil, err
+	}
+
+	return shares, nil
+}
+
+const selectLinks = `
+	SELECT l.id, l.token, l.kind, l.owner_id, l.path, l.password_hash, l.expires_at,
+		l.max_downloads, l.downloads, l.max_files, COUNT(u.path), l.max_bytes, COALESCE(SUM(u.size), 0), l.locked_until, l.created_at
+	FROM links l LEFT JOIN drop_uploads u ON u.link_id = l.id
+`
+
+func (r *repo) CreateLink(ctx context.Context, link models.Link) (models.Link, error) {
+	query := `
+		INSERT INTO links (id, token, kind, owner_id, path, password_hash, expires_at, max_downloads, max_files, max_bytes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING created_at
+	`
+
+	row := r.QueryRowContext(ctx, query, link.ID, link.Token, link.Kind, link.OwnerID, link.Path, link.PasswordHash, link.ExpiresAt, link.MaxDownloads, link.MaxFiles, link.MaxBytes)
+	if err := row.Scan(&link.CreatedAt); err != nil {
+		err = fmt.Errorf("failed to create link: %w", err)
+		slog.Error(err.Error())
+		return models.Link{}, err
+	}
+
+	return link, nil
+}
+
+func (r *repo) DeleteLink(ctx context.Context, ownerID, linkID string) error {
+	query := "DELETE FROM links WHERE id = $1 AND owner_id = $2"
+
+	res, err := r.ExecContext(ctx, query, linkID, ownerID)
+	if err != nil {
+		err = fmt.Errorf("failed to delete link: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return ErrLinkNotFound
+	}
+
+	return nil
+}
+
+func (r *repo) GetLinkByToken(ctx context.Context, token string) (models.Link, error) {
+	query := selectLinks + "WHERE l.token = $1 GROUP BY l.id"
+
+	link, err := scanLink(r.QueryRowContext(ctx, query, token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Link{}, ErrLinkNotFound
+		}
+		err = fmt.Errorf("failed to get link: %w", err)
+		slog.Error(err.Error())
+		return models.Link{}, err
+	}
+
+	return link, nil
+}
+
+func (r *repo) ListLinksByOwner(ctx context.Context, ownerID string) ([]models.Link, error) {
+	query := selectLinks + "WHERE l.owner_id = $1 GROUP BY l.id ORDER BY l.created_at DESC"
+
+	rows, err := r.QueryContext(ctx, query, ownerID)
+	if err != nil {
+		err = fmt.Errorf("failed to query links: %w", err)
+		slog.Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := make([]models.Link, 0)
+	for rows.Next() {
+		link, err := scanLink(rows)
+		if err != nil {
+			err = fmt.Errorf("failed to scan link: %w", err)
+			slog.Error(err.Error())
+			return nil, err
+		}
+		links = append(links, link)
+	}
+
+	if err = rows.Err(); err != nil {
+		err = fmt.Errorf("failed to iterate links: %w", err)
+		slog.Error(err.Error())
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// IncrementLinkDownloads counts a download of the link, failing with
+// ErrLinkExhausted once its download limit is reached. Every request from the
+// start of a file counts. A ranged request resuming a download is free while
+// the bytes served stay within the size of the downloads counted so far.
+func (r *repo) IncrementLinkDownloads(ctx context.Context, token string, offset, length, size int64) error {
+	query := `
+		UPDATE links SET
+			downloads = downloads + CASE WHEN $2::BIGINT > 0 AND served_bytes + $3 <= downloads * $4 THEN 0 ELSE 1 END,
+			served_bytes = served_bytes + $3
+		WHERE token = $1 AND (
+			max_downloads = 0 OR downloads < max_downloads
+			OR ($2::BIGINT > 0 AND served_bytes + $3 <= downloads * $4)
+		)
+	`
+
+	res, err := r.ExecContext(ctx, query, token, offset, length, size)
+	if err != nil {
+		err = fmt.Errorf("failed to count link download: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return ErrLinkExhausted
+	}
+
+	return nil
+}
+
+// RecordLinkPasswordFailure counts a wrong password for the link and returns
+// the failures so far. Failures older than window are forgotten.
+func (r *repo) RecordLinkPasswordFailure(ctx context.Context, linkID string, window time.Duration) (int, error) {
+	query := `
+		UPDATE links SET
+			password_failures = CASE
+				WHEN last_failed_at < NOW() - make_interval(secs => $2) THEN 1
+				ELSE password_failures + 1
+			END,
+			last_failed_at = NOW()
+		WHERE id = $1
+		RETURNING password_failures
+	`
+
+	var failures int
+	if err := r.QueryRowContext(ctx, query, linkID, window.Seconds()).Scan(&failures); err != nil {
+		err = fmt.Errorf("failed to record link password failure: %w", err)
+		slog.Error(err.Error())
+		return 0, err
+	}
+
+	return failures, nil
+}
+
+func (r *repo) LockLink(ctx context.Context, linkID string, until time.Time) error {
+	if _, err := r.ExecContext(ctx, "UPDATE links SET locked_until = $1 WHERE id = $2", until, linkID); err != nil {
+		err = fmt.Errorf("failed to lock link: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// ReserveDropUpload claims path for an upload through a drop link. The link
+// row is locked so concurrent uploads can't exceed its file limit, and the
+// path is unique per owner so uploaders never overwrite each other. It
+// returns how many bytes are left, 0 meaning unlimited. Those bytes aren't
+// claimed yet, the upload claims them with GrowDropUpload as it goes.
+func (r *repo) ReserveDropUpload(ctx context.Context, linkID, ownerID, path string) (int64, error) {
+	tx, err := r.BeginTx(ctx, nil)
+	if err != nil {
+		err = fmt.Errorf("failed to begin transaction: %w", err)
+		slog.Error(err.Error())
+		return 0, err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var maxFiles, maxBytes int64
+	row := tx.QueryRowContext(ctx, "SELECT max_files, max_bytes FROM links WHERE id = $1 FOR UPDATE", linkID)
+	if err = row.Scan(&maxFiles, &maxBytes); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrLinkNotFound
+		}
+		err = fmt.Errorf("failed to lock link: %w", err)
+		slog.Error(err.Error())
+		return 0, err
+	}
+
+	var files, uploadedBytes int64
+	row = tx.QueryRowContext(ctx, "SELECT COUNT(*), COALESCE(SUM(size), 0) FROM drop_uploads WHERE link_id = $1", linkID)
+	if err = row.Scan(&files, &uploadedBytes); err != nil {
+		err = fmt.Errorf("failed to count drop uploads: %w", err)
+		slog.Error(err.Error())
+		return 0, err
+	}
+
+	if (maxFiles > 0 && files >= maxFiles) || (maxBytes > 0 && uploadedBytes >= maxBytes) {
+		return 0, ErrLinkExhausted
+	}
+
+	query := `
+		INSERT INTO drop_uploads (link_id, owner_id, path)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (owner_id, path) DO NOTHING
+	`
+
+	res, err := tx.ExecContext(ctx, query, linkID, ownerID, path)
+	if err != nil {
+		err = fmt.Errorf("failed to reserve drop upload: %w", err)
+		slog.Error(err.Error())
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return 0, ErrPathTaken
+	}
+
+	if err = tx.Commit(); err != nil {
+		err = fmt.Errorf("failed to commit drop upload: %w", err)
+		slog.Error(err.Error())
+		return 0, err
+	}
+
+	if maxBytes == 0 {
+		return 0, nil
+	}
+
+	return maxBytes - uploadedBytes, nil
+}
+
+// GrowDropUpload claims bytes more of the link's byte limit for an upload in
+// progress. The link row is locked, so concurrent uploads together never
+// claim more than the limit.
+func (r *repo) GrowDropUpload(ctx context.Context, linkID, path string, bytes int64) error {
+	tx, err := r.BeginTx(ctx, nil)
+	if err != nil {
+		err = fmt.Errorf("failed to begin transaction: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var maxBytes int64
+	row := tx.QueryRowContext(ctx, "SELECT max_bytes FROM links WHERE id = $1 FOR UPDATE", linkID)
+	if err = row.Scan(&maxBytes); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrLinkNotFound
+		}
+		err = fmt.Errorf("failed to lock link: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if maxBytes == 0 {
+		return nil
+	}
+
+	var claimedBytes int64
+	row = tx.QueryRowContext(ctx, "SELECT COALESCE(SUM(size), 0) FROM drop_uploads WHERE link_id = $1", linkID)
+	if err = row.Scan(&claimedBytes); err != nil {
+		err = fmt.Errorf("failed to count drop uploads: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if claimedBytes+bytes > maxBytes {
+		return ErrLinkExhausted
+	}
+
+	query := "UPDATE drop_uploads SET size = size + $3 WHERE link_id = $1 AND path = $2"
+	if _, err = tx.ExecContext(ctx, query, linkID, path, bytes); err != nil {
+		err = fmt.Errorf("failed to grow drop upload: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		err = fmt.Errorf("failed to commit drop upload: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (r *repo) FinishDropUpload(ctx context.Context, linkID, path string, size int64) error {
+	query := "UPDATE drop_uploads SET size = $3 WHERE link_id = $1 AND path = $2"
+
+	if _, err := r.ExecContext(ctx, query, linkID, path, size); err != nil {
+		err = fmt.Errorf("failed to finish drop upload: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (r *repo) DeleteDropUpload(ctx context.Context, linkID, path string) error {
+	query := "DELETE FROM drop_uploads WHERE link_id = $1 AND path = $2"
+
+	if _, err := r.ExecContext(ctx, query, linkID, path); err != nil {
+		err = fmt.Errorf("failed to delete drop upload: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (r *repo) DeleteUserLinks(ctx context.Context, ownerID string) error {
+	query := "DELETE FROM links WHERE owner_id = $1"
+
+	if _, err := r.ExecContext(ctx, query, ownerID); err != nil {
+		err = fmt.Errorf("failed to delete user links: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetRetentionPolicy returns an empty policy for users who never set one.
+func (r *repo) GetRetentionPolicy(ctx context.Context, userID string) (models.RetentionPolicy, error) {
+	query := `
+		SELECT user_id, keep_versions, keep_days, updated_at
+		FROM retention_policies WHERE user_id = $1
+	`
+
+	var policy models.RetentionPolicy
+	row := r.QueryRowContext(ctx, query, userID)
+	if err := row.Scan(&policy.UserID, &policy.KeepVersions, &policy.KeepDays, &policy.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.RetentionPolicy{UserID: userID}, nil
+		}
+		err = fmt.Errorf("failed to get retention policy: %w", err)
+		slog.Error(err.Error())
+		return models.RetentionPolicy{}, err
+	}
+
+	return policy, nil
+}
+
+func (r *repo) SetRetentionPolicy(ctx context.Context, policy models.RetentionPolicy) (models.RetentionPolicy, error) {
+	query := `
+		INSERT INTO retention_policies (user_id, keep_versions, keep_days)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id)
+		DO UPDATE SET keep_versions = EXCLUDED.keep_versions, keep_days = EXCLUDED.keep_days, updated_at = NOW()
+		RETURNING updated_at
+	`
+
+	row := r.QueryRowContext(ctx, query, policy.UserID, policy.KeepVersions, policy.KeepDays)
+	if err := row.Scan(&policy.UpdatedAt); err != nil {
+		err = fmt.Errorf("failed to set retention policy: %w", err)
+		slog.Error(err.Error())
+		return models.RetentionPolicy{}, err
+	}
+
+	return policy, nil
+}
+
+// ListRetentionPolicies returns the policies that limit anything.
+func (r *repo) ListRetentionPolicies(ctx context.Context) ([]models.RetentionPolicy, error) {
+	query := `
+		SELECT user_id, keep_versions, keep_days, updated_at
+		FROM retention_policies WHERE keep_versions > 0 OR keep_days > 0
+	`
+
+	rows, err := r.QueryContext(ctx, query)
+	if err != nil {
+		err = fmt.Errorf("failed to list retention policies: %w", err)
+		slog.Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	policies := make([]models.RetentionPolicy, 0)
+	for rows.Next() {
+		var policy models.RetentionPolicy
+		if err = rows.Scan(&policy.UserID, &policy.KeepVersions, &policy.KeepDays, &policy.UpdatedAt); err != nil {
+			err = fmt.Errorf("failed to scan retention policy: %w", err)
+			slog.Error(err.Error())
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+
+	if err = rows.Err(); err != nil {
+		err = fmt.Errorf("failed to list retention policies: %w", err)
+		slog.Error(err.Error())
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+func (r *repo) DeleteRetentionPolicy(ctx context.Context, userID string) error {
+	query := "DELETE FROM retention_policies WHERE user_id = $1"
+
+	if _, err := r.ExecContext(ctx, query, userID); err != nil {
+		err = fmt.Errorf("failed to delete retention policy: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (r *repo) CreateTrashItem(ctx context.Context, item models.TrashItem) (models.TrashItem, error) {
+	query := `
+		INSERT INTO trash_items (id, user_id, path, is_dir, size)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING deleted_at
+	`
+
+	row := r.QueryRowContext(ctx, query, item.ID, item.UserID, item.Path, item.IsDir, item.Size)
+	if err := row.Scan(&item.DeletedAt); err != nil {
+		err = fmt.Errorf("failed to create trash item: %w", err)
+		slog.Error(err.Error())
+		return models.TrashItem{}, err
+	}
+
+	return item, nil
+}
+
+func (r *repo) GetTrashItem(ctx context.Context, userID, itemID string) (models.TrashItem, error) {
+	query := `
+		SELECT id, user_id, path, is_dir, size, deleted_at
+		FROM trash_items WHERE id = $1 AND user_id = $2
+	`
+
+	item, err := scanTrashItem(r.QueryRowContext(ctx, query, itemID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.TrashItem{}, ErrTrashItemNotFound
+		}
+		err = fmt.Errorf("failed to get trash item: %w", err)
+		slog.Error(err.Error())
+		return models.TrashItem{}, err
+	}
+
+	return item, nil
+}
+
+func (r *repo) ListTrashItems(ctx context.Context, userID string) ([]models.TrashItem, error) {
+	query := `
+		SELECT id, user_id, path, is_dir, size, deleted_at
+		FROM trash_items WHERE user_id = $1
+		ORDER BY deleted_at DESC
+	`
+
+	return r.queryTrashItems(ctx, query, userID)
+}
+
+func (r *repo) ListTrashItemsDeletedBefore(ctx context.Context, before time.Time, limit int) ([]models.TrashItem, error) {
+	query := `
+		SELECT id, user_id, path, is_dir, size, deleted_at
+		FROM trash_items WHERE deleted_at < $1
+		ORDER BY deleted_at
+		LIMIT $2
+	`
+
+	return r.queryTrashItems(ctx, query, before, limit)
+}
+
+func (r *repo) DeleteTrashItem(ctx context.Context, userID, itemID string) error {
+	query := "DELETE FROM trash_items WHERE id = $1 AND user_id = $2"
+
+	res, err := r.ExecContext(ctx, query, itemID, userID)
+	if err != nil {
+		err = fmt.Errorf("failed to delete trash item: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return ErrTrashItemNotFound
+	}
+
+	return nil
+}
+
+func (r *repo) DeleteUserTrashItems(ctx context.Context, userID string) error {
+	query := "DELETE FROM trash_items WHERE user_id = $1"
+
+	if _, err := r.ExecContext(ctx, query, userID); err != nil {
+		err = fmt.Errorf("failed to delete user trash items: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (r *repo) queryTrashItems(ctx context.Context, query string, args ...any) ([]models.TrashItem, error) {
+	rows, err := r.QueryContext(ctx, query, args...)
+	if err != nil {
+		err = fmt.Errorf("failed to list trash items: %w", err)
+		slog.Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]models.TrashItem, 0)
+	for rows.Next() {
+		item, err := scanTrashItem(rows)
+		if err != nil {
+			err = fmt.Errorf("failed to scan trash item: %w", err)
+			slog.Error(err.Error())
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		err = fmt.Errorf("failed to iterate trash items: %w", err)
+		slog.Error(err.Error())
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *repo) GetQuota(ctx context.Context, userID string) (models.Quota, error) {
+	query := "SELECT user_id, limit_bytes, updated_at FROM quotas WHERE user_id = $1"
+
+	var quota models.Quota
+	row := r.QueryRowContext(ctx, query, userID)
+	if err := row.Scan(&quota.UserID, &quota.LimitBytes, &quota.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Quota{}, ErrQuotaNotFound
+		}
+		err = fmt.Errorf("failed to get quota: %w", err)
+		slog.Error(err.Error())
+		return models.Quota{}, err
+	}
+
+	return quota, nil
+}
+
+func (r *repo) SetQuota(ctx context.Context, quota models.Quota) (models.Quota, error) {
+	query := `
+		INSERT INTO quotas (user_id, limit_bytes)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id)
+		DO UPDATE SET limit_bytes = EXCLUDED.limit_bytes, updated_at = NOW()
+		RETURNING updated_at
+	`
+
+	row := r.QueryRowContext(ctx, query, quota.UserID, quota.LimitBytes)
+	if err := row.Scan(&quota.UpdatedAt); err != nil {
+		err = fmt.Errorf("failed to set quota: %w", err)
+		slog.Error(err.Error())
+		return models.Quota{}, err
+	}
+
+	return quota, nil
+}
+
+func (r *repo) DeleteQuota(ctx context.Context, userID string) error {
+	query := "DELETE FROM quotas WHERE user_id = $1"
+
+	if _, err := r.ExecContext(ctx, query, userID); err != nil {
+		err = fmt.Errorf("failed to delete quota: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// ReserveStorage adds bytes to the reservation, failing with ErrQuotaExceeded
+// if the stored and reserved bytes would go over limitBytes. The usage row is
+// locked, so concurrent writers can't exceed the quota together. Stale usage
+// is recounted with count under the lock first.
+func (r *repo) ReserveStorage(ctx context.Context, reservationID, userID string, limitBytes, bytes int64, count func(ctx context.Context) (int64, error)) error {
+	tx, err := r.BeginTx(ctx, nil)
+	if err != nil {
+		err = fmt.Errorf("failed to begin transaction: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err = tx.ExecContext(ctx, "INSERT INTO storage_usage (user_id) VALUES ($1) ON CONFLICT (user_id) DO NOTHING", userID); err != nil {
+		err = fmt.Errorf("failed to create storage usage: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	var (
+		usedBytes int64
+		stale     bool
+	)
+	row := tx.QueryRowContext(ctx, "SELECT used_bytes, stale FROM storage_usage WHERE user_id = $1 FOR UPDATE", userID)
+	if err = row.Scan(&usedBytes, &stale); err != nil {
+		err = fmt.Errorf("failed to lock storage usage: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if stale {
+		if usedBytes, err = count(ctx); err != nil {
+			return fmt.Errorf("failed to count storage usage: %w", err)
+		}
+
+		query := "UPDATE storage_usage SET used_bytes = $2, stale = FALSE WHERE user_id = $1"
+		if _, err = tx.ExecContext(ctx, query, userID, usedBytes); err != nil {
+			err = fmt.Errorf("failed to update storage usage: %w", err)
+			slog.Error(err.Error())
+			return err
+		}
+	}
+
+	var reservedBytes int64
+	row = tx.QueryRowContext(ctx, "SELECT COALESCE(SUM(bytes), 0) FROM quota_reservations WHERE user_id = $1", userID)
+	if err = row.Scan(&reservedBytes); err != nil {
+		err = fmt.Errorf("failed to count quota reservations: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if usedBytes+reservedBytes+bytes > limitBytes {
+		return ErrQuotaExceeded
+	}
+
+	query := `
+		INSERT INTO quota_reservations (id, user_id, bytes)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (id)
+		DO UPDATE SET bytes = quota_reservations.bytes + EXCLUDED.bytes, updated_at = NOW()
+	`
+	if _, err = tx.ExecContext(ctx, query, reservationID, userID, bytes); err != nil {
+		err = fmt.Errorf("failed to reserve storage: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		err = fmt.Errorf("failed to commit storage reservation: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// FinishStorageReservation drops the reservation and counts what was
+// actually stored instead.
+func (r *repo) FinishStorageReservation(ctx context.Context, reservationID, userID string, storedBytes int64) error {
+	tx, err := r.BeginTx(ctx, nil)
+	if err != nil {
+		err = fmt.Errorf("failed to begin transaction: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM quota_reservations WHERE id = $1", reservationID); err != nil {
+		err = fmt.Errorf("failed to delete quota reservation: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	query := "UPDATE storage_usage SET used_bytes = used_bytes + $2 WHERE user_id = $1"
+	if _, err = tx.ExecContext(ctx, query, userID, storedBytes); err != nil {
+		err = fmt.Errorf("failed to update storage usage: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		err = fmt.Errorf("failed to commit storage usage: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (r *repo) DeleteStorageReservation(ctx context.Context, reservationID string) error {
+	query := "DELETE FROM quota_reservations WHERE id = $1"
+
+	if _, err := r.ExecContext(ctx, query, reservationID); err != nil {
+		err = fmt.Errorf("failed to delete quota reservation: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// DeleteIdleStorageReservations drops reservations left behind by uploads
+// that died with the process. Upload sessions keep theirs until they expire.
+func (r *repo) DeleteIdleStorageReservations(ctx context.Context, before time.Time) error {
+	query := `
+		DELETE FROM quota_reservations
+		WHERE updated_at < $1 AND NOT EXISTS (SELECT 1 FROM upload_sessions WHERE upload_sessions.id = quota_reservations.id)
+	`
+
+	if _, err := r.ExecContext(ctx, query, before); err != nil {
+		err = fmt.Errorf("failed to delete idle quota reservations: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// MarkStorageUsageStale makes the next reservation recount the usage, for
+// changes that free space by an amount that isn't known up front.
+func (r *repo) MarkStorageUsageStale(ctx context.Context, userID string) error {
+	query := "UPDATE storage_usage SET stale = TRUE WHERE user_id = $1"
+
+	if _, err := r.ExecContext(ctx, query, userID); err != nil {
+		err = fmt.Errorf("failed to mark storage usage stale: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (r *repo) DeleteStorageUsage(ctx context.Context, userID string) error {
+	tx, err := r.BeginTx(ctx, nil)
+	if err != nil {
+		err = fmt.Errorf("failed to begin transaction: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM quota_reservations WHERE user_id = $1", userID); err != nil {
+		err = fmt.Errorf("failed to delete quota reservations: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM storage_usage WHERE user_id = $1", userID); err != nil {
+		err = fmt.Errorf("failed to delete storage usage: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		err = fmt.Errorf("failed to commit storage usage removal: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (r *repo) CreateUploadSession(ctx context.Context, session models.UploadSession) (models.UploadSession, error) {
+	query := `
+		INSERT INTO upload_sessions (id, user_id, path, upload_id, total_size, chunk_size, checksum, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at
+	`
+
+	row := r.QueryRowContext(ctx, query,
+		session.ID, session.UserID, session.Path, session.UploadID,
+		session.TotalSize, session.ChunkSize, session.Checksum, session.ExpiresAt,
+	)
+	if err := row.Scan(&session.CreatedAt); err != nil {
+		err = fmt.Errorf("failed to create upload session: %w", err)
+		slog.Error(err.Error())
+		return models.UploadSession{}, err
+	}
+
+	return session, nil
+}
+
+func (r *repo) GetUploadSession(ctx context.Context, userID, sessionID string) (models.UploadSession, error) {
+	query := `
+		SELECT id, user_id, path, upload_id, total_size, chunk_size, checksum, created_at, expires_at
+		FROM upload_sessions WHERE id = $1 AND user_id = $2
+	`
+
+	session, err := scanUploadSession(r.QueryRowContext(ctx, query, sessionID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.UploadSession{}, ErrUploadSessionNotFound
+		}
+		err = fmt.Errorf("failed to get upload session: %w", err)
+		slog.Error(err.Error())
+		return models.UploadSession{}, err
+	}
+
+	return session, nil
+}
+
+func (r *repo) ListUploadSessionsExpiredBefore(ctx context.Context, before time.Time, limit int) ([]models.UploadSession, error) {
+	query := `
+		SELECT id, user_id, path, upload_id, total_size, chunk_size, checksum, created_at, expires_at
+		FROM upload_sessions WHERE expires_at < $1
+		ORDER BY expires_at
+		LIMIT $2
+	`
+
+	rows, err := r.QueryContext(ctx, query, before, limit)
+	if err != nil {
+		err = fmt.Errorf("failed to list upload sessions: %w", err)
+		slog.Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]models.UploadSession, 0)
+	for rows.Next() {
+		session, err := scanUploadSession(rows)
+		if err != nil {
+			err = fmt.Errorf("failed to scan upload session: %w", err)
+			slog.Error(err.Error())
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err = rows.Err(); err != nil {
+		err = fmt.Errorf("failed to iterate upload sessions: %w", err)
+		slog.Error(err.Error())
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (r *repo) DeleteUploadSession(ctx context.Context, userID, sessionID string) error {
+	query := "DELETE FROM upload_sessions WHERE id = $1 AND user_id = $2"
+
+	res, err := r.ExecContext(ctx, query, sessionID, userID)
+	if err != nil {
+		err = fmt.Errorf("failed to delete upload session: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return ErrUploadSessionNotFound
+	}
+
+	return nil
+}
+
+func (r *repo) DeleteUserUploadSessions(ctx context.Context, userID string) error {
+	query := "DELETE FROM upload_sessions WHERE user_id = $1"
+
+	if _, err := r.ExecContext(ctx, query, userID); err != nil {
+		err = fmt.Errorf("failed to delete user upload sessions: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func scanUploadSession(row interface{ Scan(dest ...any) error }) (models.UploadSession, error) {
+	var session models.UploadSession
+	err := row.Scan(
+		&session.ID, &session.UserID, &session.Path, &session.UploadID, &session.TotalSize,
+		&session.ChunkSize, &session.Checksum, &session.CreatedAt, &session.ExpiresAt,
+	)
+
+	return session, err
+}
+
+func scanTrashItem(row interface{ Scan(dest ...any) error }) (models.TrashItem, error) {
+	var item models.TrashItem
+	err := row.Scan(&item.ID, &item.UserID, &item.Path, &item.IsDir, &item.Size, &item.DeletedAt)
+
+	return item, err
+}
+
+func scanLink(row interface{ Scan(dest ...any) error }) (models.Link, error) {
+	var link models.Link
+	err := row.Scan(
+		&link.ID, &link.Token, &link.Kind, &link.OwnerID, &link.Path, &link.PasswordHash, &link.ExpiresAt,
+		&link.MaxDownloads, &link.Downloads, &link.MaxFiles, &link.Files, &link.MaxBytes, &link.UploadedBytes, &link.LockedUntil, &link.CreatedAt,
+	)
+
+	return link, err
+}
+
+func New(conf *config.DB) Repo {
+	dsn := getDsn(*conf)
+	database, err := sql.Open("postgres", dsn)
+	if err != nil {
+		log.Fatal("can't connect to db:\n", err)
+	}
+
+	slog.Info("db connected")
+	return &repo{
+		DB: database,
+	}
+}
+
+func getDsn(conf config.DB) string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		conf.Host,
+		conf.Port,
+		conf.User,
+		conf.Password,
+		conf.Name,
+	)
+}
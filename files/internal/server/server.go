@@ -24,14 +24,134 @@ func (s FileServer) UploadFile(stream pb.FileService_UploadFileServer) error {
 	return s.FileServerController.UploadFile(stream)
 }
 
+func (s FileServer) CreateUploadSession(ctx context.Context, req *pb.CreateUploadSessionRequest) (*pb.CreateUploadSessionResponse, error) {
+	return s.FileServerController.CreateUploadSession(ctx, req)
+}
+
+func (s FileServer) GetUploadSession(ctx context.Context, req *pb.GetUploadSessionRequest) (*pb.GetUploadSessionResponse, error) {
+	return s.FileServerController.GetUploadSession(ctx, req)
+}
+
+func (s FileServer) UploadChunk(stream pb.FileService_UploadChunkServer) error {
+	return s.FileServerController.UploadChunk(stream)
+}
+
+func (s FileServer) AppendUpload(stream pb.FileService_AppendUploadServer) error {
+	return s.FileServerController.AppendUpload(stream)
+}
+
+func (s FileServer) CompleteUploadSession(ctx context.Context, req *pb.CompleteUploadSessionRequest) (*pb.CompleteUploadSessionResponse, error) {
+	return s.FileServerController.CompleteUploadSession(ctx, req)
+}
+
+func (s FileServer) AbortUploadSession(ctx context.Context, req *pb.AbortUploadSessionRequest) (*pb.AbortUploadSessionResponse, error) {
+	return s.FileServerController.AbortUploadSession(ctx, req)
+}
+
 func (s FileServer) RemoveFile(ctx context.Context, req *pb.RemoveFileRequest) (*pb.RemoveFileResponse, error) {
 	return s.FileServerController.RemoveFile(ctx, req)
 }
 
+func (s FileServer) CreateDirectory(ctx context.Context, req *pb.CreateDirectoryRequest) (*pb.CreateDirectoryResponse, error) {
+	return s.FileServerController.CreateDirectory(ctx, req)
+}
+
+func (s FileServer) RemoveDirectory(ctx context.Context, req *pb.RemoveDirectoryRequest) (*pb.RemoveDirectoryResponse, error) {
+	return s.FileServerController.RemoveDirectory(ctx, req)
+}
+
+func (s FileServer) MoveFile(ctx context.Context, req *pb.MoveFileRequest) (*pb.MoveFileResponse, error) {
+	return s.FileServerController.MoveFile(ctx, req)
+}
+
+func (s FileServer) CopyFile(ctx context.Context, req *pb.CopyFileRequest) (*pb.CopyFileResponse, error) {
+	return s.FileServerController.CopyFile(ctx, req)
+}
+
+func (s FileServer) ListVersions(ctx context.Context, req *pb.ListVersionsRequest) (*pb.ListVersionsResponse, error) {
+	return s.FileServerController.ListVersions(ctx, req)
+}
+
+func (s FileServer) RestoreVersion(ctx context.Context, req *pb.RestoreVersionRequest) (*pb.RestoreVersionResponse, error) {
+	return s.FileServerController.RestoreVersion(ctx, req)
+}
+
+func (s FileServer) DeleteVersion(ctx context.Context, req *pb.DeleteVersionRequest) (*pb.DeleteVersionResponse, error) {
+	return s.FileServerController.DeleteVersion(ctx, req)
+}
+
+func (s FileServer) GetRetentionPolicy(ctx context.Context, req *pb.GetRetentionPolicyRequest) (*pb.GetRetentionPolicyResponse, error) {
+	return s.FileServerController.GetRetentionPolicy(ctx, req)
+}
+
+func (s FileServer) SetRetentionPolicy(ctx context.Context, req *pb.SetRetentionPolicyRequest) (*pb.SetRetentionPolicyResponse, error) {
+	return s.FileServerController.SetRetentionPolicy(ctx, req)
+}
+
+func (s FileServer) ListTrash(ctx context.Context, req *pb.ListTrashRequest) (*pb.ListTrashResponse, error) {
+	return s.FileServerController.ListTrash(ctx, req)
+}
+
+func (s FileServer) RestoreTrash(ctx context.Context, req *pb.RestoreTrashRequest) (*pb.RestoreTrashResponse, error) {
+	return s.FileServerController.RestoreTrash(ctx, req)
+}
+
+func (s FileServer) EmptyTrash(ctx context.Context, req *pb.EmptyTrashRequest) (*pb.EmptyTrashResponse, error) {
+	return s.FileServerController.EmptyTrash(ctx, req)
+}
+
+func (s FileServer) GetUsage(ctx context.Context, req *pb.GetUsageRequest) (*pb.GetUsageResponse, error) {
+	return s.FileServerController.GetUsage(ctx, req)
+}
+
+func (s FileServer) GetQuota(ctx context.Context, req *pb.GetQuotaRequest) (*pb.GetQuotaResponse, error) {
+	return s.FileServerController.GetQuota(ctx, req)
+}
+
+func (s FileServer) SetQuota(ctx context.Context, req *pb.SetQuotaRequest) (*pb.SetQuotaResponse, error) {
+	return s.FileServerController.SetQuota(ctx, req)
+}
+
 func (s FileServer) RegisterUser(ctx context.Context, req *pb.RegisterUserRequest) (*pb.RegisterUserResponse, error) {
 	return s.FileServerController.RegisterUser(ctx, req)
 }
 
+func (s FileServer) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+	return s.FileServerController.DeleteUser(ctx, req)
+}
+
+func (s FileServer) CreateShare(ctx context.Context, req *pb.CreateShareRequest) (*pb.CreateShareResponse, error) {
+	return s.FileServerController.CreateShare(ctx, req)
+}
+
+func (s FileServer) RemoveShare(ctx context.Context, req *pb.RemoveShareRequest) (*pb.RemoveShareResponse, error) {
+	return s.FileServerController.RemoveShare(ctx, req)
+}
+
+func (s FileServer) ListShares(ctx context.Context, req *pb.ListSharesRequest) (*pb.ListSharesResponse, error) {
+	return s.FileServerController.ListShares(ctx, req)
+}
+
+func (s FileServer) ListIncomingShares(ctx context.Context, req *pb.ListIncomingSharesRequest) (*pb.ListIncomingSharesResponse, error) {
+	return s.FileServerController.ListIncomingShares(ctx, req)
+}
+
+func (s FileServer) CreateLink(ctx context.Context, req *pb.CreateLinkRequest) (*pb.CreateLinkResponse, error) {
+	return s.FileServerController.CreateLink(ctx, req)
+}
+
+func (s FileServer) RevokeLink(ctx context.Context, req *pb.RevokeLinkRequest) (*pb.RevokeLinkResponse, error) {
+	return s.FileServerController.RevokeLink(ctx, req)
+}
+
+func (s FileServer) ListLinks(ctx context.Context, req *pb.ListLinksRequest) (*pb.ListLinksResponse, error) {
+	return s.FileServerController.ListLinks(ctx, req)
+}
+
+func (s FileServer) ResolveLink(ctx context.Context, req *pb.ResolveLinkRequest) (*pb.ResolveLinkResponse, error) {
+	return s.FileServerController.ResolveLink(ctx, req)
+}
+
 func New(controller controller.FileServerController) FileServer {
 	return FileServer{
 		UnimplementedFileServiceServer: pb.UnimplementedFileServiceServer{},
@@ -2,4 +2,43 @@ package service
 
 import "errors"
 
-var ErrorBucketExists = errors.New("bucket already exists")
+var (
+	ErrorBucketExists           = errors.New("bucket already exists")
+	ErrEmptySharePath           = errors.New("share path is empty")
+	ErrSelfShare                = errors.New("can't share a file with yourself")
+	ErrUnsupportedPermission    = errors.New("unsupported share permission")
+	ErrAccessDenied             = errors.New("access denied")
+	ErrLinkExpired              = errors.New("link has expired")
+	ErrLinkExhausted            = errors.New("link limit reached")
+	ErrWrongLinkPassword        = errors.New("wrong link password")
+	ErrLinkLocked               = errors.New("too many wrong link passwords, try again later")
+	ErrInvalidLinkExpiry        = errors.New("link expiry must be in the future")
+	ErrInvalidLinkLimit         = errors.New("link limits can't be negative")
+	ErrUnsupportedLinkKind      = errors.New("unsupported link kind")
+	ErrEmptyFileName            = errors.New("file name is empty")
+	ErrNoFreeFileName           = errors.New("no free file name left")
+	ErrEmptyDirectoryPath       = errors.New("directory path is empty")
+	ErrPathIsFile               = errors.New("a file with this name already exists")
+	ErrDirectoryNotFound        = errors.New("directory not found")
+	ErrDirectoryNotEmpty        = errors.New("directory is not empty")
+	ErrSourceNotFound           = errors.New("source path not found")
+	ErrDestinationExists        = errors.New("destination already exists")
+	ErrSameSourceAndDestination = errors.New("source and destination are the same")
+	ErrTransferIntoItself       = errors.New("can't move or copy a folder into itself")
+	ErrFileNotFound             = errors.New("file not found")
+	ErrVersionNotFound          = errors.New("version not found")
+	ErrInvalidRetentionPolicy   = errors.New("retention limits can't be negative")
+	ErrUnsupportedConflictMode  = errors.New("unsupported conflict mode")
+	ErrInvalidQuota             = errors.New("quota can't be negative")
+	ErrQuotaExceeded            = errors.New("storage quota exceeded")
+	ErrInvalidUploadSize        = errors.New("upload size must be positive")
+	ErrUploadTooLarge           = errors.New("upload is too large")
+	ErrInvalidChecksum          = errors.New("checksum must be a hex encoded sha256")
+	ErrInvalidChunk             = errors.New("chunk number is out of range")
+	ErrChunkSizeMismatch        = errors.New("chunk size doesn't match the session")
+	ErrUploadIncomplete         = errors.New("not all chunks are uploaded")
+	ErrChecksumMismatch         = errors.New("checksum doesn't match the uploaded file")
+	ErrUploadSessionExpired     = errors.New("upload session has expired")
+	ErrOffsetMismatch           = errors.New("offset doesn't match the uploaded size")
+	ErrUploadSizeExceeded       = errors.New("upload is larger than its declared size")
+)
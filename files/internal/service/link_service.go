@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/avran02/fileshare/files/internal/models"
+	"github.com/avran02/fileshare/files/internal/repo"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	linkTokenSize       = 32
+	maxDropNameAttempts = 100
+
+	// wrong passwords older than this are forgotten
+	linkPasswordWindow       = time.Hour
+	freeLinkPasswordFailures = 10
+	linkLockoutBase          = 30 * time.Second
+	maxLinkLockout           = 15 * time.Minute
+)
+
+type LinkService interface {
+	CreateLink(ctx context.Context, link models.Link, password string) (models.Link, error)
+	RevokeLink(ctx context.Context, ownerID, linkID string) error
+	ListLinks(ctx context.Context, ownerID string) ([]models.Link, error)
+	ResolveLink(ctx context.Context, token, password, path string) (ownerID, resolvedPath string, err error)
+	RecordDownload(ctx context.Context, token string, offset, length, size int64) error
+
+	ResolveDropLink(ctx context.Context, token, password string) (models.Link, error)
+	ReserveDropUpload(ctx context.Context, link models.Link, fileName string, exists func(ctx context.Context, path string) (bool, error)) (filePath string, maxSize int64, err error)
+	GrowDropUpload(ctx context.Context, link models.Link, filePath string, bytes int64) error
+	FinishDropUpload(ctx context.Context, link models.Link, filePath string, size int64) error
+	CancelDropUpload(ctx context.Context, link models.Link, filePath string) error
+	RemoveUserLinks(ctx context.Context, ownerID string) error
+}
+
+type linkService struct {
+	repo repo.Repo
+}
+
+func (s *linkService) CreateLink(ctx context.Context, link models.Link, password string) (models.Link, error) {
+	link.Path = normalizeSharePath(link.Path)
+	if link.Path == "" {
+		return models.Link{}, ErrEmptySharePath
+	}
+
+	if link.Kind == "" {
+		link.Kind = models.LinkKindDownload
+	}
+
+	switch link.Kind {
+	case models.LinkKindDownload:
+		link.MaxFiles, link.MaxBytes = 0, 0
+	case models.LinkKindDrop:
+		link.MaxDownloads = 0
+		if !strings.HasSuffix(link.Path, "/") {
+			link.Path += "/"
+		}
+	default:
+		return models.Link{}, fmt.Errorf("%w: %s", ErrUnsupportedLinkKind, link.Kind)
+	}
+
+	if link.ExpiresAt != nil && !link.ExpiresAt.After(time.Now()) {
+		return models.Link{}, ErrInvalidLinkExpiry
+	}
+
+	if link.MaxDownloads < 0 || link.MaxFiles < 0 || link.MaxBytes < 0 {
+		return models.Link{}, ErrInvalidLinkLimit
+	}
+
+	token, err := generateLinkToken()
+	if err != nil {
+		return models.Link{}, fmt.Errorf("failed to generate link token: %w", err)
+	}
+
+	if password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return models.Link{}, fmt.Errorf("failed to hash link password: %w", err)
+		}
+		link.PasswordHash = string(hash)
+	}
+
+	link.ID = uuid.NewString()
+	link.Token = token
+
+	link, err = s.repo.CreateLink(ctx, link)
+	if err != nil {
+		return models.Link{}, fmt.Errorf("failed to create link: %w", err)
+	}
+
+	slog.Info("Created " + link.Kind + " link " + link.ID + " for " + link.Path + " of " + link.OwnerID)
+	return link, nil
+}
+
+func (s *linkService) RevokeLink(ctx context.Context, ownerID, linkID string) error {
+	if err := s.repo.DeleteLink(ctx, ownerID, linkID); err != nil {
+		return fmt.Errorf("failed to revoke link: %w", err)
+	}
+
+	return nil
+}
+
+func (s *linkService) ListLinks(ctx context.Context, ownerID string) ([]models.Link, error) {
+	links, err := s.repo.ListLinksByOwner(ctx, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list links: %w", err)
+	}
+
+	return links, nil
+}
+
+// ResolveLink validates a download link and maps a path requested through it
+// to the owner's bucket. An empty path resolves to the linked path itself.
+func (s *linkService) ResolveLink(ctx context.Context, token, password, path string) (ownerID, resolvedPath string, err error) {
+	link, err := s.openLink(ctx, token, password, models.LinkKindDownload)
+	if err != nil {
+		return "", "", err
+	}
+
+	if link.MaxDownloads > 0 && link.Downloads >= link.MaxDownloads {
+		return "", "", ErrLinkExhausted
+	}
+
+	path = normalizeSharePath(path)
+	if path == "" {
+		path = link.Path
+	}
+
+	if !shareCoversPath(link.Path, path) {
+		slog.Warn("link " + link.ID + " does not cover " + path)
+		return "", "", ErrAccessDenied
+	}
+
+	return link.OwnerID, path, nil
+}
+
+func (s *linkService) RecordDownload(ctx context.Context, token string, offset, length, size int64) error {
+	if err := s.repo.IncrementLinkDownloads(ctx, token, offset, length, size); err != nil {
+		return fmt.Errorf("failed to record link download: %w", err)
+	}
+
+	return nil
+}
+
+func (s *linkService) ResolveDropLink(ctx context.Context, token, password string) (models.Link, error) {
+	link, err := s.openLink(ctx, token, password, models.LinkKindDrop)
+	if err != nil {
+		return models.Link{}, err
+	}
+
+	if link.MaxFiles > 0 && link.Files >= link.MaxFiles {
+		return models.Link{}, ErrLinkExhausted
+	}
+
+	return link, nil
+}
+
+// ReserveDropUpload picks a free name for fileName in the link's folder,
+// appending a counter when it is taken, and claims it for the upload. It
+// returns the claimed path and the most bytes the upload may take, 0 meaning
+// unlimited.
+func (s *linkService) ReserveDropUpload(ctx context.Context, link models.Link, fileName string, exists func(ctx context.Context, path string) (bool, error)) (filePath string, maxSize int64, err error) {
+	fileName = path.Base("/" + strings.ReplaceAll(fileName, "\\", "/"))
+	if fileName == "/" || fileName == "." || fileName == ".." {
+		return "", 0, ErrEmptyFileName
+	}
+
+	for i := 0; i < maxDropNameAttempts; i++ {
+		filePath = link.Path + numberedFileName(fileName, i)
+
+		taken, err := exists(ctx, filePath)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to check drop upload path: %w", err)
+		}
+
+		if taken {
+			continue
+		}
+
+		maxSize, err = s.repo.ReserveDropUpload(ctx, link.ID, link.OwnerID, filePath)
+		if errors.Is(err, repo.ErrPathTaken) {
+			continue
+		}
+
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to reserve drop upload: %w", err)
+		}
+
+		return filePath, maxSize, nil
+	}
+
+	return "", 0, ErrNoFreeFileName
+}
+
+func (s *linkService) GrowDropUpload(ctx context.Context, link models.Link, filePath string, bytes int64) error {
+	if err := s.repo.GrowDropUpload(ctx, link.ID, filePath, bytes); err != nil {
+		return fmt.Errorf("failed to grow drop upload: %w", err)
+	}
+
+	return nil
+}
+
+func (s *linkService) FinishDropUpload(ctx context.Context, link models.Link, filePath string, size int64) error {
+	if err := s.repo.FinishDropUpload(ctx, link.ID, filePath, size); err != nil {
+		return fmt.Errorf("failed to finish drop upload: %w", err)
+	}
+
+	slog.Info("Received " + filePath + " through drop link " + link.ID)
+	return nil
+}
+
+func (s *linkService) CancelDropUpload(ctx context.Context, link models.Link, filePath string) error {
+	if err := s.repo.DeleteDropUpload(ctx, link.ID, filePath); err != nil {
+		return fmt.Errorf("failed to cancel drop upload: %w", err)
+	}
+
+	return nil
+}
+
+func (s *linkService) RemoveUserLinks(ctx context.Context, ownerID string) error {
+	if err := s.repo.DeleteUserLinks(ctx, ownerID); err != nil {
+		return fmt.Errorf("failed to remove user links: %w", err)
+	}
+
+	return nil
+}
+
+// openLink fetches a link of the given kind and checks its expiry and password.
+func (s *linkService) openLink(ctx context.Context, token, password, kind string) (models.Link, error) {
+	link, err := s.repo.GetLinkByToken(ctx, token)
+	if err != nil {
+		return models.Link{}, fmt.Errorf("failed to get link: %w", err)
+	}
+
+	if link.Kind != kind {
+		slog.Warn("link " + link.ID + " is not a " + kind + " link")
+		return models.Link{}, ErrAccessDenied
+	}
+
+	if link.ExpiresAt != nil && time.Now().After(*link.ExpiresAt) {
+		return models.Link{}, ErrLinkExpired
+	}
+
+	if link.PasswordHash != "" {
+		if link.LockedUntil != nil && time.Now().Before(*link.LockedUntil) {
+			slog.Warn("security: link " + link.ID + " is locked after wrong passwords")
+			return models.Link{}, ErrLinkLocked
+		}
+
+		if err = bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)); err != nil {
+			slog.Warn("wrong password for link " + link.ID)
+			s.recordPasswordFailure(ctx, link.ID)
+			return models.Link{}, ErrWrongLinkPassword
+		}
+	}
+
+	return link, nil
+}
+
+// recordPasswordFailure counts a wrong password and locks the link for
+// exponentially longer once its free attempts are used up, so its password
+// can't be guessed at full speed.
+func (s *linkService) recordPasswordFailure(ctx context.Context, linkID string) {
+	failures, err := s.repo.RecordLinkPasswordFailure(ctx, linkID, linkPasswordWindow)
+	if err != nil {
+		return
+	}
+
+	delay := linkLockout(failures)
+	if delay == 0 {
+		return
+	}
+
+	slog.Warn("security: locking link", "link", linkID, "failures", failures, "for", delay.String())
+	if err = s.repo.LockLink(ctx, linkID, time.Now().Add(delay)); err != nil {
+		slog.Error("failed to lock link " + linkID)
+	}
+}
+
+// linkLockout doubles the lockout with every failure past the free ones, up to maxLinkLockout.
+func linkLockout(failures int) time.Duration {
+	if failures <= freeLinkPasswordFailures {
+		return 0
+	}
+
+	delay := linkLockoutBase
+	for i := freeLinkPasswordFailures + 1; i < failures && delay < maxLinkLockout; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxLinkLockout)
+}
+
+// numberedFileName returns "name (n).ext" for n > 0 and the name itself otherwise.
+func numberedFileName(fileName string, n int) string {
+	if n == 0 {
+		return fileName
+	}
+
+	ext := path.Ext(fileName)
+	return strings.TrimSuffix(fileName, ext) + " (" + strconv.Itoa(n) + ")" + ext
+}
+
+func generateLinkToken() (string, error) {
+	buf := make([]byte, linkTokenSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func NewLinkService(repo repo.Repo) LinkService {
+	return &linkService{
+		repo: repo,
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/avran02/fileshare/files/internal/models"
+	"github.com/avran02/fileshare/files/internal/repo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// linkRepo holds a single link in memory.
+type linkRepo struct {
+	repo.Repo
+	link     models.Link
+	failures int
+}
+
+func (r *linkRepo) GetLinkByToken(_ context.Context, token string) (models.Link, error) {
+	if token != r.link.Token {
+		return models.Link{}, repo.ErrLinkNotFound
+	}
+	return r.link, nil
+}
+
+func (r *linkRepo) RecordLinkPasswordFailure(_ context.Context, _ string, _ time.Duration) (int, error) {
+	r.failures++
+	return r.failures, nil
+}
+
+func (r *linkRepo) LockLink(_ context.Context, _ string, until time.Time) error {
+	r.link.LockedUntil = &until
+	return nil
+}
+
+func TestOpenLinkLocksAfterWrongPasswords(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &linkRepo{link: models.Link{ID: "1", Token: "token", Kind: models.LinkKindDownload, PasswordHash: string(hash)}}
+	s := NewLinkService(r).(*linkService)
+	ctx := context.Background()
+
+	for i := 0; i < freeLinkPasswordFailures; i++ {
+		if _, err = s.openLink(ctx, "token", "guess", models.LinkKindDownload); !errors.Is(err, ErrWrongLinkPassword) {
+			t.Fatalf("attempt %d: got %v, want %v", i+1, err, ErrWrongLinkPassword)
+		}
+	}
+
+	if _, err = s.openLink(ctx, "token", "secret", models.LinkKindDownload); err != nil {
+		t.Fatalf("right password within the free attempts: %v", err)
+	}
+
+	if _, err = s.openLink(ctx, "token", "guess", models.LinkKindDownload); !errors.Is(err, ErrWrongLinkPassword) {
+		t.Fatalf("got %v, want %v", err, ErrWrongLinkPassword)
+	}
+
+	if r.link.LockedUntil == nil {
+		t.Fatal("link isn't locked after the free attempts")
+	}
+
+	if _, err = s.openLink(ctx, "token", "secret", models.LinkKindDownload); !errors.Is(err, ErrLinkLocked) {
+		t.Fatalf("right password while locked: got %v, want %v", err, ErrLinkLocked)
+	}
+}
+
+func TestLinkLockout(t *testing.T) {
+	if got := linkLockout(freeLinkPasswordFailures); got != 0 {
+		t.Errorf("linkLockout(%d) = %v, want no lockout", freeLinkPasswordFailures, got)
+	}
+	if got := linkLockout(freeLinkPasswordFailures + 1); got != linkLockoutBase {
+		t.Errorf("linkLockout(%d) = %v, want %v", freeLinkPasswordFailures+1, got, linkLockoutBase)
+	}
+	if got := linkLockout(freeLinkPasswordFailures + 3); got != 4*linkLockoutBase {
+		t.Errorf("linkLockout(%d) = %v, want %v", freeLinkPasswordFailures+3, got, 4*linkLockoutBase)
+	}
+	if got := linkLockout(1000); got != maxLinkLockout {
+		t.Errorf("linkLockout(1000) = %v, want %v", got, maxLinkLockout)
+	}
+}
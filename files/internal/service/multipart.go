@@ -0,0 +1,324 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/avran02/fileshare/files/internal/config"
+	"github.com/avran02/fileshare/files/internal/models"
+	"github.com/minio/minio-go/v7"
+)
+
+func (s *filesService) StartMultipartUpload(ctx context.Context, bucketName, filePath string) (string, error) {
+	if err := s.createBucketIfNotExists(ctx, bucketName); err != nil {
+		return "", err
+	}
+
+	uploadID, err := s.core().NewMultipartUpload(ctx, bucketName, filePath, minio.PutObjectOptions{})
+	if err != nil {
+		err = fmt.Errorf("failed to start multipart upload: %w", err)
+		slog.Error(err.Error())
+		return "", err
+	}
+
+	return uploadID, nil
+}
+
+// UploadChunk stores one chunk of the session, replacing it if it was
+// already uploaded.
+func (s *filesService) UploadChunk(ctx context.Context, session models.UploadSession, chunk int, reader io.Reader) (int64, error) {
+	if chunk < 1 || chunk > session.ChunkCount() {
+		return 0, ErrInvalidChunk
+	}
+
+	part, err := s.core().PutObjectPart(
+		ctx, session.UserID, session.Path, session.UploadID,
+		chunk, reader, session.ChunkLength(chunk), minio.PutObjectPartOptions{},
+	)
+	if err != nil {
+		err = fmt.Errorf("failed to upload chunk %d: %w", chunk, err)
+		slog.Error(err.Error())
+		return 0, err
+	}
+
+	return part.Size, nil
+}
+
+// UploadOffset returns how many bytes from the start of the file are stored:
+// the leading run of uploaded chunks and the staged part of the next one.
+func (s *filesService) UploadOffset(ctx context.Context, session models.UploadSession) (int64, error) {
+	_, offset, staged, err := s.uploadPosition(ctx, session)
+	if err != nil {
+		return 0, err
+	}
+
+	return offset + staged, nil
+}
+
+// AppendUpload stores the content of the reader starting at offset. Full
+// chunks are uploaded as parts, and whatever is left of the next chunk is
+// staged until the rest of it arrives, since MinIO doesn't accept parts
+// smaller than 5 MiB. What was read is kept even if the reader fails.
+func (s *filesService) AppendUpload(ctx context.Context, session models.UploadSession, offset int64, reader io.Reader) (int64, error) {
+	done, committed, staged, err := s.uploadPosition(ctx, session)
+	if err != nil {
+		return 0, err
+	}
+
+	if offset != committed+staged {
+		return 0, fmt.Errorf("%w: expected %d", ErrOffsetMismatch, committed+staged)
+	}
+
+	if staged > 0 {
+		object, err := s.minio.GetObject(ctx, config.UploadStagingBucket, session.ID, minio.GetObjectOptions{})
+		if err != nil {
+			err = fmt.Errorf("failed to get staged chunk: %w", err)
+			slog.Error(err.Error())
+			return 0, err
+		}
+		defer object.Close()
+
+		reader = io.MultiReader(object, reader)
+	}
+
+	buf := make([]byte, session.ChunkSize)
+	for chunk := done + 1; chunk <= session.ChunkCount(); chunk++ {
+		n, readErr := io.ReadFull(reader, buf[:session.ChunkLength(chunk)])
+		if readErr != nil {
+			if int64(n) > staged {
+				if err = s.stageChunk(ctx, session, buf[:n]); err != nil {
+					return committed + staged, err
+				}
+				staged = int64(n)
+			}
+
+			if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+				return committed + staged, nil
+			}
+			err = fmt.Errorf("failed to read chunk %d: %w", chunk, readErr)
+			slog.Error(err.Error())
+			return committed + staged, err
+		}
+
+		// the last chunk is only stored once the reader is known to end with
+		// it, so an oversized body doesn't leave a complete upload behind
+		if chunk == session.ChunkCount() {
+			if err = expectEOF(reader); err != nil {
+				slog.Error(err.Error())
+				return committed + staged, err
+			}
+		}
+
+		if _, err = s.core().PutObjectPart(
+			ctx, session.UserID, session.Path, session.UploadID,
+			chunk, bytes.NewReader(buf[:n]), int64(n), minio.PutObjectPartOptions{},
+		); err != nil {
+			err = fmt.Errorf("failed to upload chunk %d: %w", chunk, err)
+			slog.Error(err.Error())
+			return committed + staged, err
+		}
+		committed += int64(n)
+
+		if staged > 0 {
+			staged = 0
+			if err = s.unstageChunk(ctx, session); err != nil {
+				return committed, err
+			}
+		}
+	}
+
+	return committed, nil
+}
+
+// expectEOF makes sure nothing is left to read.
+func expectEOF(reader io.Reader) error {
+	n, err := reader.Read(make([]byte, 1))
+	switch {
+	case n > 0:
+		return ErrUploadSizeExceeded
+	case errors.Is(err, io.EOF):
+		return nil
+	case err == nil:
+		return expectEOF(reader)
+	default:
+		return fmt.Errorf("failed to read past the last chunk: %w", err)
+	}
+}
+
+// UploadedChunks returns the numbers of the chunks stored so far, in order.
+func (s *filesService) UploadedChunks(ctx context.Context, session models.UploadSession) ([]int, error) {
+	parts, err := s.listParts(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make([]int, len(parts))
+	for i, part := range parts {
+		chunks[i] = part.PartNumber
+	}
+
+	return chunks, nil
+}
+
+// CompleteMultipartUpload assembles the chunks into the file and checks it
+// against the session checksum, if there is one.
+func (s *filesService) CompleteMultipartUpload(ctx context.Context, session models.UploadSession) error {
+	parts, err := s.listParts(ctx, session)
+	if err != nil {
+		return err
+	}
+
+	if len(parts) != session.ChunkCount() {
+		return fmt.Errorf("%w: %d of %d", ErrUploadIncomplete, len(parts), session.ChunkCount())
+	}
+
+	completeParts := make([]minio.CompletePart, len(parts))
+	for i, part := range parts {
+		completeParts[i] = minio.CompletePart{
+			PartNumber: part.PartNumber,
+			ETag:       part.ETag,
+		}
+	}
+
+	info, err := s.core().CompleteMultipartUpload(ctx, session.UserID, session.Path, session.UploadID, completeParts, minio.PutObjectOptions{})
+	if err != nil {
+		err = fmt.Errorf("failed to complete multipart upload: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if session.Checksum == "" {
+		return nil
+	}
+
+	sum, err := s.objectChecksum(ctx, session.UserID, session.Path, info.VersionID)
+	if err != nil {
+		return err
+	}
+
+	if sum == session.Checksum {
+		return nil
+	}
+
+	if err = s.minio.RemoveObject(ctx, session.UserID, session.Path, minio.RemoveObjectOptions{VersionID: info.VersionID}); err != nil {
+		err = fmt.Errorf("failed to remove corrupted upload: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return ErrChecksumMismatch
+}
+
+func (s *filesService) AbortMultipartUpload(ctx context.Context, session models.UploadSession) error {
+	if err := s.core().AbortMultipartUpload(ctx, session.UserID, session.Path, session.UploadID); err != nil {
+		err = fmt.Errorf("failed to abort multipart upload: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return s.unstageChunk(ctx, session)
+}
+
+// uploadPosition returns how many chunks in a row from the first one are
+// uploaded, their total size and the size of the staged part of the next one.
+func (s *filesService) uploadPosition(ctx context.Context, session models.UploadSession) (done int, committed, staged int64, err error) {
+	parts, err := s.listParts(ctx, session)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	for _, part := range parts {
+		if part.PartNumber != done+1 {
+			break
+		}
+		done++
+		committed += part.Size
+	}
+
+	info, err := s.minio.StatObject(ctx, config.UploadStagingBucket, session.ID, minio.StatObjectOptions{})
+	if err != nil {
+		switch minio.ToErrorResponse(err).Code {
+		case "NoSuchKey", "NoSuchBucket":
+			return done, committed, 0, nil
+		}
+		err = fmt.Errorf("failed to stat staged chunk: %w", err)
+		slog.Error(err.Error())
+		return 0, 0, 0, err
+	}
+
+	return done, committed, info.Size, nil
+}
+
+func (s *filesService) stageChunk(ctx context.Context, session models.UploadSession, data []byte) error {
+	if err := s.makeBucketIfNotExists(ctx, config.UploadStagingBucket); err != nil {
+		return err
+	}
+
+	_, err := s.minio.PutObject(ctx, config.UploadStagingBucket, session.ID, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{})
+	if err != nil {
+		err = fmt.Errorf("failed to stage chunk: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *filesService) unstageChunk(ctx context.Context, session models.UploadSession) error {
+	err := s.minio.RemoveObject(ctx, config.UploadStagingBucket, session.ID, minio.RemoveObjectOptions{})
+	if err != nil && minio.ToErrorResponse(err).Code != "NoSuchBucket" {
+		err = fmt.Errorf("failed to remove staged chunk: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *filesService) listParts(ctx context.Context, session models.UploadSession) ([]minio.ObjectPart, error) {
+	parts := make([]minio.ObjectPart, 0)
+	marker := 0
+	for {
+		result, err := s.core().ListObjectParts(ctx, session.UserID, session.Path, session.UploadID, marker, 0)
+		if err != nil {
+			err = fmt.Errorf("failed to list uploaded chunks: %w", err)
+			slog.Error(err.Error())
+			return nil, err
+		}
+
+		parts = append(parts, result.ObjectParts...)
+		if !result.IsTruncated {
+			return parts, nil
+		}
+		marker = result.NextPartNumberMarker
+	}
+}
+
+func (s *filesService) objectChecksum(ctx context.Context, bucketName, filePath, versionID string) (string, error) {
+	object, err := s.minio.GetObject(ctx, bucketName, filePath, minio.GetObjectOptions{VersionID: versionID})
+	if err != nil {
+		err = fmt.Errorf("failed to get uploaded file: %w", err)
+		slog.Error(err.Error())
+		return "", err
+	}
+	defer object.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, object); err != nil {
+		err = fmt.Errorf("failed to read uploaded file: %w", err)
+		slog.Error(err.Error())
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (s *filesService) core() minio.Core {
+	return minio.Core{Client: s.minio}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/avran02/fileshare/files/internal/config"
+	"github.com/avran02/fileshare/files/internal/models"
+	"github.com/avran02/fileshare/files/internal/repo"
+)
+
+type QuotaService interface {
+	GetQuota(ctx context.Context, userID string) (models.Quota, error)
+	SetQuota(ctx context.Context, userID string, limitBytes int64) (models.Quota, error)
+	ResetQuota(ctx context.Context, userID string) (models.Quota, error)
+	RemoveUserQuota(ctx context.Context, userID string) error
+
+	Reserve(ctx context.Context, userID, reservationID string, bytes int64, count func(ctx context.Context) (int64, error)) error
+	FinishReservation(ctx context.Context, userID, reservationID string, storedBytes int64) error
+	CancelReservation(ctx context.Context, reservationID string) error
+	RemoveIdleReservations(ctx context.Context) error
+	InvalidateUsage(ctx context.Context, userID string) error
+}
+
+type quotaService struct {
+	repo repo.Repo
+	conf config.Quota
+}
+
+// GetQuota returns the admin override of the user's quota, or the configured
+// default if there is none.
+func (s *quotaService) GetQuota(ctx context.Context, userID string) (models.Quota, error) {
+	quota, err := s.repo.GetQuota(ctx, userID)
+	if errors.Is(err, repo.ErrQuotaNotFound) {
+		return s.defaultQuota(userID), nil
+	}
+	if err != nil {
+		return models.Quota{}, fmt.Errorf("failed to get quota: %w", err)
+	}
+
+	return quota, nil
+}
+
+func (s *quotaService) SetQuota(ctx context.Context, userID string, limitBytes int64) (models.Quota, error) {
+	if limitBytes < 0 {
+		return models.Quota{}, ErrInvalidQuota
+	}
+
+	quota, err := s.repo.SetQuota(ctx, models.Quota{
+		UserID:     userID,
+		LimitBytes: limitBytes,
+	})
+	if err != nil {
+		return models.Quota{}, fmt.Errorf("failed to set quota: %w", err)
+	}
+
+	return quota, nil
+}
+
+func (s *quotaService) ResetQuota(ctx context.Context, userID string) (models.Quota, error) {
+	if err := s.repo.DeleteQuota(ctx, userID); err != nil {
+		return models.Quota{}, fmt.Errorf("failed to reset quota: %w", err)
+	}
+
+	return s.defaultQuota(userID), nil
+}
+
+func (s *quotaService) RemoveUserQuota(ctx context.Context, userID string) error {
+	if err := s.repo.DeleteQuota(ctx, userID); err != nil {
+		return fmt.Errorf("failed to remove user quota: %w", err)
+	}
+
+	if err := s.repo.DeleteStorageUsage(ctx, userID); err != nil {
+		return fmt.Errorf("failed to remove user quota: %w", err)
+	}
+
+	return nil
+}
+
+// Reserve claims bytes of the user's quota for a write in progress, adding to
+// what reservationID holds already. count is only called when the stored
+// bytes have to be recounted. Users with unlimited quota reserve nothing.
+func (s *quotaService) Reserve(ctx context.Context, userID, reservationID string, bytes int64, count func(ctx context.Context) (int64, error)) error {
+	quota, err := s.GetQuota(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if quota.LimitBytes == 0 {
+		return nil
+	}
+
+	err = s.repo.ReserveStorage(ctx, reservationID, userID, quota.LimitBytes, bytes, count)
+	if errors.Is(err, repo.ErrQuotaExceeded) {
+		return ErrQuotaExceeded
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reserve quota: %w", err)
+	}
+
+	return nil
+}
+
+// FinishReservation releases the reservation and adds the bytes that were
+// actually stored to the user's usage.
+func (s *quotaService) FinishReservation(ctx context.Context, userID, reservationID string, storedBytes int64) error {
+	if err := s.repo.FinishStorageReservation(ctx, reservationID, userID, storedBytes); err != nil {
+		return fmt.Errorf("failed to finish quota reservation: %w", err)
+	}
+
+	return nil
+}
+
+func (s *quotaService) CancelReservation(ctx context.Context, reservationID string) error {
+	if err := s.repo.DeleteStorageReservation(ctx, reservationID); err != nil {
+		return fmt.Errorf("failed to cancel quota reservation: %w", err)
+	}
+
+	return nil
+}
+
+func (s *quotaService) RemoveIdleReservations(ctx context.Context) error {
+	if err := s.repo.DeleteIdleStorageReservations(ctx, time.Now().Add(-config.QuotaReservationMaxIdle)); err != nil {
+		return fmt.Errorf("failed to remove idle quota reservations: %w", err)
+	}
+
+	return nil
+}
+
+// InvalidateUsage makes the next reservation recount what the user stores,
+// for changes that free an amount of space that isn't known up front.
+func (s *quotaService) InvalidateUsage(ctx context.Context, userID string) error {
+	if err := s.repo.MarkStorageUsageStale(ctx, userID); err != nil {
+		return fmt.Errorf("failed to invalidate storage usage: %w", err)
+	}
+
+	return nil
+}
+
+func (s *quotaService) defaultQuota(userID string) models.Quota {
+	return models.Quota{
+		UserID:     userID,
+		LimitBytes: s.conf.DefaultBytes,
+		IsDefault:  true,
+	}
+}
+
+func NewQuotaService(repo repo.Repo, conf config.Quota) QuotaService {
+	return &quotaService{
+		repo: repo,
+		conf: conf,
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/avran02/fileshare/files/internal/config"
+	"github.com/avran02/fileshare/files/internal/models"
+	"github.com/avran02/fileshare/files/internal/repo"
+)
+
+// quotaRepo keeps quotas and reservations in memory. Only the methods the
+// quota service uses are implemented.
+type quotaRepo struct {
+	repo.Repo
+	quotas       map[string]int64
+	used         int64
+	reservations map[string]int64
+}
+
+func (r *quotaRepo) GetQuota(_ context.Context, userID string) (models.Quota, error) {
+	limit, ok := r.quotas[userID]
+	if !ok {
+		return models.Quota{}, repo.ErrQuotaNotFound
+	}
+	return models.Quota{UserID: userID, LimitBytes: limit}, nil
+}
+
+func (r *quotaRepo) ReserveStorage(_ context.Context, reservationID, _ string, limitBytes, bytes int64, _ func(ctx context.Context) (int64, error)) error {
+	reserved := int64(0)
+	for _, b := range r.reservations {
+		reserved += b
+	}
+	if r.used+reserved+bytes > limitBytes {
+		return repo.ErrQuotaExceeded
+	}
+	r.reservations[reservationID] += bytes
+	return nil
+}
+
+func (r *quotaRepo) FinishStorageReservation(_ context.Context, reservationID, _ string, storedBytes int64) error {
+	delete(r.reservations, reservationID)
+	r.used += storedBytes
+	return nil
+}
+
+func (r *quotaRepo) DeleteStorageReservation(_ context.Context, reservationID string) error {
+	delete(r.reservations, reservationID)
+	return nil
+}
+
+func TestQuotaReserve(t *testing.T) {
+	tests := []struct {
+		name         string
+		defaultBytes int64
+		override     int64 // -1 for none
+		used         int64
+		reserve      []int64
+		err          error
+	}{
+		{name: "unlimited default", defaultBytes: 0, override: -1, used: 1 << 40, reserve: []int64{1 << 40}},
+		{name: "within the default", defaultBytes: 100, override: -1, reserve: []int64{60, 40}},
+		{name: "over the default", defaultBytes: 100, override: -1, reserve: []int64{60, 41}, err: ErrQuotaExceeded},
+		{name: "override raises the limit", defaultBytes: 100, override: 200, reserve: []int64{150}},
+		{name: "override to unlimited", defaultBytes: 100, override: 0, used: 500, reserve: []int64{500}},
+		{name: "stored bytes count", defaultBytes: 100, override: -1, used: 90, reserve: []int64{11}, err: ErrQuotaExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &quotaRepo{quotas: map[string]int64{}, used: tt.used, reservations: map[string]int64{}}
+			if tt.override >= 0 {
+				r.quotas["user"] = tt.override
+			}
+			s := NewQuotaService(r, config.Quota{DefaultBytes: tt.defaultBytes})
+
+			var err error
+			for _, bytes := range tt.reserve {
+				if err = s.Reserve(context.Background(), "user", "upload", bytes, nil); err != nil {
+					break
+				}
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Reserve() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestQuotaFinishAndCancel(t *testing.T) {
+	ctx := context.Background()
+	r := &quotaRepo{quotas: map[string]int64{"user": 100}, reservations: map[string]int64{}}
+	s := NewQuotaService(r, config.Quota{})
+
+	// a finished upload keeps what it stored, not what it reserved
+	if err := s.Reserve(ctx, "user", "a", 80, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.FinishReservation(ctx, "user", "a", 30); err != nil {
+		t.Fatal(err)
+	}
+
+	// a cancelled one gives everything back
+	if err := s.Reserve(ctx, "user", "b", 70, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CancelReservation(ctx, "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Reserve(ctx, "user", "c", 70, nil); err != nil {
+		t.Errorf("70 bytes with 30 stored and a limit of 100: %v", err)
+	}
+	if err := s.Reserve(ctx, "user", "d", 1, nil); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("1 byte over the limit: error = %v, want %v", err, ErrQuotaExceeded)
+	}
+}
@@ -1,15 +1,19 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"log/slog"
+	"strings"
+	"sync"
 
 	"github.com/avran02/fileshare/files/internal/config"
 	"github.com/avran02/fileshare/files/internal/dto"
+	"github.com/avran02/fileshare/files/internal/models"
 	pb "github.com/avran02/fileshare/proto/filespb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
@@ -26,14 +30,38 @@ var (
 
 type FilesService interface {
 	RegisterUser(ctx context.Context, bucketName string) error
+	RemoveUser(ctx context.Context, bucketName string) error
 	ListFiles(ctx context.Context, bucketName, dir string) ([]*pb.FileInfo, error)
 	UploadFile(ctx context.Context, req *dto.UploadFileStreamRequest) error
-	DownloadFile(ctx context.Context, bucketName, filePath string) (io.ReadCloser, error)
+	DownloadFile(ctx context.Context, bucketName, filePath, versionID string, offset, length int64) (io.ReadCloser, models.FileMetadata, error)
 	RemoveFile(ctx context.Context, bucketName, filePath string) error
+	MoveToTrash(ctx context.Context, bucketName, filePath string) (item models.TrashItem, moved int64, failed []string, err error)
+	RestoreFromTrash(ctx context.Context, item models.TrashItem, dstPath, conflict string) (restoredPath string, restored int64, failed []string, err error)
+	PurgeTrash(ctx context.Context, item models.TrashItem) error
+	Usage(ctx context.Context, bucketName string) (models.Usage, error)
+	StartMultipartUpload(ctx context.Context, bucketName, filePath string) (uploadID string, err error)
+	UploadChunk(ctx context.Context, session models.UploadSession, chunk int, reader io.Reader) (size int64, err error)
+	UploadedChunks(ctx context.Context, session models.UploadSession) ([]int, error)
+	UploadOffset(ctx context.Context, session models.UploadSession) (int64, error)
+	AppendUpload(ctx context.Context, session models.UploadSession, offset int64, reader io.Reader) (newOffset int64, err error)
+	CompleteMultipartUpload(ctx context.Context, session models.UploadSession) error
+	AbortMultipartUpload(ctx context.Context, session models.UploadSession) error
+	CreateDirectory(ctx context.Context, bucketName, dirPath string) error
+	RemoveDirectory(ctx context.Context, bucketName, dirPath string, recursive bool) (removed int64, err error)
+	MoveFile(ctx context.Context, bucketName, srcPath, dstPath string, overwrite bool) (moved int64, failed []string, err error)
+	CopyFile(ctx context.Context, srcBucket, srcPath, dstBucket, dstPath string, overwrite bool, reserve func(size int64) error) (copied int64, failed []string, err error)
+	PathExists(ctx context.Context, bucketName, path string) (bool, error)
+
+	ListVersions(ctx context.Context, bucketName, filePath string) ([]models.Version, error)
+	RestoreVersion(ctx context.Context, bucketName, filePath, versionID string) (newVersionID string, err error)
+	DeleteVersion(ctx context.Context, bucketName, filePath, versionID string) error
+	PruneVersions(ctx context.Context, bucketName, filePath string, policy models.RetentionPolicy) (removed int64, err error)
+	PruneBucketVersions(ctx context.Context, bucketName string, policy models.RetentionPolicy) (removed int64, err error)
 }
 
 type filesService struct {
-	minio *minio.Client
+	minio     *minio.Client
+	versioned sync.Map
 }
 
 func (s *filesService) ListFiles(ctx context.Context, bucketName string, dir string) ([]*pb.FileInfo, error) {
@@ -56,10 +84,24 @@ func (s *filesService) ListFiles(ctx context.Context, bucketName string, dir str
 			return nil, fmt.Errorf("failed to list objects:\n%w", object.Err)
 		}
 
+		// the marker object of the listed directory itself
+		if object.Key == dir {
+			continue
+		}
+
+		if strings.HasSuffix(object.Key, "/") {
+			files = append(files, &pb.FileInfo{
+				Name: object.Key,
+				Type: models.EntryTypeDirectory,
+			})
+			continue
+		}
+
 		files = append(files, &pb.FileInfo{
 			Name:         object.Key,
 			Size:         object.Size,
 			LastModified: timestamppb.New(object.LastModified),
+			Type:         models.EntryTypeFile,
 		})
 	}
 
@@ -81,6 +123,49 @@ func (s *filesService) RegisterUser(ctx context.Context, bucketName string) erro
 	return nil
 }
 
+// RemoveUser deletes the user's bucket and trash along with everything stored in them.
+func (s *filesService) RemoveUser(ctx context.Context, bucketName string) error {
+	if err := s.removeBucket(ctx, trashBucketName(bucketName)); err != nil {
+		return err
+	}
+
+	return s.removeBucket(ctx, bucketName)
+}
+
+func (s *filesService) removeBucket(ctx context.Context, bucketName string) error {
+	exists, err := s.minio.BucketExists(ctx, bucketName)
+	if err != nil {
+		err = fmt.Errorf("failed to check if bucket exists: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if !exists {
+		return nil
+	}
+
+	objectsCh := s.minio.ListObjects(ctx, bucketName, minio.ListObjectsOptions{
+		Recursive:    true,
+		WithVersions: true,
+	})
+
+	for removeErr := range s.minio.RemoveObjects(ctx, bucketName, objectsCh, minio.RemoveObjectsOptions{}) {
+		err = fmt.Errorf("failed to remove object %s: %w", removeErr.ObjectName, removeErr.Err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if err = s.minio.RemoveBucket(ctx, bucketName); err != nil {
+		err = fmt.Errorf("failed to remove bucket: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	s.versioned.Delete(bucketName)
+	slog.Info("Removed bucket " + bucketName)
+	return nil
+}
+
 func (s *filesService) UploadFile(ctx context.Context, req *dto.UploadFileStreamRequest) error {
 	if err := s.createBucketIfNotExists(ctx, req.UserID); err != nil {
 		return err
@@ -101,19 +186,72 @@ func (s *filesService) UploadFile(ctx context.Context, req *dto.UploadFileStream
 	return nil
 }
 
-func (s *filesService) DownloadFile(ctx context.Context, bucketName, filePath string) (io.ReadCloser, error) {
+// DownloadFile returns length bytes of the file starting at offset, with
+// length 0 meaning up to the end and a negative offset counting from the end.
+// The range is clamped to the file, so it may come out empty.
+func (s *filesService) DownloadFile(ctx context.Context, bucketName, filePath, versionID string, offset, length int64) (io.ReadCloser, models.FileMetadata, error) {
 	if err := s.createBucketIfNotExists(ctx, bucketName); err != nil {
-		return nil, err
+		return nil, models.FileMetadata{}, err
+	}
+
+	opts := getObjectOptions
+	opts.VersionID = versionID
+
+	info, err := s.minio.StatObject(ctx, bucketName, filePath, opts)
+	if err != nil {
+		err = fmt.Errorf("failed to stat object: %w", err)
+		slog.Error(err.Error())
+		return nil, models.FileMetadata{}, err
+	}
+
+	start, end := clampRange(offset, length, info.Size)
+	metadata := models.FileMetadata{
+		Size:         info.Size,
+		ETag:         info.ETag,
+		LastModified: info.LastModified,
+		ContentType:  info.ContentType,
+		Offset:       start,
+		Length:       end - start,
+	}
+
+	if metadata.Length == 0 {
+		return io.NopCloser(bytes.NewReader(nil)), metadata, nil
+	}
+
+	// pin the version seen by the stat, so the metadata matches the content
+	if info.VersionID != "" {
+		opts.VersionID = info.VersionID
+	}
+	if metadata.Length < info.Size {
+		if err = opts.SetRange(start, end-1); err != nil {
+			return nil, models.FileMetadata{}, fmt.Errorf("failed to set range: %w", err)
+		}
 	}
 
-	o, err := s.minio.GetObject(ctx, bucketName, filePath, getObjectOptions)
+	o, err := s.minio.GetObject(ctx, bucketName, filePath, opts)
 	if err != nil {
 		err = fmt.Errorf("failed to get object: %w", err)
 		slog.Error(err.Error())
-		return nil, err
+		return nil, models.FileMetadata{}, err
+	}
+
+	return o, metadata, nil
+}
+
+// clampRange turns offset and length, as DownloadFile takes them, into the
+// bounds of the range within a file of the given size.
+func clampRange(offset, length, size int64) (start, end int64) {
+	start, end = offset, size
+	if start < 0 {
+		start = max(size+start, 0)
+	}
+	start = min(start, size)
+	// compared against what's left, as start+length overflows for huge lengths
+	if length > 0 && length < size-start {
+		end = start + length
 	}
 
-	return o, nil
+	return start, end
 }
 
 func (s *filesService) RemoveFile(ctx context.Context, bucketName, filePath string) error {
@@ -124,7 +262,135 @@ func (s *filesService) RemoveFile(ctx context.Context, bucketName, filePath stri
 	return s.minio.RemoveObject(ctx, bucketName, filePath, minio.RemoveObjectOptions{})
 }
 
+// CreateDirectory stores an empty marker object so the directory is listed
+// even before anything is uploaded into it.
+func (s *filesService) CreateDirectory(ctx context.Context, bucketName, dirPath string) error {
+	prefix, err := directoryPrefix(dirPath)
+	if err != nil {
+		return err
+	}
+
+	if err = s.createBucketIfNotExists(ctx, bucketName); err != nil {
+		return err
+	}
+
+	isFile, err := s.PathExists(ctx, bucketName, strings.TrimSuffix(prefix, "/"))
+	if err != nil {
+		return err
+	}
+
+	if isFile {
+		return ErrPathIsFile
+	}
+
+	_, err = s.minio.PutObject(ctx, bucketName, prefix, bytes.NewReader(nil), 0, minio.PutObjectOptions{})
+	if err != nil {
+		err = fmt.Errorf("failed to create directory: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	slog.Info("Created directory: " + prefix)
+	return nil
+}
+
+// RemoveDirectory deletes an empty directory, or with recursive set everything
+// stored under it.
+func (s *filesService) RemoveDirectory(ctx context.Context, bucketName, dirPath string, recursive bool) (int64, error) {
+	prefix, err := directoryPrefix(dirPath)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = s.createBucketIfNotExists(ctx, bucketName); err != nil {
+		return 0, err
+	}
+
+	keys := make([]string, 0, 1)
+	for object := range s.minio.ListObjects(ctx, bucketName, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	}) {
+		if object.Err != nil {
+			err = fmt.Errorf("failed to list objects: %w", object.Err)
+			slog.Error(err.Error())
+			return 0, err
+		}
+
+		if !recursive && object.Key != prefix {
+			return 0, ErrDirectoryNotEmpty
+		}
+
+		keys = append(keys, object.Key)
+	}
+
+	if len(keys) == 0 {
+		return 0, ErrDirectoryNotFound
+	}
+
+	objectsCh := make(chan minio.ObjectInfo, len(keys))
+	for _, key := range keys {
+		objectsCh <- minio.ObjectInfo{Key: key}
+	}
+	close(objectsCh)
+
+	for removeErr := range s.minio.RemoveObjects(ctx, bucketName, objectsCh, minio.RemoveObjectsOptions{}) {
+		err = fmt.Errorf("failed to remove object %s: %w", removeErr.ObjectName, removeErr.Err)
+		slog.Error(err.Error())
+		return 0, err
+	}
+
+	slog.Info(fmt.Sprintf("Removed directory %s with %d objects", prefix, len(keys)))
+	return int64(len(keys)), nil
+}
+
+func (s *filesService) PathExists(ctx context.Context, bucketName, path string) (bool, error) {
+	if err := s.createBucketIfNotExists(ctx, bucketName); err != nil {
+		return false, err
+	}
+
+	if !strings.HasSuffix(path, "/") {
+		_, err := s.minio.StatObject(ctx, bucketName, path, minio.StatObjectOptions{})
+		if err != nil {
+			if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+				return false, nil
+			}
+			err = fmt.Errorf("failed to stat object: %w", err)
+			slog.Error(err.Error())
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for object := range s.minio.ListObjects(ctx, bucketName, minio.ListObjectsOptions{
+		Prefix:  path,
+		MaxKeys: 1,
+	}) {
+		if object.Err != nil {
+			err := fmt.Errorf("failed to list objects: %w", object.Err)
+			slog.Error(err.Error())
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
 func (s *filesService) createBucketIfNotExists(ctx context.Context, bucketName string) error {
+	if err := s.makeBucketIfNotExists(ctx, bucketName); err != nil {
+		return err
+	}
+
+	return s.ensureVersioning(ctx, bucketName)
+}
+
+func (s *filesService) makeBucketIfNotExists(ctx context.Context, bucketName string) error {
 	exists, err := s.minio.BucketExists(ctx, bucketName)
 	if err != nil {
 		err = fmt.Errorf("failed to check if bucket exists: %w", err)
@@ -144,6 +410,15 @@ func (s *filesService) createBucketIfNotExists(ctx context.Context, bucketName s
 	return nil
 }
 
+func directoryPrefix(dirPath string) (string, error) {
+	dirPath = strings.Trim(dirPath, "/")
+	if dirPath == "" {
+		return "", ErrEmptyDirectoryPath
+	}
+
+	return dirPath + "/", nil
+}
+
 func New(conf config.Minio) FilesService {
 	minioClient, err := minio.New(conf.Endpoint, &minio.Options{
 		Creds:  credentials.NewStaticV4(conf.AccessKey, conf.SecretKey, ""),
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"math"
+	"testing"
+)
+
+func TestClampRange(t *testing.T) {
+	const size = 100
+
+	tests := []struct {
+		name           string
+		offset, length int64
+		start, end     int64
+	}{
+		{"whole file", 0, 0, 0, size},
+		{"closed range", 10, 20, 10, 30},
+		{"open-ended", 90, 0, 90, size},
+		{"suffix", -30, 0, 70, size},
+		{"suffix longer than the file", -500, 0, 0, size},
+		{"length past the end", 95, 10, 95, size},
+		{"start past the end", 150, 10, size, size},
+		{"huge length", 5, math.MaxInt64 - 4, 5, size},
+		{"max length", 0, math.MaxInt64, 0, size},
+	}
+
+	for _, tt := range tests {
+		start, end := clampRange(tt.offset, tt.length, size)
+		if start != tt.start || end != tt.end {
+			t.Errorf("%s: clampRange(%d, %d, %d) = [%d, %d), want [%d, %d)",
+				tt.name, tt.offset, tt.length, size, start, end, tt.start, tt.end)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/avran02/fileshare/files/internal/models"
+	"github.com/avran02/fileshare/files/internal/repo"
+	"github.com/google/uuid"
+)
+
+type ShareService interface {
+	CreateShare(ctx context.Context, ownerID, recipientID, path, permission string) (models.Share, error)
+	RemoveShare(ctx context.Context, ownerID, shareID string) error
+	ListShares(ctx context.Context, ownerID string) ([]models.Share, error)
+	ListIncomingShares(ctx context.Context, recipientID string) ([]models.Share, error)
+	CheckAccess(ctx context.Context, ownerID, recipientID, path string) error
+	ResolveShare(ctx context.Context, shareID, recipientID, path string) (ownerID, resolvedPath string, err error)
+	RemoveUserShares(ctx context.Context, userID string) error
+}
+
+type shareService struct {
+	repo repo.Repo
+}
+
+func (s *shareService) CreateShare(ctx context.Context, ownerID, recipientID, path, permission string) (models.Share, error) {
+	path = normalizeSharePath(path)
+	if path == "" {
+		return models.Share{}, ErrEmptySharePath
+	}
+
+	if ownerID == recipientID {
+		return models.Share{}, ErrSelfShare
+	}
+
+	if permission == "" {
+		permission = models.PermissionRead
+	}
+
+	if permission != models.PermissionRead {
+		return models.Share{}, fmt.Errorf("%w: %s", ErrUnsupportedPermission, permission)
+	}
+
+	share, err := s.repo.CreateShare(ctx, models.Share{
+		ID:          uuid.NewString(),
+		OwnerID:     ownerID,
+		RecipientID: recipientID,
+		Path:        path,
+		Permission:  permission,
+	})
+	if err != nil {
+		return models.Share{}, fmt.Errorf("failed to create share: %w", err)
+	}
+
+	slog.Info("Shared " + path + " of " + ownerID + " with " + recipientID)
+	return share, nil
+}
+
+func (s *shareService) RemoveShare(ctx context.Context, ownerID, shareID string) error {
+	if err := s.repo.DeleteShare(ctx, ownerID, shareID); err != nil {
+		return fmt.Errorf("failed to remove share: %w", err)
+	}
+
+	return nil
+}
+
+func (s *shareService) ListShares(ctx context.Context, ownerID string) ([]models.Share, error) {
+	shares, err := s.repo.ListSharesByOwner(ctx, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shares: %w", err)
+	}
+
+	return shares, nil
+}
+
+func (s *shareService) ListIncomingShares(ctx context.Context, recipientID string) ([]models.Share, error) {
+	shares, err := s.repo.ListSharesByRecipient(ctx, recipientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list incoming shares: %w", err)
+	}
+
+	return shares, nil
+}
+
+// CheckAccess returns ErrAccessDenied unless the owner has shared the path,
+// or a folder containing it, with the recipient.
+func (s *shareService) CheckAccess(ctx context.Context, ownerID, recipientID, path string) error {
+	shares, err := s.repo.ListSharesByOwnerAndRecipient(ctx, ownerID, recipientID)
+	if err != nil {
+		return fmt.Errorf("failed to check access: %w", err)
+	}
+
+	path = normalizeSharePath(path)
+	for _, share := range shares {
+		if shareCoversPath(share.Path, path) {
+			return nil
+		}
+	}
+
+	slog.Warn("user " + recipientID + " has no access to " + path + " of " + ownerID)
+	return ErrAccessDenied
+}
+
+// ResolveShare maps a path requested through a share to the owner's bucket.
+// An empty path resolves to the shared path itself.
+func (s *shareService) ResolveShare(ctx context.Context, shareID, recipientID, path string) (ownerID, resolvedPath string, err error) {
+	share, err := s.repo.GetShare(ctx, shareID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get share: %w", err)
+	}
+
+	if share.RecipientID != recipientID {
+		slog.Warn("user " + recipientID + " tried to use share " + shareID + " of another user")
+		return "", "", ErrAccessDenied
+	}
+
+	path = normalizeSharePath(path)
+	if path == "" {
+		path = share.Path
+	}
+
+	if !shareCoversPath(share.Path, path) {
+		slog.Warn("share " + shareID + " does not cover " + path)
+		return "", "", ErrAccessDenied
+	}
+
+	return share.OwnerID, path, nil
+}
+
+// RemoveUserShares drops every share the user is either owner or recipient of.
+func (s *shareService) RemoveUserShares(ctx context.Context, userID string) error {
+	if err := s.repo.DeleteUserShares(ctx, userID); err != nil {
+		return fmt.Errorf("failed to remove user shares: %w", err)
+	}
+
+	return nil
+}
+
+func normalizeSharePath(path string) string {
+	return strings.TrimPrefix(path, "/")
+}
+
+// shareCoversPath reports whether sharedPath grants access to path. Folder
+// shares end with a slash and cover everything under them.
+func shareCoversPath(sharedPath, path string) bool {
+	if sharedPath == path {
+		return true
+	}
+
+	return strings.HasSuffix(sharedPath, "/") && strings.HasPrefix(path, sharedPath)
+}
+
+func NewShareService(repo repo.Repo) ShareService {
+	return &shareService{
+		repo: repo,
+	}
+}
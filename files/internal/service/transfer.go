@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// maxCopyObjectSize is the largest object S3 copies in a single request.
+const maxCopyObjectSize = 5 * 1024 * 1024 * 1024
+
+type objectTransfer struct {
+	src     string
+	version string
+	dst     string
+	size    int64
+}
+
+// MoveFile moves a file, or a folder with everything under it, by copying each
+// object along with its old versions to its new key and removing the old key.
+// The copied versions get new IDs and dates. Objects that fail to move are
+// reported and stay at their old key, so running the same move again picks up
+// where it stopped.
+func (s *filesService) MoveFile(ctx context.Context, bucketName, srcPath, dstPath string, overwrite bool) (int64, []string, error) {
+	if err := s.createBucketIfNotExists(ctx, bucketName); err != nil {
+		return 0, nil, err
+	}
+
+	transfers, err := s.planTransfer(ctx, bucketName, srcPath, bucketName, dstPath, overwrite)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var (
+		moved  int64
+		failed []string
+	)
+	for _, t := range transfers {
+		if err = s.copyHistory(ctx, bucketName, bucketName, t); err != nil {
+			failed = append(failed, t.src)
+			continue
+		}
+
+		if err = s.removeAllVersions(ctx, bucketName, t.src); err != nil {
+			slog.Error(fmt.Sprintf("failed to remove moved object %s: %s", t.src, err))
+			failed = append(failed, t.src)
+			continue
+		}
+
+		moved++
+	}
+
+	slog.Info(fmt.Sprintf("Moved %d of %d objects from %s to %s", moved, len(transfers), srcPath, dstPath))
+	return moved, failed, nil
+}
+
+// CopyFile copies a file, or a folder with everything under it, from srcBucket
+// into dstBucket without the data leaving the storage. reserve is given the
+// size of everything to be copied before anything is, and can refuse the copy.
+func (s *filesService) CopyFile(ctx context.Context, srcBucket, srcPath, dstBucket, dstPath string, overwrite bool, reserve func(size int64) error) (int64, []string, error) {
+	if err := s.createBucketIfNotExists(ctx, dstBucket); err != nil {
+		return 0, nil, err
+	}
+
+	transfers, err := s.planTransfer(ctx, srcBucket, srcPath, dstBucket, dstPath, overwrite)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var size int64
+	for _, t := range transfers {
+		size += t.size
+	}
+
+	if err = reserve(size); err != nil {
+		return 0, nil, err
+	}
+
+	var (
+		copied int64
+		failed []string
+	)
+	for _, t := range transfers {
+		if _, err = s.copyObject(ctx, srcBucket, dstBucket, t); err != nil {
+			failed = append(failed, t.src)
+			continue
+		}
+
+		copied++
+	}
+
+	slog.Info(fmt.Sprintf("Copied %d of %d objects from %s/%s to %s/%s", copied, len(transfers), srcBucket, srcPath, dstBucket, dstPath))
+	return copied, failed, nil
+}
+
+// planTransfer lists the objects srcPath covers in srcBucket along with the
+// keys they get under dstPath in dstBucket.
+func (s *filesService) planTransfer(ctx context.Context, srcBucket, srcPath, dstBucket, dstPath string, overwrite bool) ([]objectTransfer, error) {
+	if strings.Trim(srcPath, "/") == "" {
+		return nil, ErrSourceNotFound
+	}
+
+	var srcSize int64
+	isDir := strings.HasSuffix(srcPath, "/")
+	if !isDir {
+		info, err := s.minio.StatObject(ctx, srcBucket, srcPath, minio.StatObjectOptions{})
+		switch {
+		case err == nil:
+			srcSize = info.Size
+		case minio.ToErrorResponse(err).Code == "NoSuchKey":
+			isDir = true
+		default:
+			err = fmt.Errorf("failed to stat object: %w", err)
+			slog.Error(err.Error())
+			return nil, err
+		}
+	}
+
+	if !isDir {
+		if dstPath == "" || strings.HasSuffix(dstPath, "/") {
+			dstPath += path.Base(srcPath)
+		}
+
+		if srcBucket == dstBucket && srcPath == dstPath {
+			return nil, ErrSameSourceAndDestination
+		}
+
+		if !overwrite {
+			exists, err := s.PathExists(ctx, dstBucket, dstPath)
+			if err != nil {
+				return nil, err
+			}
+
+			if exists {
+				return nil, fmt.Errorf("%w: %s", ErrDestinationExists, dstPath)
+			}
+		}
+
+		return []objectTransfer{{src: srcPath, dst: dstPath, size: srcSize}}, nil
+	}
+
+	srcPrefix, err := directoryPrefix(srcPath)
+	if err != nil {
+		return nil, err
+	}
+
+	dstPrefix, err := directoryPrefix(dstPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if srcBucket == dstBucket && strings.HasPrefix(dstPrefix, srcPrefix) {
+		return nil, ErrTransferIntoItself
+	}
+
+	var taken map[string]int64
+	if !overwrite {
+		taken, err = s.listKeys(ctx, dstBucket, dstPrefix)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	srcKeys, err := s.listKeys(ctx, srcBucket, srcPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(srcKeys) == 0 {
+		return nil, ErrSourceNotFound
+	}
+
+	transfers := make([]objectTransfer, 0, len(srcKeys))
+	for key, size := range srcKeys {
+		dstKey := dstPrefix + strings.TrimPrefix(key, srcPrefix)
+		if _, ok := taken[dstKey]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDestinationExists, dstKey)
+		}
+
+		transfers = append(transfers, objectTransfer{src: key, dst: dstKey, size: size})
+	}
+
+	return transfers, nil
+}
+
+// listKeys returns the sizes of all objects under prefix by their keys.
+func (s *filesService) listKeys(ctx context.Context, bucketName, prefix string) (map[string]int64, error) {
+	keys := make(map[string]int64)
+	for object := range s.minio.ListObjects(ctx, bucketName, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	}) {
+		if object.Err != nil {
+			err := fmt.Errorf("failed to list objects: %w", object.Err)
+			slog.Error(err.Error())
+			return nil, err
+		}
+
+		keys[object.Key] = object.Size
+	}
+
+	return keys, nil
+}
+
+// copyHistory copies every version of an object, oldest first, so the current
+// version stays the current one at the destination.
+func (s *filesService) copyHistory(ctx context.Context, srcBucket, dstBucket string, t objectTransfer) error {
+	versions, err := s.keyVersions(ctx, srcBucket, t.src)
+	if err != nil {
+		slog.Error(err.Error())
+		return err
+	}
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].IsDeleteMarker {
+			continue
+		}
+
+		if _, err = s.copyObject(ctx, srcBucket, dstBucket, objectTransfer{
+			src:     t.src,
+			version: versions[i].VersionID,
+			dst:     t.dst,
+			size:    versions[i].Size,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyObject copies an object inside the storage. Objects too big for a single
+// copy request are copied part by part.
+func (s *filesService) copyObject(ctx context.Context, srcBucket, dstBucket string, t objectTransfer) (minio.UploadInfo, error) {
+	dst := minio.CopyDestOptions{
+		Bucket: dstBucket,
+		Object: t.dst,
+	}
+	src := minio.CopySrcOptions{
+		Bucket:    srcBucket,
+		Object:    t.src,
+		VersionID: t.version,
+	}
+
+	var (
+		info minio.UploadInfo
+		err  error
+	)
+	if t.size > maxCopyObjectSize {
+		info, err = s.minio.ComposeObject(ctx, dst, src)
+	} else {
+		info, err = s.minio.CopyObject(ctx, dst, src)
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to copy object %s to %s: %w", t.src, t.dst, err)
+		slog.Error(err.Error())
+		return minio.UploadInfo{}, err
+	}
+
+	return info, nil
+}
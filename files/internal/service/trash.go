@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"github.com/avran02/fileshare/files/internal/models"
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	trashBucketSuffix      = "-trash"
+	maxRestoreNameAttempts = 100
+)
+
+// MoveToTrash moves a file or a folder into the user's trash bucket, old
+// versions included, so a restore brings the history back as well. The
+// returned item isn't stored yet, objects that failed to move stay in place.
+func (s *filesService) MoveToTrash(ctx context.Context, bucketName, filePath string) (models.TrashItem, int64, []string, error) {
+	if err := s.createBucketIfNotExists(ctx, bucketName); err != nil {
+		return models.TrashItem{}, 0, nil, err
+	}
+
+	trashBucket := trashBucketName(bucketName)
+	if err := s.createBucketIfNotExists(ctx, trashBucket); err != nil {
+		return models.TrashItem{}, 0, nil, err
+	}
+
+	item := models.TrashItem{
+		ID:     uuid.NewString(),
+		UserID: bucketName,
+		Path:   strings.TrimPrefix(filePath, "/"),
+	}
+
+	transfers, err := s.planTransfer(ctx, bucketName, item.Path, trashBucket, item.ID+"/"+strings.Trim(item.Path, "/"), true)
+	if err != nil {
+		return models.TrashItem{}, 0, nil, err
+	}
+
+	item.IsDir = strings.HasSuffix(item.Path, "/") || len(transfers) != 1 || transfers[0].src != item.Path
+	if item.IsDir && !strings.HasSuffix(item.Path, "/") {
+		item.Path += "/"
+	}
+
+	var (
+		moved  int64
+		failed []string
+	)
+	for _, t := range transfers {
+		if err = s.copyHistory(ctx, bucketName, trashBucket, t); err != nil {
+			failed = append(failed, t.src)
+			continue
+		}
+
+		// a plain removal would only hide the object behind a delete marker
+		if err = s.removeAllVersions(ctx, bucketName, t.src); err != nil {
+			slog.Error(fmt.Sprintf("failed to remove trashed object %s: %s", t.src, err))
+			failed = append(failed, t.src)
+			continue
+		}
+
+		item.Size += t.size
+		moved++
+	}
+
+	slog.Info(fmt.Sprintf("Moved %s to trash as %s", item.Path, item.ID))
+	return item, moved, failed, nil
+}
+
+// RestoreFromTrash moves a trashed item back to dstPath, or to where it was
+// deleted from if dstPath is empty. With the rename conflict mode a taken
+// destination gets a numbered name instead.
+func (s *filesService) RestoreFromTrash(ctx context.Context, item models.TrashItem, dstPath, conflict string) (string, int64, []string, error) {
+	if dstPath == "" {
+		dstPath = item.Path
+	}
+
+	trashBucket := trashBucketName(item.UserID)
+	srcPath := item.ID + "/" + strings.TrimPrefix(item.Path, "/")
+
+	var (
+		transfers []objectTransfer
+		err       error
+	)
+	switch conflict {
+	case "", models.RestoreConflictFail, models.RestoreConflictOverwrite:
+		transfers, err = s.planTransfer(ctx, trashBucket, srcPath, item.UserID, dstPath, conflict == models.RestoreConflictOverwrite)
+	case models.RestoreConflictRename:
+		dstPath, transfers, err = s.planRenamedTransfer(ctx, trashBucket, srcPath, item.UserID, dstPath)
+	default:
+		return "", 0, nil, fmt.Errorf("%w: %s", ErrUnsupportedConflictMode, conflict)
+	}
+	if err != nil {
+		return "", 0, nil, err
+	}
+
+	var (
+		restored int64
+		failed   []string
+	)
+	for _, t := range transfers {
+		if err = s.copyHistory(ctx, trashBucket, item.UserID, t); err != nil {
+			failed = append(failed, strings.TrimPrefix(t.src, item.ID+"/"))
+			continue
+		}
+
+		if err = s.removeAllVersions(ctx, trashBucket, t.src); err != nil {
+			slog.Error(fmt.Sprintf("failed to remove restored object %s: %s", t.src, err))
+		}
+
+		restored++
+	}
+
+	slog.Info(fmt.Sprintf("Restored %d objects of trash item %s to %s", restored, item.ID, dstPath))
+	return dstPath, restored, failed, nil
+}
+
+// PurgeTrash permanently deletes what is left of a trashed item.
+func (s *filesService) PurgeTrash(ctx context.Context, item models.TrashItem) error {
+	trashBucket := trashBucketName(item.UserID)
+	exists, err := s.minio.BucketExists(ctx, trashBucket)
+	if err != nil {
+		err = fmt.Errorf("failed to check if bucket exists: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if !exists {
+		return nil
+	}
+
+	objectsCh := s.minio.ListObjects(ctx, trashBucket, minio.ListObjectsOptions{
+		Prefix:       item.ID + "/",
+		Recursive:    true,
+		WithVersions: true,
+	})
+
+	for removeErr := range s.minio.RemoveObjects(ctx, trashBucket, objectsCh, minio.RemoveObjectsOptions{}) {
+		err = fmt.Errorf("failed to remove object %s: %w", removeErr.ObjectName, removeErr.Err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *filesService) planRenamedTransfer(ctx context.Context, srcBucket, srcPath, dstBucket, dstPath string) (string, []objectTransfer, error) {
+	dir, name := path.Split(strings.TrimSuffix(dstPath, "/"))
+	suffix := ""
+	if strings.HasSuffix(dstPath, "/") {
+		suffix = "/"
+	}
+
+	for i := 0; i < maxRestoreNameAttempts; i++ {
+		candidate := dir + numberedFileName(name, i) + suffix
+
+		transfers, err := s.planTransfer(ctx, srcBucket, srcPath, dstBucket, candidate, false)
+		if errors.Is(err, ErrDestinationExists) {
+			continue
+		}
+		if err != nil {
+			return "", nil, err
+		}
+
+		return candidate, transfers, nil
+	}
+
+	return "", nil, ErrNoFreeFileName
+}
+
+func trashBucketName(bucketName string) string {
+	return bucketName + trashBucketSuffix
+}
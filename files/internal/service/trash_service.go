@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/avran02/fileshare/files/internal/config"
+	"github.com/avran02/fileshare/files/internal/models"
+	"github.com/avran02/fileshare/files/internal/repo"
+)
+
+const purgeBatchSize = 100
+
+// TrashService keeps track of what was moved to the trash and from where. The
+// objects themselves are moved by FilesService.
+type TrashService interface {
+	AddItem(ctx context.Context, item models.TrashItem) (models.TrashItem, error)
+	GetItem(ctx context.Context, userID, itemID string) (models.TrashItem, error)
+	ListItems(ctx context.Context, userID string) ([]models.TrashItem, error)
+	RemoveItem(ctx context.Context, userID, itemID string) error
+	ListExpiredItems(ctx context.Context) ([]models.TrashItem, error)
+	RemoveUserTrash(ctx context.Context, userID string) error
+}
+
+type trashService struct {
+	repo repo.Repo
+	conf config.Trash
+}
+
+func (s *trashService) AddItem(ctx context.Context, item models.TrashItem) (models.TrashItem, error) {
+	item, err := s.repo.CreateTrashItem(ctx, item)
+	if err != nil {
+		return models.TrashItem{}, fmt.Errorf("failed to add trash item: %w", err)
+	}
+
+	return s.withExpiry(item), nil
+}
+
+func (s *trashService) GetItem(ctx context.Context, userID, itemID string) (models.TrashItem, error) {
+	item, err := s.repo.GetTrashItem(ctx, userID, itemID)
+	if err != nil {
+		return models.TrashItem{}, fmt.Errorf("failed to get trash item: %w", err)
+	}
+
+	return s.withExpiry(item), nil
+}
+
+func (s *trashService) ListItems(ctx context.Context, userID string) ([]models.TrashItem, error) {
+	items, err := s.repo.ListTrashItems(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash items: %w", err)
+	}
+
+	for i := range items {
+		items[i] = s.withExpiry(items[i])
+	}
+
+	return items, nil
+}
+
+func (s *trashService) RemoveItem(ctx context.Context, userID, itemID string) error {
+	if err := s.repo.DeleteTrashItem(ctx, userID, itemID); err != nil {
+		return fmt.Errorf("failed to remove trash item: %w", err)
+	}
+
+	return nil
+}
+
+// ListExpiredItems returns the next batch of items kept longer than the
+// configured maximum age.
+func (s *trashService) ListExpiredItems(ctx context.Context) ([]models.TrashItem, error) {
+	items, err := s.repo.ListTrashItemsDeletedBefore(ctx, time.Now().Add(-s.conf.MaxAge), purgeBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired trash items: %w", err)
+	}
+
+	return items, nil
+}
+
+func (s *trashService) RemoveUserTrash(ctx context.Context, userID string) error {
+	if err := s.repo.DeleteUserTrashItems(ctx, userID); err != nil {
+		return fmt.Errorf("failed to remove user trash: %w", err)
+	}
+
+	return nil
+}
+
+func (s *trashService) withExpiry(item models.TrashItem) models.TrashItem {
+	item.ExpiresAt = item.DeletedAt.Add(s.conf.MaxAge)
+	return item
+}
+
+func NewTrashService(repo repo.Repo, conf config.Trash) TrashService {
+	return &trashService{
+		repo: repo,
+		conf: conf,
+	}
+}
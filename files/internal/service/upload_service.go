@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/avran02/fileshare/files/internal/config"
+	"github.com/avran02/fileshare/files/internal/models"
+	"github.com/avran02/fileshare/files/internal/repo"
+	"github.com/google/uuid"
+)
+
+// maxUploadChunks is the most parts a MinIO multipart upload can have.
+const maxUploadChunks = 10000
+
+// UploadService keeps track of resumable upload sessions. The chunks
+// themselves are stored by FilesService.
+type UploadService interface {
+	NewSession(userID, path string, totalSize int64, checksum string) (models.UploadSession, error)
+	AddSession(ctx context.Context, session models.UploadSession) (models.UploadSession, error)
+	GetSession(ctx context.Context, userID, sessionID string) (models.UploadSession, error)
+	RemoveSession(ctx context.Context, userID, sessionID string) error
+	ListExpiredSessions(ctx context.Context) ([]models.UploadSession, error)
+	RemoveUserSessions(ctx context.Context, userID string) error
+}
+
+type uploadService struct {
+	repo repo.Repo
+	conf config.Upload
+}
+
+// NewSession validates the upload and picks its chunk size, growing it up to
+// the configured maximum for files too large to fit into maxUploadChunks
+// chunks of the configured size.
+func (s *uploadService) NewSession(userID, path string, totalSize int64, checksum string) (models.UploadSession, error) {
+	if path == "" {
+		return models.UploadSession{}, ErrEmptyFileName
+	}
+
+	if totalSize <= 0 {
+		return models.UploadSession{}, ErrInvalidUploadSize
+	}
+
+	checksum = strings.ToLower(checksum)
+	if checksum != "" {
+		if decoded, err := hex.DecodeString(checksum); err != nil || len(decoded) != 32 {
+			return models.UploadSession{}, ErrInvalidChecksum
+		}
+	}
+
+	chunkSize := s.conf.ChunkSize
+	if minSize := (totalSize + maxUploadChunks - 1) / maxUploadChunks; minSize > chunkSize {
+		chunkSize = minSize
+	}
+
+	if chunkSize > s.conf.MaxChunkSize {
+		return models.UploadSession{}, ErrUploadTooLarge
+	}
+
+	return models.UploadSession{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Path:      path,
+		TotalSize: totalSize,
+		ChunkSize: chunkSize,
+		Checksum:  checksum,
+		ExpiresAt: time.Now().Add(s.conf.SessionTTL),
+	}, nil
+}
+
+func (s *uploadService) AddSession(ctx context.Context, session models.UploadSession) (models.UploadSession, error) {
+	session, err := s.repo.CreateUploadSession(ctx, session)
+	if err != nil {
+		return models.UploadSession{}, fmt.Errorf("failed to add upload session: %w", err)
+	}
+
+	return session, nil
+}
+
+func (s *uploadService) GetSession(ctx context.Context, userID, sessionID string) (models.UploadSession, error) {
+	session, err := s.repo.GetUploadSession(ctx, userID, sessionID)
+	if err != nil {
+		return models.UploadSession{}, fmt.Errorf("failed to get upload session: %w", err)
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		return models.UploadSession{}, ErrUploadSessionExpired
+	}
+
+	return session, nil
+}
+
+func (s *uploadService) RemoveSession(ctx context.Context, userID, sessionID string) error {
+	if err := s.repo.DeleteUploadSession(ctx, userID, sessionID); err != nil {
+		return fmt.Errorf("failed to remove upload session: %w", err)
+	}
+
+	return nil
+}
+
+// ListExpiredSessions returns the next batch of abandoned sessions.
+func (s *uploadService) ListExpiredSessions(ctx context.Context) ([]models.UploadSession, error) {
+	sessions, err := s.repo.ListUploadSessionsExpiredBefore(ctx, time.Now(), purgeBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired upload sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+func (s *uploadService) RemoveUserSessions(ctx context.Context, userID string) error {
+	if err := s.repo.DeleteUserUploadSessions(ctx, userID); err != nil {
+		return fmt.Errorf("failed to remove user upload sessions: %w", err)
+	}
+
+	return nil
+}
+
+func NewUploadService(repo repo.Repo, conf config.Upload) UploadService {
+	return &uploadService{
+		repo: repo,
+		conf: conf,
+	}
+}
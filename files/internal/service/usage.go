@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/avran02/fileshare/files/internal/models"
+	"github.com/minio/minio-go/v7"
+)
+
+// Usage sums up what the user stores, including old versions and the trash.
+// Files in the root of the bucket are reported as a folder with an empty name.
+func (s *filesService) Usage(ctx context.Context, bucketName string) (models.Usage, error) {
+	if err := s.createBucketIfNotExists(ctx, bucketName); err != nil {
+		return models.Usage{}, err
+	}
+
+	var usage models.Usage
+	folders := make(map[string]int64)
+	err := s.walkVersions(ctx, bucketName, func(object minio.ObjectInfo) {
+		folder, _, found := strings.Cut(object.Key, "/")
+		if !found {
+			folder = ""
+		}
+		folders[folder] += object.Size
+		usage.UsedBytes += object.Size
+	})
+	if err != nil {
+		return models.Usage{}, err
+	}
+
+	trashBucket := trashBucketName(bucketName)
+	exists, err := s.minio.BucketExists(ctx, trashBucket)
+	if err != nil {
+		err = fmt.Errorf("failed to check if bucket exists: %w", err)
+		slog.Error(err.Error())
+		return models.Usage{}, err
+	}
+
+	if exists {
+		err = s.walkVersions(ctx, trashBucket, func(object minio.ObjectInfo) {
+			usage.TrashBytes += object.Size
+		})
+		if err != nil {
+			return models.Usage{}, err
+		}
+		usage.UsedBytes += usage.TrashBytes
+	}
+
+	usage.Folders = make([]models.FolderUsage, 0, len(folders))
+	for name, bytes := range folders {
+		usage.Folders = append(usage.Folders, models.FolderUsage{Name: name, Bytes: bytes})
+	}
+	sort.Slice(usage.Folders, func(i, j int) bool {
+		return usage.Folders[i].Bytes > usage.Folders[j].Bytes
+	})
+
+	return usage, nil
+}
+
+func (s *filesService) walkVersions(ctx context.Context, bucketName string, fn func(object minio.ObjectInfo)) error {
+	for object := range s.minio.ListObjects(ctx, bucketName, minio.ListObjectsOptions{
+		Recursive:    true,
+		WithVersions: true,
+	}) {
+		if object.Err != nil {
+			err := fmt.Errorf("failed to list object versions: %w", object.Err)
+			slog.Error(err.Error())
+			return err
+		}
+
+		if !object.IsDeleteMarker {
+			fn(object)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/avran02/fileshare/files/internal/models"
+	"github.com/avran02/fileshare/files/internal/repo"
+)
+
+type VersionService interface {
+	GetRetentionPolicy(ctx context.Context, userID string) (models.RetentionPolicy, error)
+	SetRetentionPolicy(ctx context.Context, userID string, keepVersions, keepDays int64) (models.RetentionPolicy, error)
+	RemoveUserRetentionPolicy(ctx context.Context, userID string) error
+	ListRetentionPolicies(ctx context.Context) ([]models.RetentionPolicy, error)
+}
+
+type versionService struct {
+	repo repo.Repo
+}
+
+func (s *versionService) GetRetentionPolicy(ctx context.Context, userID string) (models.RetentionPolicy, error) {
+	policy, err := s.repo.GetRetentionPolicy(ctx, userID)
+	if err != nil {
+		return models.RetentionPolicy{}, fmt.Errorf("failed to get retention policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+func (s *versionService) SetRetentionPolicy(ctx context.Context, userID string, keepVersions, keepDays int64) (models.RetentionPolicy, error) {
+	if keepVersions < 0 || keepDays < 0 {
+		return models.RetentionPolicy{}, ErrInvalidRetentionPolicy
+	}
+
+	policy, err := s.repo.SetRetentionPolicy(ctx, models.RetentionPolicy{
+		UserID:       userID,
+		KeepVersions: keepVersions,
+		KeepDays:     keepDays,
+	})
+	if err != nil {
+		return models.RetentionPolicy{}, fmt.Errorf("failed to set retention policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+func (s *versionService) RemoveUserRetentionPolicy(ctx context.Context, userID string) error {
+	if err := s.repo.DeleteRetentionPolicy(ctx, userID); err != nil {
+		return fmt.Errorf("failed to remove retention policy: %w", err)
+	}
+
+	return nil
+}
+
+func (s *versionService) ListRetentionPolicies(ctx context.Context) ([]models.RetentionPolicy, error) {
+	policies, err := s.repo.ListRetentionPolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retention policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+func NewVersionService(repo repo.Repo) VersionService {
+	return &versionService{
+		repo: repo,
+	}
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/avran02/fileshare/files/internal/models"
+	"github.com/minio/minio-go/v7"
+)
+
+// ListVersions returns every version of a file, newest first.
+func (s *filesService) ListVersions(ctx context.Context, bucketName, filePath string) ([]models.Version, error) {
+	if err := s.createBucketIfNotExists(ctx, bucketName); err != nil {
+		return nil, err
+	}
+
+	objects, err := s.keyVersions(ctx, bucketName, filePath)
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, err
+	}
+
+	versions := make([]models.Version, 0, len(objects))
+	for _, object := range objects {
+		versions = append(versions, models.Version{
+			ID:             object.VersionID,
+			Size:           object.Size,
+			LastModified:   object.LastModified,
+			IsLatest:       object.IsLatest,
+			IsDeleteMarker: object.IsDeleteMarker,
+		})
+	}
+
+	if len(versions) == 0 {
+		return nil, ErrFileNotFound
+	}
+
+	return versions, nil
+}
+
+// RestoreVersion makes a copy of an old version the current one, so the
+// history up to the restore is kept.
+func (s *filesService) RestoreVersion(ctx context.Context, bucketName, filePath, versionID string) (string, error) {
+	if err := s.createBucketIfNotExists(ctx, bucketName); err != nil {
+		return "", err
+	}
+
+	info, err := s.minio.StatObject(ctx, bucketName, filePath, minio.StatObjectOptions{VersionID: versionID})
+	if err != nil {
+		switch minio.ToErrorResponse(err).Code {
+		case "NoSuchKey", "NoSuchVersion", "MethodNotAllowed":
+			return "", ErrVersionNotFound
+		}
+		err = fmt.Errorf("failed to stat object version: %w", err)
+		slog.Error(err.Error())
+		return "", err
+	}
+
+	upload, err := s.copyObject(ctx, bucketName, bucketName, objectTransfer{
+		src:     filePath,
+		version: versionID,
+		dst:     filePath,
+		size:    info.Size,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	slog.Info("Restored version " + versionID + " of " + filePath)
+	return upload.VersionID, nil
+}
+
+func (s *filesService) DeleteVersion(ctx context.Context, bucketName, filePath, versionID string) error {
+	if err := s.createBucketIfNotExists(ctx, bucketName); err != nil {
+		return err
+	}
+
+	if versionID == "" {
+		return ErrVersionNotFound
+	}
+
+	err := s.minio.RemoveObject(ctx, bucketName, filePath, minio.RemoveObjectOptions{VersionID: versionID})
+	if err != nil {
+		err = fmt.Errorf("failed to remove object version: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// PruneVersions drops the old versions of a file the retention policy no
+// longer keeps. The current version is never dropped.
+func (s *filesService) PruneVersions(ctx context.Context, bucketName, filePath string, policy models.RetentionPolicy) (int64, error) {
+	if policy.KeepVersions == 0 && policy.KeepDays == 0 {
+		return 0, nil
+	}
+
+	versions, err := s.ListVersions(ctx, bucketName, filePath)
+	if err != nil {
+		return 0, err
+	}
+
+	var removed int64
+	for _, versionID := range expiredVersions(versions, policy, time.Now()) {
+		if err = s.DeleteVersion(ctx, bucketName, filePath, versionID); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+
+	if removed > 0 {
+		slog.Info(fmt.Sprintf("Pruned %d old versions of %s", removed, filePath))
+	}
+	return removed, nil
+}
+
+// PruneBucketVersions applies the retention policy to every file of a bucket,
+// so that versions expire even for files which are never written again.
+func (s *filesService) PruneBucketVersions(ctx context.Context, bucketName string, policy models.RetentionPolicy) (int64, error) {
+	if policy.KeepVersions == 0 && policy.KeepDays == 0 {
+		return 0, nil
+	}
+
+	exists, err := s.minio.BucketExists(ctx, bucketName)
+	if err != nil {
+		err = fmt.Errorf("failed to check if bucket exists: %w", err)
+		slog.Error(err.Error())
+		return 0, err
+	}
+
+	if !exists {
+		return 0, nil
+	}
+
+	var (
+		removed  int64
+		key      string
+		versions []models.Version
+	)
+	prune := func() error {
+		for _, versionID := range expiredVersions(versions, policy, time.Now()) {
+			if err := s.DeleteVersion(ctx, bucketName, key, versionID); err != nil {
+				return err
+			}
+			removed++
+		}
+		return nil
+	}
+
+	// versions of a key are listed together, newest first
+	for object := range s.minio.ListObjects(ctx, bucketName, minio.ListObjectsOptions{
+		Recursive:    true,
+		WithVersions: true,
+	}) {
+		if object.Err != nil {
+			err := fmt.Errorf("failed to list object versions: %w", object.Err)
+			slog.Error(err.Error())
+			return removed, err
+		}
+
+		if object.Key != key {
+			if err := prune(); err != nil {
+				return removed, err
+			}
+			key, versions = object.Key, versions[:0]
+		}
+
+		versions = append(versions, models.Version{
+			ID:             object.VersionID,
+			LastModified:   object.LastModified,
+			IsLatest:       object.IsLatest,
+			IsDeleteMarker: object.IsDeleteMarker,
+		})
+	}
+
+	if err := prune(); err != nil {
+		return removed, err
+	}
+
+	if removed > 0 {
+		slog.Info(fmt.Sprintf("Pruned %d old versions in bucket %s", removed, bucketName))
+	}
+	return removed, nil
+}
+
+// expiredVersions picks the old versions, listed newest first, the retention
+// policy no longer keeps. The current version is never picked.
+func expiredVersions(versions []models.Version, policy models.RetentionPolicy, now time.Time) []string {
+	cutoff := now.AddDate(0, 0, -int(policy.KeepDays))
+
+	var (
+		kept    int64
+		expired []string
+	)
+	for _, version := range versions {
+		if version.IsLatest {
+			continue
+		}
+
+		if (policy.KeepVersions > 0 && kept < policy.KeepVersions) || (policy.KeepDays > 0 && version.LastModified.After(cutoff)) {
+			kept++
+			continue
+		}
+
+		expired = append(expired, version.ID)
+	}
+
+	return expired
+}
+
+// keyVersions lists the versions of key alone, newest first. Other keys
+// starting with key sort after it, so the listing stops at the first of them
+// instead of walking everything under the prefix.
+func (s *filesService) keyVersions(ctx context.Context, bucketName, key string) ([]minio.ObjectInfo, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	versions := make([]minio.ObjectInfo, 0)
+	for object := range s.minio.ListObjects(ctx, bucketName, minio.ListObjectsOptions{
+		Prefix:       key,
+		Recursive:    true,
+		WithVersions: true,
+	}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list object versions: %w", object.Err)
+		}
+
+		if object.Key != key {
+			break
+		}
+		versions = append(versions, object)
+	}
+
+	return versions, nil
+}
+
+// removeAllVersions permanently deletes a key along with its whole history.
+func (s *filesService) removeAllVersions(ctx context.Context, bucketName, key string) error {
+	versions, err := s.keyVersions(ctx, bucketName, key)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range versions {
+		if err := s.minio.RemoveObject(ctx, bucketName, key, minio.RemoveObjectOptions{VersionID: object.VersionID}); err != nil {
+			return fmt.Errorf("failed to remove object version: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ensureVersioning turns versioning on for buckets created before files were
+// versioned. It is checked once per bucket for the process lifetime.
+func (s *filesService) ensureVersioning(ctx context.Context, bucketName string) error {
+	if _, ok := s.versioned.Load(bucketName); ok {
+		return nil
+	}
+
+	conf, err := s.minio.GetBucketVersioning(ctx, bucketName)
+	if err != nil {
+		err = fmt.Errorf("failed to get bucket versioning: %w", err)
+		slog.Error(err.Error())
+		return err
+	}
+
+	if !conf.Enabled() {
+		if err = s.minio.EnableVersioning(ctx, bucketName); err != nil {
+			err = fmt.Errorf("failed to enable bucket versioning: %w", err)
+			slog.Error(err.Error())
+			return err
+		}
+		slog.Info("Enabled versioning for bucket " + bucketName)
+	}
+
+	s.versioned.Store(bucketName, struct{}{})
+	return nil
+}
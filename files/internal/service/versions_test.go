@@ -0,0 +1,54 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/avran02/fileshare/files/internal/models"
+)
+
+func TestExpiredVersions(t *testing.T) {
+	now := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+
+	// newest first, as ListVersions returns them
+	versions := []models.Version{
+		{ID: "current", IsLatest: true, LastModified: daysAgo(0)},
+		{ID: "v4", LastModified: daysAgo(1)},
+		{ID: "v3", LastModified: daysAgo(5)},
+		{ID: "v2", LastModified: daysAgo(10)},
+		{ID: "v1", LastModified: daysAgo(40)},
+	}
+
+	tests := []struct {
+		name   string
+		policy models.RetentionPolicy
+		want   []string
+	}{
+		{name: "keep versions", policy: models.RetentionPolicy{KeepVersions: 2}, want: []string{"v2", "v1"}},
+		{name: "keep more versions than there are", policy: models.RetentionPolicy{KeepVersions: 10}, want: nil},
+		{name: "keep days", policy: models.RetentionPolicy{KeepDays: 7}, want: []string{"v2", "v1"}},
+		{name: "keep days, all too old", policy: models.RetentionPolicy{KeepDays: 1}, want: []string{"v4", "v3", "v2", "v1"}},
+		// with both set a version is kept while either keeps it
+		{name: "versions keep more", policy: models.RetentionPolicy{KeepVersions: 3, KeepDays: 2}, want: []string{"v1"}},
+		{name: "days keep more", policy: models.RetentionPolicy{KeepVersions: 1, KeepDays: 30}, want: []string{"v1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := expiredVersions(versions, tt.policy, now)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expiredVersions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpiredVersionsKeepsCurrent(t *testing.T) {
+	versions := []models.Version{{ID: "current", IsLatest: true, LastModified: time.Unix(0, 0)}}
+
+	if got := expiredVersions(versions, models.RetentionPolicy{KeepVersions: 1, KeepDays: 1}, time.Now()); len(got) != 0 {
+		t.Errorf("expiredVersions() = %v, want the current version kept", got)
+	}
+}
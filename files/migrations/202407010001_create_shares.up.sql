@@ -0,0 +1,14 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+CREATE TABLE IF NOT EXISTS shares (
+    id VARCHAR(255) PRIMARY KEY NOT NULL,
+    owner_id VARCHAR(255) NOT NULL,
+    recipient_id VARCHAR(255) NOT NULL,
+    path TEXT NOT NULL,
+    permission VARCHAR(10) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (owner_id, recipient_id, path)
+);
+
+CREATE INDEX IF NOT EXISTS shares_recipient_id_idx ON shares (recipient_id);
@@ -0,0 +1,9 @@
+-- +migrate Down
+-- SQL in section 'Down' is executed when this migration is rolled back
+
+DROP TABLE IF EXISTS drop_uploads;
+
+ALTER TABLE links
+    DROP COLUMN IF EXISTS kind,
+    DROP COLUMN IF EXISTS max_files,
+    DROP COLUMN IF EXISTS max_bytes;
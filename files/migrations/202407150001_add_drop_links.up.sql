@@ -0,0 +1,18 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+ALTER TABLE links
+    ADD COLUMN IF NOT EXISTS kind VARCHAR(10) NOT NULL DEFAULT 'download',
+    ADD COLUMN IF NOT EXISTS max_files BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS max_bytes BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS drop_uploads (
+    link_id VARCHAR(255) NOT NULL REFERENCES links (id) ON DELETE CASCADE,
+    owner_id VARCHAR(255) NOT NULL,
+    path TEXT NOT NULL,
+    size BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (owner_id, path)
+);
+
+CREATE INDEX IF NOT EXISTS drop_uploads_link_id_idx ON drop_uploads (link_id);
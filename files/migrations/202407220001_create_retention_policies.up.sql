@@ -0,0 +1,9 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+CREATE TABLE IF NOT EXISTS retention_policies (
+    user_id VARCHAR(255) PRIMARY KEY,
+    keep_versions BIGINT NOT NULL DEFAULT 0,
+    keep_days BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
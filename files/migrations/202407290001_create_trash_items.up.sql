@@ -0,0 +1,14 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+CREATE TABLE IF NOT EXISTS trash_items (
+    id VARCHAR(255) PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    path TEXT NOT NULL,
+    is_dir BOOLEAN NOT NULL DEFAULT FALSE,
+    size BIGINT NOT NULL DEFAULT 0,
+    deleted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS trash_items_user_id_idx ON trash_items (user_id);
+CREATE INDEX IF NOT EXISTS trash_items_deleted_at_idx ON trash_items (deleted_at);
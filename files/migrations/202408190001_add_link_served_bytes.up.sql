@@ -0,0 +1,5 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+ALTER TABLE links
+    ADD COLUMN IF NOT EXISTS served_bytes BIGINT NOT NULL DEFAULT 0;
@@ -3,11 +3,14 @@ module github.com/avran02/fileshare/gateway
 go 1.22.3
 
 require (
-	github.com/avran02/fileshare v0.0.4
+	github.com/avran02/fileshare/proto/authpb v0.0.0
+	github.com/avran02/fileshare/proto/filespb v0.0.0
 	github.com/go-chi/chi v1.5.5
 	github.com/go-chi/chi/v5 v5.0.14
+	github.com/golang-jwt/jwt/v5 v5.2.1
 	github.com/json-iterator/go v1.1.12
 	google.golang.org/grpc v1.64.0
+	google.golang.org/protobuf v1.34.2
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -18,5 +21,9 @@ require (
 	golang.org/x/sys v0.18.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237 // indirect
-	google.golang.org/protobuf v1.34.2 // indirect
+)
+
+replace (
+	github.com/avran02/fileshare/proto/authpb => ../proto/authpb
+	github.com/avran02/fileshare/proto/filespb => ../proto/filespb
 )
@@ -1,11 +1,13 @@
 package app
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 
 	"github.com/avran02/fileshare/gateway/internal/config"
 	"github.com/avran02/fileshare/gateway/internal/controller"
+	"github.com/avran02/fileshare/gateway/internal/pkg/verifier"
 	"github.com/avran02/fileshare/gateway/internal/router"
 	"github.com/avran02/fileshare/gateway/internal/service"
 	"google.golang.org/grpc"
@@ -15,6 +17,7 @@ type App struct {
 	*router.Router
 	*config.Config
 	*grpc.ClientConn
+	verifier verifier.Verifier
 }
 
 func New() *App {
@@ -23,26 +26,39 @@ func New() *App {
 	// TODO: Add auth service endpoint
 	authClient, _ := connectToAuthService(conf.AuthService.Endpoint)
 
+	filesService := service.NewFilesService(filesClient)
 	services := service.Services{
-		UserService:  service.NewUserService(authClient),
-		FilesService: service.NewFilesService(filesClient),
-		ShareService: service.NewShareService(),
+		UserService:    service.NewUserService(authClient),
+		FilesService:   filesService,
+		ShareService:   service.NewShareService(filesClient, authClient),
+		LinkService:    service.NewLinkService(filesClient),
+		AdminService:   service.NewAdminService(authClient, filesClient),
+		ArchiveService: service.NewArchiveService(filesService, conf.Archive),
 	}
 
+	tokenVerifier := verifier.New(authClient)
+
 	controllers := controller.Controllers{
-		UsersController: controller.NewUsersController(services.UserService),
-		FilesController: controller.NewFilesController(services.FilesService),
-		ShareController: controller.NewShareController(services.ShareService),
+		UsersController:   controller.NewUsersController(services.UserService),
+		FilesController:   controller.NewFilesController(services.FilesService),
+		ShareController:   controller.NewShareController(services.ShareService, services.FilesService),
+		LinkController:    controller.NewLinkController(services.LinkService, services.FilesService),
+		AdminController:   controller.NewAdminController(services.AdminService),
+		TusController:     controller.NewTusController(services.FilesService),
+		ArchiveController: controller.NewArchiveController(services.ArchiveService),
 	}
 	return &App{
-		Config: conf,
-		Router: router.New(controllers),
+		Config:   conf,
+		Router:   router.New(controllers, tokenVerifier, conf.Server.TrustedProxies),
+		verifier: tokenVerifier,
 		// ClientConn: conn,
 	}
 }
 
 func (a *App) RunServer() error {
 	endpoint := a.getServerEndpoint()
+	go a.verifier.Run(context.Background())
+
 	slog.Info("Server running on http://" + endpoint)
 	s := http.Server{ //nolint
 		Addr:    endpoint,
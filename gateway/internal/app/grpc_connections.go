@@ -5,11 +5,13 @@ import (
 	"log"
 	"log/slog"
 
+	"github.com/avran02/fileshare/gateway/internal/middlaware"
 	authpb "github.com/avran02/fileshare/proto/authpb"
 	filespb "github.com/avran02/fileshare/proto/filespb"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
 )
 
 func connectToFilesServer(endpoint string) (filespb.FileServiceClient, *grpc.ClientConn) {
@@ -23,7 +25,10 @@ func connectToFilesServer(endpoint string) (filespb.FileServiceClient, *grpc.Cli
 }
 
 func connectToAuthService(endpoint string) (authpb.AuthServiceClient, *grpc.ClientConn) {
-	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient(endpoint,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(forwardClientIP),
+	)
 	if err != nil {
 		log.Fatal("Failed to connect to gRPC server: ", err)
 	}
@@ -32,6 +37,15 @@ func connectToAuthService(endpoint string) (authpb.AuthServiceClient, *grpc.Clie
 	return authpb.NewAuthServiceClient(conn), conn
 }
 
+// forwardClientIP attaches the IP of the HTTP client a call is made for to its metadata.
+func forwardClientIP(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if ip, ok := ctx.Value(middlaware.ContextClientIPKey).(string); ok && ip != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, middlaware.ClientIPMetadataKey, ip)
+	}
+
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
 func checkServerHealth(conn *grpc.ClientConn, serviceName string) string {
 	resp, err := grpc_health_v1.NewHealthClient(conn).Check(
 		context.Background(),
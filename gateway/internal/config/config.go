@@ -10,6 +10,10 @@ import (
 type Server struct {
 	Host string `yaml:"host"`
 	Port string `yaml:"port"`
+
+	// TrustedProxies lists the addresses or CIDRs allowed to set
+	// X-Forwarded-For and X-Real-IP.
+	TrustedProxies []string `yaml:"trustedProxies"`
 }
 
 type FileService struct {
@@ -20,10 +24,17 @@ type AuthService struct {
 	Endpoint string `yaml:"endpoint"`
 }
 
+// Archive limits what can be downloaded as one archive, 0 meaning unlimited.
+type Archive struct {
+	MaxBytes   int64 `yaml:"maxBytes"`
+	MaxEntries int   `yaml:"maxEntries"`
+}
+
 type Config struct {
 	Server      Server      `yaml:"server"`
 	FileService FileService `yaml:"fileService"`
 	AuthService AuthService `yaml:"authService"`
+	Archive     Archive     `yaml:"archive"`
 }
 
 func New() *Config {
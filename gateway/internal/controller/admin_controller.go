@@ -0,0 +1,351 @@
+package controller
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/avran02/fileshare/gateway/internal/dto"
+	"github.com/avran02/fileshare/gateway/internal/middlaware"
+	"github.com/avran02/fileshare/gateway/internal/service"
+	authpb "github.com/avran02/fileshare/proto/authpb"
+	filespb "github.com/avran02/fileshare/proto/filespb"
+	"github.com/go-chi/chi/v5"
+)
+
+type AdminController interface {
+	ListUsers(w http.ResponseWriter, r *http.Request)
+	GetUser(w http.ResponseWriter, r *http.Request)
+	DisableUser(w http.ResponseWriter, r *http.Request)
+	EnableUser(w http.ResponseWriter, r *http.Request)
+	DeleteUser(w http.ResponseWriter, r *http.Request)
+	ForceLogout(w http.ResponseWriter, r *http.Request)
+
+	GetSettings(w http.ResponseWriter, r *http.Request)
+	UpdateSettings(w http.ResponseWriter, r *http.Request)
+
+	ListLockouts(w http.ResponseWriter, r *http.Request)
+	ClearLockout(w http.ResponseWriter, r *http.Request)
+
+	GetQuota(w http.ResponseWriter, r *http.Request)
+	SetQuota(w http.ResponseWriter, r *http.Request)
+	ResetQuota(w http.ResponseWriter, r *http.Request)
+}
+
+type adminController struct {
+	service service.AdminService
+}
+
+func (c *adminController) ListUsers(w http.ResponseWriter, r *http.Request) {
+	slog.Info("List users")
+	ctx := r.Context()
+	token := ctx.Value(middlaware.ContextTokenKey).(string)
+	query := r.URL.Query()
+
+	page, err := parseOptionalInt32(query.Get("page"))
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, "page must be a number", http.StatusBadRequest)
+		return
+	}
+
+	pageSize, err := parseOptionalInt32(query.Get("pageSize"))
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, "pageSize must be a number", http.StatusBadRequest)
+		return
+	}
+
+	users, total, err := c.service.ListUsers(ctx, token, query.Get("search"), page, pageSize)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.ListUsersResponse{
+		Users: make([]dto.UserInfo, len(users)),
+		Total: total,
+	}
+	for i, user := range users {
+		resp.Users[i] = userToDto(user)
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *adminController) GetUser(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Get user")
+	ctx := r.Context()
+	token := ctx.Value(middlaware.ContextTokenKey).(string)
+
+	user, err := c.service.GetUser(ctx, token, chi.URLParam(r, "userID"))
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(dto.GetUserResponse{User: userToDto(user)}); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *adminController) DisableUser(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Disable user")
+	c.runAction(w, r, c.service.DisableUser)
+}
+
+func (c *adminController) EnableUser(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Enable user")
+	c.runAction(w, r, c.service.EnableUser)
+}
+
+func (c *adminController) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Delete user")
+	c.runAction(w, r, c.service.DeleteUser)
+}
+
+func (c *adminController) ForceLogout(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Force user logout")
+	c.runAction(w, r, c.service.ForceLogout)
+}
+
+// runAction applies an admin action to the user from the URL and writes its result.
+func (c *adminController) runAction(w http.ResponseWriter, r *http.Request, action func(ctx context.Context, accessToken, userID string) (bool, error)) {
+	ctx := r.Context()
+	token := ctx.Value(middlaware.ContextTokenKey).(string)
+
+	ok, err := action(ctx, token, chi.URLParam(r, "userID"))
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(dto.AdminActionResponse{Success: ok}); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *adminController) GetSettings(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Get security settings")
+	ctx := r.Context()
+	token := ctx.Value(middlaware.ContextTokenKey).(string)
+
+	settings, err := c.service.GetSecuritySettings(ctx, token)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(dto.SecuritySettings{TOTPRequired: settings.TotpRequired}); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *adminController) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Update security settings")
+	ctx := r.Context()
+	token := ctx.Value(middlaware.ContextTokenKey).(string)
+
+	var req dto.SecuritySettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ok, err := c.service.UpdateSecuritySettings(ctx, token, req.TOTPRequired)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(dto.AdminActionResponse{Success: ok}); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *adminController) ListLockouts(w http.ResponseWriter, r *http.Request) {
+	slog.Info("List login lockouts")
+	ctx := r.Context()
+	token := ctx.Value(middlaware.ContextTokenKey).(string)
+
+	lockouts, err := c.service.ListLoginLockouts(ctx, token)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.ListLoginLockoutsResponse{
+		Lockouts: make([]dto.LoginLockout, len(lockouts)),
+	}
+	for i, lockout := range lockouts {
+		resp.Lockouts[i] = dto.LoginLockout{
+			Scope:        lockout.Scope,
+			Subject:      lockout.Subject,
+			Failures:     lockout.Failures,
+			LastFailedAt: lockout.LastFailedAt.AsTime(),
+		}
+		if lockout.LockedUntil != nil {
+			lockedUntil := lockout.LockedUntil.AsTime()
+			resp.Lockouts[i].LockedUntil = &lockedUntil
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *adminController) ClearLockout(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Clear login lockout")
+	ctx := r.Context()
+	token := ctx.Value(middlaware.ContextTokenKey).(string)
+
+	ok, err := c.service.ClearLoginLockout(ctx, token, chi.URLParam(r, "scope"), chi.URLParam(r, "subject"))
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(dto.AdminActionResponse{Success: ok}); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *adminController) GetQuota(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Get user quota")
+	ctx := r.Context()
+
+	quota, err := c.service.GetQuota(ctx, chi.URLParam(r, "userID"))
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(quotaToDto(quota)); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *adminController) SetQuota(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Set user quota")
+	ctx := r.Context()
+
+	var req dto.SetQuotaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.LimitBytes < 0 {
+		slog.Error("limitBytes is negative")
+		http.Error(w, "limitBytes is negative", http.StatusBadRequest)
+		return
+	}
+
+	quota, err := c.service.SetQuota(ctx, chi.URLParam(r, "userID"), req.LimitBytes, false)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(quotaToDto(quota)); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *adminController) ResetQuota(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Reset user quota")
+	ctx := r.Context()
+
+	quota, err := c.service.SetQuota(ctx, chi.URLParam(r, "userID"), 0, true)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(quotaToDto(quota)); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func quotaToDto(quota *filespb.Quota) dto.Quota {
+	info := dto.Quota{
+		LimitBytes: quota.LimitBytes,
+		IsDefault:  quota.IsDefault,
+	}
+
+	if quota.UpdatedAt != nil {
+		updatedAt := quota.UpdatedAt.AsTime()
+		info.UpdatedAt = &updatedAt
+	}
+
+	return info
+}
+
+func parseOptionalInt32(s string) (int32, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.ParseInt(s, 10, 32)
+	return int32(n), err
+}
+
+func userToDto(user *authpb.UserInfo) dto.UserInfo {
+	info := dto.UserInfo{
+		ID:           user.Id,
+		Username:     user.Username,
+		Role:         user.Role,
+		Disabled:     user.Disabled,
+		TOTPEnabled:  user.TotpEnabled,
+		FailedLogins: user.FailedLogins,
+	}
+
+	if user.LockedUntil != nil {
+		lockedUntil := user.LockedUntil.AsTime()
+		info.LockedUntil = &lockedUntil
+	}
+
+	return info
+}
+
+func NewAdminController(service service.AdminService) AdminController {
+	return &adminController{
+		service: service,
+	}
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/avran02/fileshare/gateway/internal/dto"
+	"github.com/avran02/fileshare/gateway/internal/middlaware"
+	"github.com/avran02/fileshare/gateway/internal/service"
+)
+
+// ArchiveController downloads folders and selections of files as a single
+// zip or tar.gz archive.
+type ArchiveController interface {
+	Archive(w http.ResponseWriter, r *http.Request)
+	ArchiveSelection(w http.ResponseWriter, r *http.Request)
+}
+
+type archiveController struct {
+	service service.ArchiveService
+}
+
+func (c *archiveController) Archive(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Download archive")
+	filePath := r.URL.Query().Get("path")
+	if filePath == "" {
+		slog.Error("path is empty")
+		http.Error(w, "path is required", http.StatusBadRequest)
+		return
+	}
+
+	c.serveArchive(w, r, []string{filePath}, r.URL.Query().Get("format"))
+}
+
+func (c *archiveController) ArchiveSelection(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Download archive of selected files")
+	req := dto.ArchiveRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Paths) == 0 {
+		slog.Error("paths are empty")
+		http.Error(w, "paths are required", http.StatusBadRequest)
+		return
+	}
+
+	c.serveArchive(w, r, req.Paths, req.Format)
+}
+
+// serveArchive lists all entries before writing anything, so that the limits
+// and missing paths can still be reported with a proper status.
+func (c *archiveController) serveArchive(w http.ResponseWriter, r *http.Request, paths []string, format string) {
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	var contentType string
+	switch format {
+	case "", service.ArchiveFormatZip:
+		format = service.ArchiveFormatZip
+		contentType = "application/zip"
+	case service.ArchiveFormatTarGz:
+		contentType = "application/gzip"
+	default:
+		slog.Error(service.ErrUnsupportedArchiveFormat.Error())
+		http.Error(w, "format must be zip or tar.gz", http.StatusBadRequest)
+		return
+	}
+
+	entries, err := c.service.ListEntries(ctx, userID, paths)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), archiveErrorStatus(err))
+		return
+	}
+
+	name := "archive"
+	if len(paths) == 1 {
+		if base := path.Base(strings.Trim(paths[0], "/")); base != "." && base != "" {
+			name = base
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+name+"."+format+"\"")
+
+	// the response is already started, so a failure can only be logged
+	if err = c.service.WriteArchive(ctx, w, userID, format, entries); err != nil {
+		slog.Error(err.Error())
+	}
+}
+
+func archiveErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrArchiveTooLarge):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrArchiveNameConflict):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrArchivePathNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func NewArchiveController(service service.ArchiveService) ArchiveController {
+	return &archiveController{
+		service: service,
+	}
+}
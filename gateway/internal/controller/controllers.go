@@ -5,9 +5,13 @@ import (
 )
 
 type Controllers struct {
-	UsersController UsersController
-	FilesController FilesController
-	ShareController ShareController
+	UsersController   UsersController
+	FilesController   FilesController
+	ShareController   ShareController
+	LinkController    LinkController
+	AdminController   AdminController
+	TusController     TusController
+	ArchiveController ArchiveController
 }
 
 var json = jsoniter.ConfigCompatibleWithStandardLibrary
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/avran02/fileshare/gateway/internal/service"
+)
+
+// serveDownload writes a file fetched by download into the response as an
+// attachment, honoring a single byte Range and the If-None-Match and
+// If-Modified-Since conditions.
+func serveDownload(w http.ResponseWriter, r *http.Request, filePath string, download func(ctx context.Context, offset, length int64) (*service.FileDownload, error)) {
+	offset, length, ranged := parseRange(r.Header.Get("Range"))
+
+	file, err := download(r.Context(), offset, length)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	metadata := file.Metadata
+	etag := `"` + strings.Trim(metadata.Etag, `"`) + `"`
+	lastModified := metadata.LastModified.AsTime()
+
+	filePathParts := strings.Split(filePath, "/")
+	fileName := filePathParts[len(filePathParts)-1]
+
+	contentType := metadata.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+
+	if notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	status := http.StatusOK
+	if ranged {
+		if metadata.Length == 0 {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", metadata.Size))
+			http.Error(w, "requested range not satisfiable", http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+
+		status = http.StatusPartialContent
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", metadata.Offset, metadata.Offset+metadata.Length-1, metadata.Size))
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
+	w.Header().Set("Content-Length", strconv.FormatInt(metadata.Length, 10))
+	w.WriteHeader(status)
+
+	// the status is already sent, so a failure can only cut the body short
+	if _, err = io.Copy(w, file); err != nil {
+		slog.Error(fmt.Errorf("failed to send file: %w", err).Error())
+	}
+}
+
+// parseRange reads a Range header with a single byte range into the offset
+// and length expected by the files service. Anything else is ignored, which
+// means the whole file is sent.
+func parseRange(header string) (offset, length int64, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false
+	}
+
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false
+	}
+
+	if first == "" {
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffix <= 0 {
+			return 0, 0, false
+		}
+		return -suffix, 0, true
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false
+	}
+
+	if last == "" {
+		return start, 0, true
+	}
+
+	end, err := strconv.ParseInt(last, 10, 64)
+	if err != nil || end < start {
+		return 0, 0, false
+	}
+
+	return start, end - start + 1, true
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when there is no
+// If-None-Match, as described in RFC 9110.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		for _, candidate := range strings.Split(header, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	return !lastModified.Truncate(time.Second).After(since)
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		offset int64
+		length int64
+		ok     bool
+	}{
+		{name: "no header", header: "", ok: false},
+		{name: "closed", header: "bytes=0-99", offset: 0, length: 100, ok: true},
+		{name: "single byte", header: "bytes=5-5", offset: 5, length: 1, ok: true},
+		{name: "open-ended", header: "bytes=100-", offset: 100, length: 0, ok: true},
+		{name: "suffix", header: "bytes=-500", offset: -500, length: 0, ok: true},
+		// satisfiability is up to the files service, which knows the size
+		{name: "past EOF", header: "bytes=1000000-1000099", offset: 1000000, length: 100, ok: true},
+		{name: "open-ended past EOF", header: "bytes=1000000-", offset: 1000000, length: 0, ok: true},
+		{name: "multi-range", header: "bytes=0-9,20-29", ok: false},
+		{name: "multi-range with suffix", header: "bytes=0-9, -5", ok: false},
+		{name: "empty suffix", header: "bytes=-0", ok: false},
+		{name: "end before start", header: "bytes=10-5", ok: false},
+		{name: "no dash", header: "bytes=10", ok: false},
+		{name: "negative start", header: "bytes=--5", ok: false},
+		{name: "other unit", header: "items=0-9", ok: false},
+		{name: "not a number", header: "bytes=a-b", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offset, length, ok := parseRange(tt.header)
+			if ok != tt.ok {
+				t.Fatalf("parseRange(%q) ok = %v, want %v", tt.header, ok, tt.ok)
+			}
+			if ok && (offset != tt.offset || length != tt.length) {
+				t.Errorf("parseRange(%q) = %d, %d, want %d, %d", tt.header, offset, length, tt.offset, tt.length)
+			}
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	const etag = `"abc"`
+	lastModified := time.Date(2024, 9, 1, 12, 0, 0, 500_000_000, time.UTC)
+	since := func(d time.Duration) string { return lastModified.Add(d).Format(http.TimeFormat) }
+
+	// request builds a conditional GET from header name and value pairs
+	request := func(headers ...string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/download", nil)
+		for i := 0; i < len(headers); i += 2 {
+			r.Header.Set(headers[i], headers[i+1])
+		}
+		return r
+	}
+
+	t.Run("etag", func(t *testing.T) {
+		for _, match := range []string{etag, `W/"abc"`, `"x", "abc"`, "*"} {
+			if !notModified(request("If-None-Match", match), etag, lastModified) {
+				t.Errorf("If-None-Match %s isn't matched", match)
+			}
+		}
+		if notModified(request("If-None-Match", `"x"`), etag, lastModified) {
+			t.Error("other etag is matched")
+		}
+	})
+
+	t.Run("date", func(t *testing.T) {
+		// the header has no sub-second precision
+		if !notModified(request("If-Modified-Since", since(0)), etag, lastModified) {
+			t.Error("same second counts as modified")
+		}
+		if !notModified(request("If-Modified-Since", since(time.Hour)), etag, lastModified) {
+			t.Error("later date counts as modified")
+		}
+		if notModified(request("If-Modified-Since", since(-time.Second)), etag, lastModified) {
+			t.Error("earlier date counts as not modified")
+		}
+		if notModified(request("If-Modified-Since", "yesterday"), etag, lastModified) {
+			t.Error("invalid date counts as not modified")
+		}
+	})
+
+	t.Run("etag wins over date", func(t *testing.T) {
+		if notModified(request("If-None-Match", `"x"`, "If-Modified-Since", since(time.Hour)), etag, lastModified) {
+			t.Error("date is used although the etag doesn't match")
+		}
+	})
+
+	if notModified(request(), etag, lastModified) {
+		t.Error("unconditional request counts as not modified")
+	}
+}
@@ -6,17 +6,39 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
-	"strings"
 
 	"github.com/avran02/fileshare/gateway/internal/dto"
 	"github.com/avran02/fileshare/gateway/internal/middlaware"
 	"github.com/avran02/fileshare/gateway/internal/service"
+	pb "github.com/avran02/fileshare/proto/filespb"
 )
 
 type FilesController interface {
 	Download(w http.ResponseWriter, r *http.Request)
 	Upload(w http.ResponseWriter, r *http.Request)
 	Rm(w http.ResponseWriter, r *http.Request)
+	Mkdir(w http.ResponseWriter, r *http.Request)
+	Rmdir(w http.ResponseWriter, r *http.Request)
+	Mv(w http.ResponseWriter, r *http.Request)
+	Cp(w http.ResponseWriter, r *http.Request)
+
+	Versions(w http.ResponseWriter, r *http.Request)
+	RestoreVersion(w http.ResponseWriter, r *http.Request)
+	DeleteVersion(w http.ResponseWriter, r *http.Request)
+	GetRetention(w http.ResponseWriter, r *http.Request)
+	SetRetention(w http.ResponseWriter, r *http.Request)
+
+	CreateUpload(w http.ResponseWriter, r *http.Request)
+	GetUpload(w http.ResponseWriter, r *http.Request)
+	UploadChunk(w http.ResponseWriter, r *http.Request)
+	CompleteUpload(w http.ResponseWriter, r *http.Request)
+	AbortUpload(w http.ResponseWriter, r *http.Request)
+
+	GetUsage(w http.ResponseWriter, r *http.Request)
+
+	ListTrash(w http.ResponseWriter, r *http.Request)
+	RestoreTrash(w http.ResponseWriter, r *http.Request)
+	EmptyTrash(w http.ResponseWriter, r *http.Request)
 	Ls(w http.ResponseWriter, r *http.Request)
 }
 
@@ -29,13 +51,8 @@ func (c *filesController) Download(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
 
-	pr, pw := io.Pipe()
-	streamErrChan := make(chan error, 1)
-
 	filePath := r.URL.Query().Get("filePath")
-
-	filePathParts := strings.Split(filePath, "/")
-	fileName := filePathParts[len(filePathParts)-1]
+	versionID := r.URL.Query().Get("versionID")
 
 	if userID == "" || filePath == "" {
 		slog.Error("userID or filePath is empty")
@@ -43,24 +60,9 @@ func (c *filesController) Download(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	go c.asyncDownloadFileFromGrpcStream(ctx, userID, filePath, pw, streamErrChan)
-
-	w.Header().Set("Content-Type", "application/octet-stream")
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
-
-	_, err := io.Copy(w, pr)
-	if err != nil {
-		slog.Error(err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	err = <-streamErrChan
-	if err != nil {
-		slog.Error(err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+	serveDownload(w, r, filePath, func(ctx context.Context, offset, length int64) (*service.FileDownload, error) {
+		return c.service.DownloadFile(ctx, userID, filePath, versionID, offset, length)
+	})
 }
 
 func (c *filesController) Upload(w http.ResponseWriter, r *http.Request) {
@@ -114,7 +116,7 @@ func (c *filesController) Rm(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ok, err := c.service.RemoveFile(ctx, userID, body.FilePath)
+	removed, err := c.service.RemoveFile(ctx, userID, body.FilePath)
 	if err != nil {
 		err = fmt.Errorf("failed to remove file: %w", err)
 		slog.Error(err.Error())
@@ -122,7 +124,10 @@ func (c *filesController) Rm(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rawResp, err := json.Marshal(dto.RemoveFileResponse{Success: ok})
+	rawResp, err := json.Marshal(dto.RemoveFileResponse{
+		Success:     removed.Success,
+		TrashItemID: removed.TrashItemID,
+	})
 	if err != nil {
 		err = fmt.Errorf("failed to marshal response: %w", err)
 		slog.Error(err.Error())
@@ -139,6 +144,156 @@ func (c *filesController) Rm(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (c *filesController) Mkdir(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Create a directory")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	var req dto.CreateDirectoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.FilePath == "" {
+		slog.Error("filePath is empty")
+		http.Error(w, "filePath is empty", http.StatusBadRequest)
+		return
+	}
+
+	ok, err := c.service.CreateDirectory(ctx, userID, req.FilePath)
+	if err != nil {
+		err = fmt.Errorf("failed to create directory: %w", err)
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(dto.CreateDirectoryResponse{Success: ok}); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *filesController) Rmdir(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Remove a directory")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	var req dto.RemoveDirectoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.FilePath == "" {
+		slog.Error("filePath is empty")
+		http.Error(w, "filePath is empty", http.StatusBadRequest)
+		return
+	}
+
+	removed, err := c.service.RemoveDirectory(ctx, userID, req.FilePath, req.Recursive)
+	if err != nil {
+		err = fmt.Errorf("failed to remove directory: %w", err)
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.RemoveDirectoryResponse{
+		Success:     removed.Success,
+		Removed:     removed.Removed,
+		TrashItemID: removed.TrashItemID,
+	}
+
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *filesController) Mv(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Move a file")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	var req dto.MoveFileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.SrcPath == "" || req.DstPath == "" {
+		slog.Error("srcPath or dstPath is empty")
+		http.Error(w, "srcPath or dstPath is empty", http.StatusBadRequest)
+		return
+	}
+
+	result, err := c.service.MoveFile(ctx, userID, req.SrcPath, req.DstPath, req.Overwrite)
+	if err != nil {
+		err = fmt.Errorf("failed to move file: %w", err)
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.MoveFileResponse{
+		Success: result.Success,
+		Moved:   result.Moved,
+		Failed:  result.Failed,
+	}
+
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *filesController) Cp(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Copy a file")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	var req dto.CopyFileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.SrcPath == "" || req.DstPath == "" {
+		slog.Error("srcPath or dstPath is empty")
+		http.Error(w, "srcPath or dstPath is empty", http.StatusBadRequest)
+		return
+	}
+
+	result, err := c.service.CopyFile(ctx, userID, req.ShareID, req.SrcPath, req.DstPath, req.Overwrite)
+	if err != nil {
+		err = fmt.Errorf("failed to copy file: %w", err)
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.CopyFileResponse{
+		Success: result.Success,
+		Copied:  result.Copied,
+		Failed:  result.Failed,
+	}
+
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
 func (c *filesController) Ls(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
@@ -162,11 +317,7 @@ func (c *filesController) Ls(w http.ResponseWriter, r *http.Request) {
 			slog.Warn("file is nil")
 			return
 		}
-		respFiles[i] = dto.FileInfo{
-			Name:         file.Name,
-			Size:         file.Size,
-			LastModified: file.LastModified.AsTime(),
-		}
+		respFiles[i] = fileInfoToDto(file)
 	}
 	err = json.NewEncoder(w).Encode(dto.ListFilesResponse{Files: respFiles})
 	if err != nil {
@@ -175,14 +326,19 @@ func (c *filesController) Ls(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (c *filesController) asyncDownloadFileFromGrpcStream(ctx context.Context, userID, filePath string, w *io.PipeWriter, streamErrChan chan error) {
-	defer close(streamErrChan)
+func fileInfoToDto(file *pb.FileInfo) dto.FileInfo {
+	info := dto.FileInfo{
+		Name: file.Name,
+		Type: file.Type,
+		Size: file.Size,
+	}
 
-	if err := c.service.DownloadFile(ctx, userID, filePath, w); err != nil {
-		slog.Error(err.Error())
-		streamErrChan <- fmt.Errorf("failed to create download stream: %w", err)
-		return
+	if file.LastModified != nil {
+		lastModified := file.LastModified.AsTime()
+		info.LastModified = &lastModified
 	}
+
+	return info
 }
 
 func NewFilesController(service service.FilesService) FilesController {
@@ -0,0 +1,238 @@
+package controller
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/avran02/fileshare/gateway/internal/dto"
+	"github.com/avran02/fileshare/gateway/internal/middlaware"
+	"github.com/avran02/fileshare/gateway/internal/service"
+	pb "github.com/avran02/fileshare/proto/filespb"
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	publicLinkPrefix   = "/api/v1/public/"
+	linkPasswordHeader = "X-Link-Password"
+)
+
+type LinkController interface {
+	Create(w http.ResponseWriter, r *http.Request)
+	Revoke(w http.ResponseWriter, r *http.Request)
+	List(w http.ResponseWriter, r *http.Request)
+	PublicLs(w http.ResponseWriter, r *http.Request)
+	PublicDownload(w http.ResponseWriter, r *http.Request)
+	PublicUpload(w http.ResponseWriter, r *http.Request)
+}
+
+type linkController struct {
+	service      service.LinkService
+	filesService service.FilesService
+}
+
+func (c *linkController) Create(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Create a public link")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	var req dto.CreateLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.FilePath == "" {
+		slog.Error("filePath is empty")
+		http.Error(w, "filePath is empty", http.StatusBadRequest)
+		return
+	}
+
+	createReq := &pb.CreateLinkRequest{
+		OwnerID:      userID,
+		Kind:         req.Type,
+		FilePath:     req.FilePath,
+		MaxDownloads: req.MaxDownloads,
+		MaxFiles:     req.MaxFiles,
+		MaxBytes:     req.MaxBytes,
+		Password:     req.Password,
+	}
+	if req.ExpiresAt != nil {
+		createReq.ExpiresAt = timestamppb.New(*req.ExpiresAt)
+	}
+
+	link, err := c.service.CreateLink(ctx, createReq)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(dto.CreateLinkResponse{Link: linkToDto(link)}); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *linkController) Revoke(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Revoke a public link")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+	linkID := chi.URLParam(r, "linkID")
+
+	ok, err := c.service.RevokeLink(ctx, userID, linkID)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(dto.RevokeLinkResponse{Success: ok}); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *linkController) List(w http.ResponseWriter, r *http.Request) {
+	slog.Info("List public links")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	links, err := c.service.ListLinks(ctx, userID)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.ListLinksResponse{
+		Links: make([]dto.LinkInfo, len(links)),
+	}
+	for i, link := range links {
+		resp.Links[i] = linkToDto(link)
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *linkController) PublicLs(w http.ResponseWriter, r *http.Request) {
+	slog.Info("List files of a public link")
+	ctx := r.Context()
+	token := chi.URLParam(r, "token")
+	filePath := r.URL.Query().Get("filePath")
+
+	files, err := c.filesService.ListLinkFiles(ctx, token, getLinkPassword(r), filePath)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	respFiles := make([]dto.FileInfo, 0, len(files))
+	for _, file := range files {
+		if file == nil {
+			continue
+		}
+		respFiles = append(respFiles, fileInfoToDto(file))
+	}
+
+	if err = json.NewEncoder(w).Encode(dto.ListFilesResponse{Files: respFiles}); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *linkController) PublicDownload(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Download a file through a public link")
+	ctx := r.Context()
+	token := chi.URLParam(r, "token")
+	password := getLinkPassword(r)
+
+	filePath, err := c.service.ResolveLink(ctx, token, password, r.URL.Query().Get("filePath"))
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	if strings.HasSuffix(filePath, "/") {
+		slog.Error("filePath is a folder")
+		http.Error(w, "filePath is required for folder links", http.StatusBadRequest)
+		return
+	}
+
+	serveDownload(w, r, filePath, func(ctx context.Context, offset, length int64) (*service.FileDownload, error) {
+		return c.filesService.DownloadLinkFile(ctx, token, password, filePath, offset, length)
+	})
+}
+
+func (c *linkController) PublicUpload(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Upload a file through a drop link")
+	ctx := r.Context()
+	token := chi.URLParam(r, "token")
+
+	req, err := dto.NewUploadFileRequestFromHTTPForm(r)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filePath, err := c.filesService.UploadLinkFile(ctx, req.File, token, getLinkPassword(r), req.FilePath)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(dto.DropUploadResponse{FilePath: filePath}); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// getLinkPassword only reads the header, as query strings end up in access logs.
+func getLinkPassword(r *http.Request) string {
+	return r.Header.Get(linkPasswordHeader)
+}
+
+func linkToDto(link *pb.LinkInfo) dto.LinkInfo {
+	info := dto.LinkInfo{
+		ID:            link.Id,
+		Type:          link.Kind,
+		URL:           publicLinkPrefix + link.Token,
+		FilePath:      link.FilePath,
+		MaxDownloads:  link.MaxDownloads,
+		Downloads:     link.Downloads,
+		MaxFiles:      link.MaxFiles,
+		Files:         link.Files,
+		MaxBytes:      link.MaxBytes,
+		UploadedBytes: link.UploadedBytes,
+		HasPassword:   link.HasPassword,
+		CreatedAt:     link.CreatedAt.AsTime(),
+	}
+	if link.ExpiresAt != nil {
+		expiresAt := link.ExpiresAt.AsTime()
+		info.ExpiresAt = &expiresAt
+	}
+
+	return info
+}
+
+func NewLinkController(service service.LinkService, filesService service.FilesService) LinkController {
+	return &linkController{
+		service:      service,
+		filesService: filesService,
+	}
+}
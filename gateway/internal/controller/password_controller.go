@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/avran02/fileshare/gateway/internal/dto"
+	"github.com/avran02/fileshare/gateway/internal/middlaware"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (c *userController) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Change password")
+	ctx := r.Context()
+	token := ctx.Value(middlaware.ContextTokenKey).(string)
+
+	var req dto.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ok, err := c.service.ChangePassword(ctx, token, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), passwordErrorStatus(err))
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(dto.PasswordResponse{Success: ok}); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *userController) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Request password reset")
+	ctx := r.Context()
+
+	var req dto.RequestPasswordResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ok, err := c.service.RequestPasswordReset(ctx, req.Username)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), passwordErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(dto.PasswordResponse{Success: ok}); err != nil {
+		slog.Error(err.Error())
+		return
+	}
+}
+
+func (c *userController) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Reset password")
+	ctx := r.Context()
+
+	var req dto.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ok, err := c.service.ResetPassword(ctx, req.Token, req.NewPassword)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), passwordErrorStatus(err))
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(dto.PasswordResponse{Success: ok}); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// passwordErrorStatus tells passwords the client got wrong, including ones
+// the password policy rejects, and throttled attempts apart from other failures.
+func passwordErrorStatus(err error) int {
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -1,43 +1,231 @@
 package controller
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 
+	"github.com/avran02/fileshare/gateway/internal/dto"
+	"github.com/avran02/fileshare/gateway/internal/middlaware"
 	"github.com/avran02/fileshare/gateway/internal/service"
+	"github.com/go-chi/chi/v5"
 )
 
 type ShareController interface {
 	Share(w http.ResponseWriter, r *http.Request)
 	Unshare(w http.ResponseWriter, r *http.Request)
+	ListOutgoing(w http.ResponseWriter, r *http.Request)
+	ListIncoming(w http.ResponseWriter, r *http.Request)
+	LsShared(w http.ResponseWriter, r *http.Request)
+	DownloadShared(w http.ResponseWriter, r *http.Request)
 }
 
 type shareController struct {
-	service service.ShareService
+	service      service.ShareService
+	filesService service.FilesService
 }
 
 func (c *shareController) Share(w http.ResponseWriter, r *http.Request) {
 	slog.Info("Share a file with users")
-	c.service.Share()
-	_, err := w.Write([]byte("Share a file with users"))
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	var req dto.ShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.FilePath == "" || req.Username == "" {
+		slog.Error("filePath or username is empty")
+		http.Error(w, "filePath or username is empty", http.StatusBadRequest)
+		return
+	}
+
+	share, err := c.service.Share(ctx, userID, req.Username, req.FilePath, req.Permission)
 	if err != nil {
 		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.ShareResponse{
+		Share: dto.ShareInfo{
+			ID:         share.Id,
+			Username:   req.Username,
+			FilePath:   share.FilePath,
+			Permission: share.Permission,
+			CreatedAt:  share.CreatedAt.AsTime(),
+		},
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 }
 
 func (c *shareController) Unshare(w http.ResponseWriter, r *http.Request) {
 	slog.Info("Unshare a file with users")
-	c.service.Unshare()
-	_, err := w.Write([]byte("Unshare a file with users"))
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	var req dto.UnshareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ok, err := c.service.Unshare(ctx, userID, req.ShareID)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.UnshareResponse{
+		Success: ok,
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *shareController) ListOutgoing(w http.ResponseWriter, r *http.Request) {
+	slog.Info("List files shared by user")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	shares, err := c.service.ListShares(ctx, userID)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.ListSharesResponse{
+		Shares: make([]dto.ShareInfo, len(shares)),
+	}
+	for i, share := range shares {
+		username, err := c.service.GetUsername(ctx, share.RecipientID)
+		if err != nil {
+			slog.Warn("failed to resolve recipient of share " + share.Id + ": " + err.Error())
+		}
+
+		resp.Shares[i] = dto.ShareInfo{
+			ID:         share.Id,
+			Username:   username,
+			FilePath:   share.FilePath,
+			Permission: share.Permission,
+			CreatedAt:  share.CreatedAt.AsTime(),
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *shareController) ListIncoming(w http.ResponseWriter, r *http.Request) {
+	slog.Info("List files shared with user")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	shares, err := c.service.ListIncomingShares(ctx, userID)
 	if err != nil {
 		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+
+	owners := make(map[string]string)
+	resp := dto.ListIncomingSharesResponse{
+		Shares: make([]dto.IncomingShareInfo, len(shares)),
+	}
+	for i, share := range shares {
+		owner, ok := owners[share.OwnerID]
+		if !ok {
+			owner, err = c.service.GetUsername(ctx, share.OwnerID)
+			if err != nil {
+				slog.Warn("failed to resolve owner of share " + share.Id + ": " + err.Error())
+			}
+			owners[share.OwnerID] = owner
+		}
+
+		resp.Shares[i] = dto.IncomingShareInfo{
+			ID:         share.Id,
+			Owner:      owner,
+			FilePath:   share.FilePath,
+			Permission: share.Permission,
+			SharedAt:   share.CreatedAt.AsTime(),
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *shareController) LsShared(w http.ResponseWriter, r *http.Request) {
+	slog.Info("List files of a share")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+	shareID := chi.URLParam(r, "shareID")
+	filePath := r.URL.Query().Get("filePath")
+
+	files, err := c.filesService.ListSharedFiles(ctx, userID, shareID, filePath)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	respFiles := make([]dto.FileInfo, 0, len(files))
+	for _, file := range files {
+		if file == nil {
+			continue
+		}
+		respFiles = append(respFiles, fileInfoToDto(file))
+	}
+
+	if err = json.NewEncoder(w).Encode(dto.ListFilesResponse{Files: respFiles}); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *shareController) DownloadShared(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Download a shared file")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+	shareID := chi.URLParam(r, "shareID")
+	filePath := r.URL.Query().Get("filePath")
+
+	if shareID == "" || filePath == "" {
+		slog.Error("shareID or filePath is empty")
+		http.Error(w, "shareID or filePath is empty", http.StatusBadRequest)
+		return
+	}
+
+	serveDownload(w, r, filePath, func(ctx context.Context, offset, length int64) (*service.FileDownload, error) {
+		return c.filesService.DownloadSharedFile(ctx, userID, shareID, filePath, offset, length)
+	})
 }
 
-func NewShareController(service service.ShareService) ShareController {
+func NewShareController(service service.ShareService, filesService service.FilesService) ShareController {
 	return &shareController{
-		service: service,
+		service:      service,
+		filesService: filesService,
 	}
 }
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/avran02/fileshare/gateway/internal/dto"
+	"github.com/avran02/fileshare/gateway/internal/middlaware"
+	pb "github.com/avran02/fileshare/proto/authpb"
+	"github.com/go-chi/chi/v5"
+)
+
+func (c *userController) CreatePersonalToken(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Create a personal access token")
+	ctx := r.Context()
+	token := ctx.Value(middlaware.ContextTokenKey).(string)
+
+	var req dto.CreatePersonalTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := c.service.CreatePersonalToken(ctx, token, req.Name, req.Scopes, req.ExpiresInDays)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := dto.CreatePersonalTokenResponse{
+		Token: created.Token,
+		Info:  personalTokenToDto(created.Info),
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *userController) ListPersonalTokens(w http.ResponseWriter, r *http.Request) {
+	slog.Info("List personal access tokens")
+	ctx := r.Context()
+	token := ctx.Value(middlaware.ContextTokenKey).(string)
+
+	tokens, err := c.service.ListPersonalTokens(ctx, token)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.ListPersonalTokensResponse{
+		Tokens: make([]dto.PersonalTokenInfo, len(tokens)),
+	}
+	for i, info := range tokens {
+		resp.Tokens[i] = personalTokenToDto(info)
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *userController) RevokePersonalToken(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Revoke a personal access token")
+	ctx := r.Context()
+	token := ctx.Value(middlaware.ContextTokenKey).(string)
+
+	ok, err := c.service.RevokePersonalToken(ctx, token, chi.URLParam(r, "tokenID"))
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(dto.RevokePersonalTokenResponse{Success: ok}); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func personalTokenToDto(info *pb.PersonalTokenInfo) dto.PersonalTokenInfo {
+	token := dto.PersonalTokenInfo{
+		ID:        info.Id,
+		Name:      info.Name,
+		Prefix:    info.Prefix,
+		Scopes:    info.Scopes,
+		CreatedAt: info.CreatedAt.AsTime(),
+		ExpiresAt: info.ExpiresAt.AsTime(),
+	}
+
+	if info.LastUsedAt != nil {
+		lastUsedAt := info.LastUsedAt.AsTime()
+		token.LastUsedAt = &lastUsedAt
+	}
+
+	return token
+}
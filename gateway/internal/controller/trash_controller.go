@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/avran02/fileshare/gateway/internal/dto"
+	"github.com/avran02/fileshare/gateway/internal/middlaware"
+	"github.com/go-chi/chi/v5"
+)
+
+func (c *filesController) ListTrash(w http.ResponseWriter, r *http.Request) {
+	slog.Info("List trash")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	items, err := c.service.ListTrash(ctx, userID)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.ListTrashResponse{
+		Items: make([]dto.TrashItem, len(items)),
+	}
+	for i, item := range items {
+		resp.Items[i] = dto.TrashItem{
+			ID:        item.Id,
+			FilePath:  item.FilePath,
+			IsDir:     item.IsDir,
+			Size:      item.Size,
+			DeletedAt: item.DeletedAt.AsTime(),
+			ExpiresAt: item.ExpiresAt.AsTime(),
+		}
+	}
+
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *filesController) RestoreTrash(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Restore from trash")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	var req dto.RestoreTrashRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.ItemID == "" {
+		slog.Error("itemID is empty")
+		http.Error(w, "itemID is empty", http.StatusBadRequest)
+		return
+	}
+
+	restored, err := c.service.RestoreTrash(ctx, userID, req.ItemID, req.FilePath, req.Conflict)
+	if err != nil {
+		err = fmt.Errorf("failed to restore from trash: %w", err)
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.RestoreTrashResponse{
+		Success:  restored.Success,
+		FilePath: restored.FilePath,
+		Restored: restored.Restored,
+		Failed:   restored.Failed,
+	}
+
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *filesController) EmptyTrash(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Empty trash")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+	itemID := chi.URLParam(r, "itemID")
+
+	removed, err := c.service.EmptyTrash(ctx, userID, itemID)
+	if err != nil {
+		err = fmt.Errorf("failed to empty trash: %w", err)
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.EmptyTrashResponse{
+		Success: true,
+		Removed: removed,
+	}
+
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
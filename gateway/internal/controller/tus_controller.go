@@ -0,0 +1,243 @@
+package controller
+
+import (
+	"encoding/base64"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/avran02/fileshare/gateway/internal/middlaware"
+	"github.com/avran02/fileshare/gateway/internal/service"
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	tusVersion     = "1.0.0"
+	tusExtensions  = "creation,termination,expiration"
+	tusContentType = "application/offset+octet-stream"
+)
+
+// TusController implements the tus resumable upload protocol on top of the
+// files service upload sessions.
+type TusController interface {
+	Options(w http.ResponseWriter, r *http.Request)
+	Create(w http.ResponseWriter, r *http.Request)
+	Head(w http.ResponseWriter, r *http.Request)
+	Patch(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+}
+
+type tusController struct {
+	service service.FilesService
+}
+
+func (c *tusController) Options(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	w.Header().Set("Tus-Version", tusVersion)
+	w.Header().Set("Tus-Extension", tusExtensions)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Create starts an upload. The path is taken from the filePath metadata, or
+// from filename for clients which only send that.
+func (c *tusController) Create(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Create tus upload")
+	if !checkTusVersion(w, r) {
+		return
+	}
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || length <= 0 {
+		slog.Error("invalid Upload-Length")
+		http.Error(w, "Upload-Length must be a positive number", http.StatusBadRequest)
+		return
+	}
+
+	metadata, err := parseTusMetadata(r.Header.Get("Upload-Metadata"))
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, "invalid Upload-Metadata", http.StatusBadRequest)
+		return
+	}
+
+	filePath := metadata["filePath"]
+	if filePath == "" {
+		filePath = metadata["filename"]
+	}
+	if filePath == "" {
+		slog.Error("filePath is empty")
+		http.Error(w, "filePath or filename metadata is required", http.StatusBadRequest)
+		return
+	}
+
+	session, err := c.service.CreateUploadSession(ctx, userID, filePath, length, metadata["checksum"])
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), tusErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, "/")+"/"+session.Id)
+	w.Header().Set("Upload-Expires", session.ExpiresAt.AsTime().UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (c *tusController) Head(w http.ResponseWriter, r *http.Request) {
+	if !checkTusVersion(w, r) {
+		return
+	}
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	resp, err := c.service.GetUploadSession(ctx, userID, chi.URLParam(r, "uploadID"))
+	if err != nil {
+		slog.Error(err.Error())
+		w.WriteHeader(tusErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Upload-Offset", strconv.FormatInt(resp.Offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(resp.Session.TotalSize, 10))
+	w.Header().Set("Upload-Expires", resp.Session.ExpiresAt.AsTime().UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusOK)
+}
+
+// Patch appends the request body to the upload and completes it once all of
+// its bytes have arrived.
+func (c *tusController) Patch(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Patch tus upload")
+	if !checkTusVersion(w, r) {
+		return
+	}
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+	uploadID := chi.URLParam(r, "uploadID")
+
+	if r.Header.Get("Content-Type") != tusContentType {
+		slog.Error("unsupported content type")
+		http.Error(w, "Content-Type must be "+tusContentType, http.StatusUnsupportedMediaType)
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		slog.Error("invalid Upload-Offset")
+		http.Error(w, "Upload-Offset must be a non-negative number", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := c.service.GetUploadSession(ctx, userID, uploadID)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), tusErrorStatus(err))
+		return
+	}
+
+	if offset != resp.Offset {
+		slog.Error("Upload-Offset doesn't match")
+		http.Error(w, "Upload-Offset doesn't match the upload", http.StatusConflict)
+		return
+	}
+
+	if r.ContentLength > 0 && offset+r.ContentLength > resp.Session.TotalSize {
+		slog.Error("body exceeds Upload-Length")
+		http.Error(w, "body exceeds Upload-Length", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	offset, err = c.service.AppendUpload(ctx, r.Body, userID, uploadID, offset)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), tusErrorStatus(err))
+		return
+	}
+
+	if offset == resp.Session.TotalSize {
+		if _, err = c.service.CompleteUploadSession(ctx, userID, uploadID); err != nil {
+			slog.Error(err.Error())
+			http.Error(w, err.Error(), tusErrorStatus(err))
+			return
+		}
+	}
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	w.Header().Set("Upload-Expires", resp.Session.ExpiresAt.AsTime().UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *tusController) Delete(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Terminate tus upload")
+	if !checkTusVersion(w, r) {
+		return
+	}
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	if _, err := c.service.AbortUploadSession(ctx, userID, chi.URLParam(r, "uploadID")); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), tusErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkTusVersion sets the protocol header of the response and rejects
+// clients speaking another version of the protocol.
+func checkTusVersion(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	if r.Header.Get("Tus-Resumable") == tusVersion {
+		return true
+	}
+
+	w.Header().Set("Tus-Version", tusVersion)
+	http.Error(w, "unsupported tus version", http.StatusPreconditionFailed)
+	return false
+}
+
+// parseTusMetadata decodes the Upload-Metadata header, a comma separated list
+// of keys, each followed by a space and its base64 encoded value if it has one.
+func parseTusMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			continue
+		}
+
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, err
+		}
+		metadata[key] = string(value)
+	}
+
+	return metadata, nil
+}
+
+func tusErrorStatus(err error) int {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.FailedPrecondition:
+		return http.StatusGone
+	case codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func NewTusController(service service.FilesService) TusController {
+	return &tusController{
+		service: service,
+	}
+}
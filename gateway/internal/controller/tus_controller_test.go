@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestParseTusMetadata(t *testing.T) {
+	check := func(header string, want map[string]string) {
+		t.Helper()
+
+		got, err := parseTusMetadata(header)
+		if err != nil {
+			t.Fatalf("parseTusMetadata(%q): %v", header, err)
+		}
+		if !maps.Equal(got, want) {
+			t.Errorf("parseTusMetadata(%q) = %v, want %v", header, got, want)
+		}
+	}
+
+	check("", map[string]string{})
+	check("filename d29ybGQ=", map[string]string{"filename": "world"})
+	check("filePath ZG9jcy9yZXBvcnQucGRm, filename d29ybGQ=", map[string]string{"filePath": "docs/report.pdf", "filename": "world"})
+
+	// keys may come without a value
+	check("is_confidential", map[string]string{"is_confidential": ""})
+	check("is_confidential ", map[string]string{"is_confidential": ""})
+	check("filename d29ybGQ=,is_confidential", map[string]string{"filename": "world", "is_confidential": ""})
+
+	check(",, filename d29ybGQ=,", map[string]string{"filename": "world"})
+}
+
+func TestParseTusMetadataRejectsInvalidBase64(t *testing.T) {
+	if got, err := parseTusMetadata("filename not-base64!"); err == nil {
+		t.Errorf("parseTusMetadata() = %v, want an error", got)
+	}
+}
@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/avran02/fileshare/gateway/internal/dto"
+	"github.com/avran02/fileshare/gateway/internal/middlaware"
+)
+
+func (c *userController) VerifyLoginTOTP(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Verify login second factor")
+	ctx := r.Context()
+
+	var req dto.VerifyLoginTOTPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	accessToken, refreshToken, err := c.service.VerifyLoginTOTP(ctx, req.ChallengeToken, req.Code, r.UserAgent(), middlaware.ClientIP(r))
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), loginErrorStatus(err))
+		return
+	}
+
+	resp := dto.LoginUserResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *userController) BeginTOTPEnrollment(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Begin TOTP enrollment")
+	ctx := r.Context()
+
+	var req dto.TOTPEnrollmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	token := enrollmentToken(r, req.ChallengeToken)
+	if token == "" {
+		slog.Error("challengeToken or Authorization header is required")
+		http.Error(w, "challengeToken or Authorization header is required", http.StatusUnauthorized)
+		return
+	}
+
+	enrollment, err := c.service.BeginTOTPEnrollment(ctx, token)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := dto.BeginTOTPEnrollmentResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningURI: enrollment.ProvisioningURI,
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *userController) ConfirmTOTPEnrollment(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Confirm TOTP enrollment")
+	ctx := r.Context()
+
+	var req dto.TOTPEnrollmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	token := enrollmentToken(r, req.ChallengeToken)
+	if token == "" {
+		slog.Error("challengeToken or Authorization header is required")
+		http.Error(w, "challengeToken or Authorization header is required", http.StatusUnauthorized)
+		return
+	}
+
+	confirmation, err := c.service.ConfirmTOTPEnrollment(ctx, token, req.Code, r.UserAgent(), middlaware.ClientIP(r))
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := dto.ConfirmTOTPEnrollmentResponse{
+		RecoveryCodes: confirmation.RecoveryCodes,
+		AccessToken:   confirmation.AccessToken,
+		RefreshToken:  confirmation.RefreshToken,
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *userController) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Disable TOTP")
+	ctx := r.Context()
+	token := ctx.Value(middlaware.ContextTokenKey).(string)
+
+	var req dto.DisableTOTPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ok, err := c.service.DisableTOTP(ctx, token, req.Code)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(dto.DisableTOTPResponse{Success: ok}); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func enrollmentToken(r *http.Request, challengeToken string) string {
+	if challengeToken != "" {
+		return challengeToken
+	}
+
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/avran02/fileshare/gateway/internal/dto"
+	"github.com/avran02/fileshare/gateway/internal/middlaware"
+	pb "github.com/avran02/fileshare/proto/filespb"
+	"github.com/go-chi/chi/v5"
+)
+
+func (c *filesController) CreateUpload(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Create upload session")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	var req dto.CreateUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.FilePath == "" || req.TotalSize <= 0 {
+		slog.Error("filePath is empty or totalSize is not positive")
+		http.Error(w, "filePath is empty or totalSize is not positive", http.StatusBadRequest)
+		return
+	}
+
+	session, err := c.service.CreateUploadSession(ctx, userID, req.FilePath, req.TotalSize, req.Checksum)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(uploadSessionToDto(session, nil)); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *filesController) GetUpload(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Get upload session")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	resp, err := c.service.GetUploadSession(ctx, userID, chi.URLParam(r, "sessionID"))
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(uploadSessionToDto(resp.Session, resp.UploadedChunks)); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// UploadChunk streams the raw request body as one chunk of the session.
+func (c *filesController) UploadChunk(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Upload a chunk")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	chunk, err := strconv.ParseInt(chi.URLParam(r, "chunk"), 10, 32)
+	if err != nil || chunk < 1 {
+		slog.Error("chunk must be a positive number")
+		http.Error(w, "chunk must be a positive number", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := c.service.UploadChunk(ctx, r.Body, userID, chi.URLParam(r, "sessionID"), int32(chunk))
+	if err != nil {
+		err = fmt.Errorf("failed to upload chunk: %w", err)
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(dto.UploadChunkResponse{
+		Success: resp.Success,
+		Chunk:   resp.Chunk,
+		Size:    resp.Size,
+	}); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *filesController) CompleteUpload(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Complete upload session")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	filePath, err := c.service.CompleteUploadSession(ctx, userID, chi.URLParam(r, "sessionID"))
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(dto.CompleteUploadResponse{Success: true, FilePath: filePath}); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *filesController) AbortUpload(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Abort upload session")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	ok, err := c.service.AbortUploadSession(ctx, userID, chi.URLParam(r, "sessionID"))
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(dto.AbortUploadResponse{Success: ok}); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func uploadSessionToDto(session *pb.UploadSession, uploadedChunks []int32) dto.UploadSession {
+	if uploadedChunks == nil {
+		uploadedChunks = []int32{}
+	}
+
+	return dto.UploadSession{
+		ID:             session.Id,
+		FilePath:       session.FilePath,
+		TotalSize:      session.TotalSize,
+		ChunkSize:      session.ChunkSize,
+		ChunkCount:     session.ChunkCount,
+		Checksum:       session.Checksum,
+		CreatedAt:      session.CreatedAt.AsTime(),
+		ExpiresAt:      session.ExpiresAt.AsTime(),
+		UploadedChunks: uploadedChunks,
+	}
+}
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/avran02/fileshare/gateway/internal/dto"
+	"github.com/avran02/fileshare/gateway/internal/middlaware"
+)
+
+func (c *filesController) GetUsage(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Get usage")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	usage, err := c.service.GetUsage(ctx, userID)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.UsageResponse{
+		UsedBytes:  usage.UsedBytes,
+		LimitBytes: usage.LimitBytes,
+		TrashBytes: usage.TrashBytes,
+		Folders:    make([]dto.FolderUsage, len(usage.Folders)),
+	}
+	for i, folder := range usage.Folders {
+		resp.Folders[i] = dto.FolderUsage{
+			Name:  folder.Name,
+			Bytes: folder.Bytes,
+		}
+	}
+
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -5,8 +5,12 @@ import (
 	"net/http"
 
 	"github.com/avran02/fileshare/gateway/internal/dto"
+	"github.com/avran02/fileshare/gateway/internal/middlaware"
 	"github.com/avran02/fileshare/gateway/internal/service"
 	pb "github.com/avran02/fileshare/proto/authpb"
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type UsersController interface {
@@ -15,6 +19,25 @@ type UsersController interface {
 	RefreshToken(w http.ResponseWriter, r *http.Request)
 	Logout(w http.ResponseWriter, r *http.Request)
 
+	ChangePassword(w http.ResponseWriter, r *http.Request)
+	RequestPasswordReset(w http.ResponseWriter, r *http.Request)
+	ResetPassword(w http.ResponseWriter, r *http.Request)
+
+	VerifyLoginTOTP(w http.ResponseWriter, r *http.Request)
+	BeginTOTPEnrollment(w http.ResponseWriter, r *http.Request)
+	ConfirmTOTPEnrollment(w http.ResponseWriter, r *http.Request)
+	DisableTOTP(w http.ResponseWriter, r *http.Request)
+
+	ListSessions(w http.ResponseWriter, r *http.Request)
+	RevokeSession(w http.ResponseWriter, r *http.Request)
+	RevokeOtherSessions(w http.ResponseWriter, r *http.Request)
+
+	CreatePersonalToken(w http.ResponseWriter, r *http.Request)
+	ListPersonalTokens(w http.ResponseWriter, r *http.Request)
+	RevokePersonalToken(w http.ResponseWriter, r *http.Request)
+
+	JWKS(w http.ResponseWriter, r *http.Request)
+
 	GetGrpcClient() pb.AuthServiceClient
 }
 
@@ -33,16 +56,19 @@ func (c *userController) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	accessToken, refreshToken, err := c.service.LoginUser(ctx, req.Username, req.Password)
+	login, err := c.service.LoginUser(ctx, req.Username, req.Password, r.UserAgent(), middlaware.ClientIP(r))
 	if err != nil {
 		slog.Error(err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), loginErrorStatus(err))
 		return
 	}
 
 	resp := dto.LoginUserResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		AccessToken:      login.AccessToken,
+		RefreshToken:     login.RefreshToken,
+		MFARequired:      login.MfaRequired,
+		MFASetupRequired: login.MfaSetupRequired,
+		ChallengeToken:   login.ChallengeToken,
 	}
 
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
@@ -66,7 +92,7 @@ func (c *userController) Register(w http.ResponseWriter, r *http.Request) {
 	ok, err := c.service.RegisterUser(ctx, req.Username, req.Password)
 	if err != nil {
 		slog.Error(err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), passwordErrorStatus(err))
 		return
 	}
 
@@ -92,7 +118,7 @@ func (c *userController) RefreshToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	accessToken, err := c.service.RefreshToken(ctx, req.RefreshToken)
+	accessToken, refreshToken, err := c.service.RefreshToken(ctx, req.RefreshToken)
 	if err != nil {
 		slog.Error(err.Error())
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -100,7 +126,8 @@ func (c *userController) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	}
 
 	resp := dto.RefreshTokenResponse{
-		AccessToken: accessToken,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
 	}
 
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
@@ -139,6 +166,125 @@ func (c *userController) Logout(w http.ResponseWriter, r *http.Request) { //noli
 	}
 }
 
+func (c *userController) ListSessions(w http.ResponseWriter, r *http.Request) {
+	slog.Info("List user sessions")
+	ctx := r.Context()
+	token := ctx.Value(middlaware.ContextTokenKey).(string)
+
+	sessions, err := c.service.ListSessions(ctx, token)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.ListSessionsResponse{
+		Sessions: make([]dto.SessionInfo, len(sessions)),
+	}
+	for i, session := range sessions {
+		resp.Sessions[i] = dto.SessionInfo{
+			ID:         session.Id,
+			UserAgent:  session.UserAgent,
+			IP:         session.Ip,
+			CreatedAt:  session.CreatedAt.AsTime(),
+			LastUsedAt: session.LastUsedAt.AsTime(),
+			Current:    session.Current,
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *userController) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Revoke a user session")
+	ctx := r.Context()
+	token := ctx.Value(middlaware.ContextTokenKey).(string)
+
+	ok, err := c.service.RevokeSession(ctx, token, chi.URLParam(r, "sessionID"))
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(dto.RevokeSessionResponse{Success: ok}); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *userController) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Revoke other user sessions")
+	ctx := r.Context()
+	token := ctx.Value(middlaware.ContextTokenKey).(string)
+
+	ok, err := c.service.RevokeOtherSessions(ctx, token)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(dto.RevokeSessionResponse{Success: ok}); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *userController) JWKS(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Get JWKS")
+	ctx := r.Context()
+
+	keys, err := c.service.GetJWKS(ctx)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.JWKSResponse{
+		Keys: make([]dto.JWK, len(keys)),
+	}
+	for i, key := range keys {
+		resp.Keys[i] = dto.JWK{
+			Kid: key.Kid,
+			Kty: key.Kty,
+			Alg: key.Alg,
+			Use: key.Use,
+			N:   key.N,
+			E:   key.E,
+			Crv: key.Crv,
+			X:   key.X,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// loginErrorStatus tells bad credentials and lockouts apart from other login failures.
+func loginErrorStatus(err error) int {
+	switch status.Code(err) {
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 func (c *userController) GetGrpcClient() pb.AuthServiceClient {
 	return c.service.GetGrpcClient()
 }
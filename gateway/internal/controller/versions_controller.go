@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/avran02/fileshare/gateway/internal/dto"
+	"github.com/avran02/fileshare/gateway/internal/middlaware"
+	pb "github.com/avran02/fileshare/proto/filespb"
+)
+
+func (c *filesController) Versions(w http.ResponseWriter, r *http.Request) {
+	slog.Info("List versions of a file")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+	filePath := r.URL.Query().Get("filePath")
+
+	if filePath == "" {
+		slog.Error("filePath is empty")
+		http.Error(w, "filePath is empty", http.StatusBadRequest)
+		return
+	}
+
+	versions, err := c.service.ListVersions(ctx, userID, filePath)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.ListVersionsResponse{
+		Versions: make([]dto.VersionInfo, len(versions)),
+	}
+	for i, version := range versions {
+		resp.Versions[i] = dto.VersionInfo{
+			VersionID:      version.VersionID,
+			Size:           version.Size,
+			LastModified:   version.LastModified.AsTime(),
+			IsLatest:       version.IsLatest,
+			IsDeleteMarker: version.IsDeleteMarker,
+		}
+	}
+
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *filesController) RestoreVersion(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Restore a version of a file")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	var req dto.VersionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.FilePath == "" || req.VersionID == "" {
+		slog.Error("filePath or versionID is empty")
+		http.Error(w, "filePath or versionID is empty", http.StatusBadRequest)
+		return
+	}
+
+	versionID, err := c.service.RestoreVersion(ctx, userID, req.FilePath, req.VersionID)
+	if err != nil {
+		err = fmt.Errorf("failed to restore version: %w", err)
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.RestoreVersionResponse{
+		Success:   true,
+		VersionID: versionID,
+	}
+
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *filesController) DeleteVersion(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Delete a version of a file")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	var req dto.VersionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.FilePath == "" || req.VersionID == "" {
+		slog.Error("filePath or versionID is empty")
+		http.Error(w, "filePath or versionID is empty", http.StatusBadRequest)
+		return
+	}
+
+	ok, err := c.service.DeleteVersion(ctx, userID, req.FilePath, req.VersionID)
+	if err != nil {
+		err = fmt.Errorf("failed to delete version: %w", err)
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(dto.DeleteVersionResponse{Success: ok}); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *filesController) GetRetention(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Get version retention policy")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	policy, err := c.service.GetRetentionPolicy(ctx, userID)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(retentionPolicyToDto(policy)); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (c *filesController) SetRetention(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Set version retention policy")
+	ctx := r.Context()
+	userID := ctx.Value(middlaware.ContextUserIDKey).(string)
+
+	var req dto.RetentionPolicy
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.KeepVersions < 0 || req.KeepDays < 0 {
+		slog.Error("keepVersions and keepDays can't be negative")
+		http.Error(w, "keepVersions and keepDays can't be negative", http.StatusBadRequest)
+		return
+	}
+
+	policy, err := c.service.SetRetentionPolicy(ctx, userID, req.KeepVersions, req.KeepDays)
+	if err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(retentionPolicyToDto(policy)); err != nil {
+		slog.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func retentionPolicyToDto(policy *pb.RetentionPolicy) dto.RetentionPolicy {
+	resp := dto.RetentionPolicy{
+		KeepVersions: policy.GetKeepVersions(),
+		KeepDays:     policy.GetKeepDays(),
+	}
+
+	if policy.GetUpdatedAt() != nil {
+		updatedAt := policy.UpdatedAt.AsTime()
+		resp.UpdatedAt = &updatedAt
+	}
+
+	return resp
+}
@@ -0,0 +1,52 @@
+package dto
+
+import "time"
+
+type UserInfo struct {
+	ID           string     `json:"id"`
+	Username     string     `json:"username"`
+	Role         string     `json:"role"`
+	Disabled     bool       `json:"disabled"`
+	TOTPEnabled  bool       `json:"totpEnabled"`
+	FailedLogins int32      `json:"failedLogins"`
+	LockedUntil  *time.Time `json:"lockedUntil,omitempty"`
+}
+
+type ListUsersResponse struct {
+	Users []UserInfo `json:"users"`
+	Total int32      `json:"total"`
+}
+
+type GetUserResponse struct {
+	User UserInfo `json:"user"`
+}
+
+type AdminActionResponse struct {
+	Success bool `json:"success"`
+}
+
+type SecuritySettings struct {
+	TOTPRequired bool `json:"totpRequired"`
+}
+
+type LoginLockout struct {
+	Scope        string     `json:"scope"`
+	Subject      string     `json:"subject"`
+	Failures     int32      `json:"failures"`
+	LastFailedAt time.Time  `json:"lastFailedAt"`
+	LockedUntil  *time.Time `json:"lockedUntil,omitempty"`
+}
+
+type ListLoginLockoutsResponse struct {
+	Lockouts []LoginLockout `json:"lockouts"`
+}
+
+type Quota struct {
+	LimitBytes int64      `json:"limitBytes"`
+	IsDefault  bool       `json:"isDefault"`
+	UpdatedAt  *time.Time `json:"updatedAt,omitempty"`
+}
+
+type SetQuotaRequest struct {
+	LimitBytes int64 `json:"limitBytes"`
+}
@@ -10,9 +10,10 @@ import (
 const maxFileSize = 1024 * 1024 * 1024
 
 type FileInfo struct {
-	Name         string    `json:"name"`
-	Size         int64     `json:"size"`
-	LastModified time.Time `json:"lastModified"`
+	Name         string     `json:"name"`
+	Type         string     `json:"type"`
+	Size         int64      `json:"size"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
 }
 
 type ListFilesResponse struct {
@@ -29,13 +30,18 @@ func NewUploadFileRequestFromHTTPForm(req *http.Request) (*UploadFileRequest, er
 		return nil, fmt.Errorf("failed to parse multipart form: %w", err)
 	}
 
-	file, _, err := req.FormFile("file")
+	file, header, err := req.FormFile("file")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get file: %w", err)
 	}
 
+	filePath := req.FormValue("filePath")
+	if filePath == "" {
+		filePath = header.Filename
+	}
+
 	return &UploadFileRequest{
-		FilePath: req.FormValue("filePath"),
+		FilePath: filePath,
 		File:     file,
 	}, nil
 }
@@ -53,5 +59,99 @@ type RemoveFileRequest struct {
 }
 
 type RemoveFileResponse struct {
+	Success     bool   `json:"success"`
+	TrashItemID string `json:"trashItemID,omitempty"`
+}
+
+type CreateDirectoryRequest struct {
+	FilePath string `json:"filePath"`
+}
+
+type CreateDirectoryResponse struct {
+	Success bool `json:"success"`
+}
+
+type RemoveDirectoryRequest struct {
+	FilePath  string `json:"filePath"`
+	Recursive bool   `json:"recursive"`
+}
+
+type RemoveDirectoryResponse struct {
+	Success     bool   `json:"success"`
+	Removed     int64  `json:"removed"`
+	TrashItemID string `json:"trashItemID,omitempty"`
+}
+
+type MoveFileRequest struct {
+	SrcPath   string `json:"srcPath"`
+	DstPath   string `json:"dstPath"`
+	Overwrite bool   `json:"overwrite"`
+}
+
+type MoveFileResponse struct {
+	Success bool     `json:"success"`
+	Moved   int64    `json:"moved"`
+	Failed  []string `json:"failed,omitempty"`
+}
+
+type CopyFileRequest struct {
+	ShareID   string `json:"shareID,omitempty"`
+	SrcPath   string `json:"srcPath"`
+	DstPath   string `json:"dstPath"`
+	Overwrite bool   `json:"overwrite"`
+}
+
+type CopyFileResponse struct {
+	Success bool     `json:"success"`
+	Copied  int64    `json:"copied"`
+	Failed  []string `json:"failed,omitempty"`
+}
+
+type VersionInfo struct {
+	VersionID      string    `json:"versionID"`
+	Size           int64     `json:"size"`
+	LastModified   time.Time `json:"lastModified"`
+	IsLatest       bool      `json:"isLatest"`
+	IsDeleteMarker bool      `json:"isDeleteMarker,omitempty"`
+}
+
+type ListVersionsResponse struct {
+	Versions []VersionInfo `json:"versions"`
+}
+
+type VersionRequest struct {
+	FilePath  string `json:"filePath"`
+	VersionID string `json:"versionID"`
+}
+
+type RestoreVersionResponse struct {
+	Success   bool   `json:"success"`
+	VersionID string `json:"versionID"`
+}
+
+type DeleteVersionResponse struct {
 	Success bool `json:"success"`
 }
+
+type RetentionPolicy struct {
+	KeepVersions int64      `json:"keepVersions"`
+	KeepDays     int64      `json:"keepDays"`
+	UpdatedAt    *time.Time `json:"updatedAt,omitempty"`
+}
+
+type FolderUsage struct {
+	Name  string `json:"name"`
+	Bytes int64  `json:"bytes"`
+}
+
+type UsageResponse struct {
+	UsedBytes  int64         `json:"usedBytes"`
+	LimitBytes int64         `json:"limitBytes"`
+	TrashBytes int64         `json:"trashBytes"`
+	Folders    []FolderUsage `json:"folders"`
+}
+
+type ArchiveRequest struct {
+	Paths  []string `json:"paths"`
+	Format string   `json:"format,omitempty"`
+}
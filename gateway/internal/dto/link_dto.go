@@ -0,0 +1,45 @@
+package dto
+
+import "time"
+
+type CreateLinkRequest struct {
+	Type         string     `json:"type"`
+	FilePath     string     `json:"filePath"`
+	ExpiresAt    *time.Time `json:"expiresAt"`
+	MaxDownloads int64      `json:"maxDownloads"`
+	MaxFiles     int64      `json:"maxFiles"`
+	MaxBytes     int64      `json:"maxBytes"`
+	Password     string     `json:"password"`
+}
+
+type LinkInfo struct {
+	ID            string     `json:"id"`
+	Type          string     `json:"type"`
+	URL           string     `json:"url"`
+	FilePath      string     `json:"filePath"`
+	ExpiresAt     *time.Time `json:"expiresAt"`
+	MaxDownloads  int64      `json:"maxDownloads"`
+	Downloads     int64      `json:"downloads"`
+	MaxFiles      int64      `json:"maxFiles"`
+	Files         int64      `json:"files"`
+	MaxBytes      int64      `json:"maxBytes"`
+	UploadedBytes int64      `json:"uploadedBytes"`
+	HasPassword   bool       `json:"hasPassword"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+type CreateLinkResponse struct {
+	Link LinkInfo `json:"link"`
+}
+
+type RevokeLinkResponse struct {
+	Success bool `json:"success"`
+}
+
+type ListLinksResponse struct {
+	Links []LinkInfo `json:"links"`
+}
+
+type DropUploadResponse struct {
+	FilePath string `json:"filePath"`
+}
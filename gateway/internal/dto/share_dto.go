@@ -0,0 +1,45 @@
+package dto
+
+import "time"
+
+type ShareRequest struct {
+	FilePath   string `json:"filePath"`
+	Username   string `json:"username"`
+	Permission string `json:"permission"`
+}
+
+type ShareInfo struct {
+	ID         string    `json:"id"`
+	Username   string    `json:"username"`
+	FilePath   string    `json:"filePath"`
+	Permission string    `json:"permission"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type ShareResponse struct {
+	Share ShareInfo `json:"share"`
+}
+
+type UnshareRequest struct {
+	ShareID string `json:"shareID"`
+}
+
+type UnshareResponse struct {
+	Success bool `json:"success"`
+}
+
+type ListSharesResponse struct {
+	Shares []ShareInfo `json:"shares"`
+}
+
+type IncomingShareInfo struct {
+	ID         string    `json:"id"`
+	Owner      string    `json:"owner"`
+	FilePath   string    `json:"filePath"`
+	Permission string    `json:"permission"`
+	SharedAt   time.Time `json:"sharedAt"`
+}
+
+type ListIncomingSharesResponse struct {
+	Shares []IncomingShareInfo `json:"shares"`
+}
@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+type TrashItem struct {
+	ID        string    `json:"id"`
+	FilePath  string    `json:"filePath"`
+	IsDir     bool      `json:"isDir"`
+	Size      int64     `json:"size"`
+	DeletedAt time.Time `json:"deletedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type ListTrashResponse struct {
+	Items []TrashItem `json:"items"`
+}
+
+type RestoreTrashRequest struct {
+	ItemID   string `json:"itemID"`
+	FilePath string `json:"filePath,omitempty"`
+	Conflict string `json:"conflict,omitempty"`
+}
+
+type RestoreTrashResponse struct {
+	Success  bool     `json:"success"`
+	FilePath string   `json:"filePath"`
+	Restored int64    `json:"restored"`
+	Failed   []string `json:"failed,omitempty"`
+}
+
+type EmptyTrashResponse struct {
+	Success bool  `json:"success"`
+	Removed int64 `json:"removed"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+type CreateUploadRequest struct {
+	FilePath  string `json:"filePath"`
+	TotalSize int64  `json:"totalSize"`
+	Checksum  string `json:"checksum,omitempty"`
+}
+
+type UploadSession struct {
+	ID             string    `json:"id"`
+	FilePath       string    `json:"filePath"`
+	TotalSize      int64     `json:"totalSize"`
+	ChunkSize      int64     `json:"chunkSize"`
+	ChunkCount     int32     `json:"chunkCount"`
+	Checksum       string    `json:"checksum,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+	UploadedChunks []int32   `json:"uploadedChunks"`
+}
+
+type UploadChunkResponse struct {
+	Success bool  `json:"success"`
+	Chunk   int32 `json:"chunk"`
+	Size    int64 `json:"size"`
+}
+
+type CompleteUploadResponse struct {
+	Success  bool   `json:"success"`
+	FilePath string `json:"filePath"`
+}
+
+type AbortUploadResponse struct {
+	Success bool `json:"success"`
+}
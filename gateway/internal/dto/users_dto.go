@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type RegisterUserRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
@@ -14,9 +16,63 @@ type LoginUserRequest struct {
 	Password string `json:"password"`
 }
 
+// LoginUserResponse carries either the token pair or, when a second factor is
+// needed, the challenge token to finish the login with.
 type LoginUserResponse struct {
-	AccessToken  string `json:"accessToken"`
-	RefreshToken string `json:"refreshToken"`
+	AccessToken      string `json:"accessToken,omitempty"`
+	RefreshToken     string `json:"refreshToken,omitempty"`
+	MFARequired      bool   `json:"mfaRequired,omitempty"`
+	MFASetupRequired bool   `json:"mfaSetupRequired,omitempty"`
+	ChallengeToken   string `json:"challengeToken,omitempty"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+type RequestPasswordResetRequest struct {
+	Username string `json:"username"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"newPassword"`
+}
+
+type PasswordResponse struct {
+	Success bool `json:"success"`
+}
+
+type VerifyLoginTOTPRequest struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"`
+}
+
+type TOTPEnrollmentRequest struct {
+	// ChallengeToken is set when enrolling during a login that requires 2FA,
+	// otherwise the access token from the Authorization header is used.
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"`
+}
+
+type BeginTOTPEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+type ConfirmTOTPEnrollmentResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+	AccessToken   string   `json:"accessToken,omitempty"`
+	RefreshToken  string   `json:"refreshToken,omitempty"`
+}
+
+type DisableTOTPRequest struct {
+	Code string `json:"code"`
+}
+
+type DisableTOTPResponse struct {
+	Success bool `json:"success"`
 }
 
 type RefreshTokenRequest struct {
@@ -24,7 +80,8 @@ type RefreshTokenRequest struct {
 }
 
 type RefreshTokenResponse struct {
-	AccessToken string `json:"accessToken"`
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
 }
 
 type LogoutRequest struct {
@@ -34,3 +91,64 @@ type LogoutRequest struct {
 type LogoutResponse struct {
 	Success bool `json:"success"`
 }
+
+type SessionInfo struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"userAgent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	Current    bool      `json:"current"`
+}
+
+type ListSessionsResponse struct {
+	Sessions []SessionInfo `json:"sessions"`
+}
+
+type RevokeSessionResponse struct {
+	Success bool `json:"success"`
+}
+
+type JWK struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSResponse struct {
+	Keys []JWK `json:"keys"`
+}
+
+type CreatePersonalTokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int32    `json:"expiresInDays"`
+}
+
+type PersonalTokenInfo struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
+
+type CreatePersonalTokenResponse struct {
+	Token string            `json:"token"`
+	Info  PersonalTokenInfo `json:"info"`
+}
+
+type ListPersonalTokensResponse struct {
+	Tokens []PersonalTokenInfo `json:"tokens"`
+}
+
+type RevokePersonalTokenResponse struct {
+	Success bool `json:"success"`
+}
@@ -2,16 +2,26 @@ package middlaware
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/avran02/fileshare/gateway/internal/pkg/verifier"
 	pb "github.com/avran02/fileshare/proto/authpb"
 )
 
-const ContextUserIDKey = "userID"
+const (
+	ContextUserIDKey   = "userID"
+	ContextUserRoleKey = "userRole"
+	ContextTokenKey    = "accessToken"
+	ContextScopesKey   = "scopes"
+)
 
-func GetAuthMiddleware(authClient pb.AuthServiceClient) func(http.Handler) http.Handler {
+// GetAuthMiddleware verifies access tokens locally when tokenVerifier is up to date
+// and asks the auth service otherwise.
+func GetAuthMiddleware(authClient pb.AuthServiceClient, tokenVerifier verifier.Verifier) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -26,19 +36,46 @@ func GetAuthMiddleware(authClient pb.AuthServiceClient) func(http.Handler) http.
 				return
 			}
 
-			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-			defer cancel()
+			var userID, role string
+			var scopes []string
+			var err error
 
-			req := &pb.ValidateTokenRequest{AccessToken: token}
-			resp, err := authClient.ValidateToken(ctx, req)
+			// personal access tokens are opaque, only the auth service can check them
+			if strings.HasPrefix(token, pb.PersonalTokenPrefix) {
+				userID, role, scopes, err = validateRemotely(authClient, token)
+				if err == nil && scopes == nil {
+					scopes = []string{}
+				}
+			} else {
+				userID, role, err = tokenVerifier.Verify(token)
+				if errors.Is(err, verifier.ErrCannotVerify) {
+					slog.Debug(err.Error())
+					userID, role, _, err = validateRemotely(authClient, token)
+				}
+			}
 			if err != nil {
 				http.Error(w, "Invalid token", http.StatusUnauthorized)
 				return
 			}
 
-			// Store userID in context for future handlers
-			ctx = context.WithValue(r.Context(), ContextUserIDKey, resp.Id)
+			// Store userID, role, scopes and token in context for future handlers
+			ctx := context.WithValue(r.Context(), ContextUserIDKey, userID)
+			ctx = context.WithValue(ctx, ContextUserRoleKey, role)
+			ctx = context.WithValue(ctx, ContextScopesKey, scopes)
+			ctx = context.WithValue(ctx, ContextTokenKey, token)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+func validateRemotely(authClient pb.AuthServiceClient, token string) (userID, role string, scopes []string, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	resp, err := authClient.ValidateToken(ctx, &pb.ValidateTokenRequest{AccessToken: token})
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return resp.Id, resp.Role, resp.Scopes, nil
+}
@@ -0,0 +1,124 @@
+package middlaware
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const (
+	ContextClientIPKey = "clientIP"
+
+	// ClientIPMetadataKey passes the client IP on to the auth service, which
+	// throttles failed logins per IP.
+	ClientIPMetadataKey = "x-client-ip"
+)
+
+// GetClientIPMiddleware stores the client IP in the request context, so gRPC
+// calls made for the request can forward it. X-Forwarded-For and X-Real-IP are
+// only honoured when the request comes from one of trustedProxies, given as
+// CIDRs or single addresses; anyone else could set them to dodge throttling.
+func GetClientIPMiddleware(trustedProxies []string) func(http.Handler) http.Handler {
+	proxies := make([]*net.IPNet, 0, len(trustedProxies))
+	for _, proxy := range trustedProxies {
+		network, err := parseProxy(proxy)
+		if err != nil {
+			log.Fatal("invalid trusted proxy:\n", err)
+		}
+		proxies = append(proxies, network)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), ContextClientIPKey, clientIP(r, proxies))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ClientIP returns the client IP resolved by the client IP middleware.
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(ContextClientIPKey).(string); ok {
+		return ip
+	}
+
+	return remoteIP(r)
+}
+
+func clientIP(r *http.Request, proxies []*net.IPNet) string {
+	ip := remoteIP(r)
+	if !isTrusted(ip, proxies) {
+		return ip
+	}
+
+	// walk the chain from the nearest hop, the first address not belonging
+	// to a trusted proxy is the client
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+
+			ip = hop
+			if !isTrusted(hop, proxies) {
+				break
+			}
+		}
+
+		return ip
+	}
+
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+
+	return ip
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func isTrusted(ip string, proxies []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, proxy := range proxies {
+		if proxy.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseProxy(proxy string) (*net.IPNet, error) {
+	if !strings.Contains(proxy, "/") {
+		ip := net.ParseIP(proxy)
+		if ip == nil {
+			return nil, &net.ParseError{Type: "IP address", Text: proxy}
+		}
+
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = 8 * net.IPv4len
+		}
+
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+
+	_, network, err := net.ParseCIDR(proxy)
+	return network, err
+}
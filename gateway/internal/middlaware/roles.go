@@ -0,0 +1,22 @@
+package middlaware
+
+import (
+	"net/http"
+	"slices"
+)
+
+// GetRoleMiddleware only lets through users having one of the roles. It must
+// run after the auth middleware, which puts the role into the context.
+func GetRoleMiddleware(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, ok := r.Context().Value(ContextUserRoleKey).(string)
+			if !ok || !slices.Contains(roles, role) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
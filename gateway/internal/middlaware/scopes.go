@@ -0,0 +1,37 @@
+package middlaware
+
+import (
+	"net/http"
+	"slices"
+)
+
+// GetScopeMiddleware only lets through requests whose token grants the scope.
+// Session tokens carry no scopes in the context and grant everything.
+func GetScopeMiddleware(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scopes, _ := r.Context().Value(ContextScopesKey).([]string)
+			if scopes != nil && !slices.Contains(scopes, scope) {
+				http.Error(w, "Token lacks scope "+scope, http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// GetSessionOnlyMiddleware turns away personal access tokens, whatever their
+// scopes, from sessions, tokens and admin actions.
+func GetSessionOnlyMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if scopes, _ := r.Context().Value(ContextScopesKey).([]string); scopes != nil {
+				http.Error(w, "Personal access tokens can't be used here", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package verifier
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	pb "github.com/avran02/fileshare/proto/authpb"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	accessTokenType = "access"
+
+	// auth sends a heartbeat every second, so missing a few means the stream is broken
+	staleAfter        = 5 * time.Second
+	reconnectDelay    = time.Second
+	keysRefreshPeriod = 5 * time.Minute
+	minKeysRefresh    = 10 * time.Second
+	requestTimeout    = time.Second
+	// auth bumps a session's last use at most once a minute anyway
+	touchInterval = time.Minute
+)
+
+var (
+	// ErrCannotVerify means the token has to be checked by the auth service instead.
+	ErrCannotVerify   = errors.New("token can't be verified locally")
+	ErrInvalidToken   = errors.New("invalid token")
+	ErrRevokedToken   = errors.New("token session was revoked")
+	ErrUnsupportedKey = errors.New("unsupported key")
+)
+
+// Verifier checks access tokens against the auth service's published keys and
+// the revocations it streams, without a round trip per request.
+type Verifier interface {
+	Verify(token string) (userID, role string, err error)
+	Run(ctx context.Context)
+}
+
+type claims struct {
+	Type      string `json:"type"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+type verifier struct {
+	client pb.AuthServiceClient
+
+	mu             sync.RWMutex
+	keys           map[string]crypto.PublicKey
+	keysFetchedAt  time.Time
+	revoked        map[string]time.Time
+	touched        map[string]time.Time
+	lastID         int64
+	lastHeartbeat  time.Time
+	lastPrunedAt   time.Time
+	keysRefreshing sync.Mutex
+}
+
+func (v *verifier) Verify(token string) (userID, role string, err error) {
+	if !v.fresh() {
+		return "", "", fmt.Errorf("%w: revocation list is stale", ErrCannotVerify)
+	}
+
+	methods := []string{
+		jwt.SigningMethodRS256.Alg(),
+		jwt.SigningMethodEdDSA.Alg(),
+	}
+
+	parsedToken, err := jwt.ParseWithClaims(token, &claims{}, v.key, jwt.WithValidMethods(methods), jwt.WithExpirationRequired())
+	if err != nil {
+		if errors.Is(err, ErrCannotVerify) {
+			return "", "", err
+		}
+		return "", "", fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	c, ok := parsedToken.Claims.(*claims)
+	if !ok || !parsedToken.Valid || c.Type != accessTokenType {
+		return "", "", ErrInvalidToken
+	}
+
+	if c.SessionID == "" {
+		return "", "", fmt.Errorf("%w: token has no session", ErrCannotVerify)
+	}
+
+	v.mu.RLock()
+	_, revoked := v.revoked[c.SessionID]
+	v.mu.RUnlock()
+
+	if revoked {
+		return "", "", ErrRevokedToken
+	}
+
+	v.touch(c.SessionID, token)
+
+	return c.Subject, c.Role, nil
+}
+
+// touch has the auth service validate the token in the background, which bumps
+// the last use of its session, as locally verified requests never reach auth.
+func (v *verifier) touch(sessionID, token string) {
+	v.mu.Lock()
+	if time.Since(v.touched[sessionID]) < touchInterval {
+		v.mu.Unlock()
+		return
+	}
+	v.touched[sessionID] = time.Now()
+	v.mu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		defer cancel()
+
+		if _, err := v.client.ValidateToken(ctx, &pb.ValidateTokenRequest{AccessToken: token}); err != nil {
+			slog.Debug("failed to touch session " + sessionID + ": " + err.Error())
+		}
+	}()
+}
+
+func (v *verifier) key(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		return nil, fmt.Errorf("%w: token has no key id", ErrCannotVerify)
+	}
+
+	key, ok := v.lookupKey(kid)
+	if !ok {
+		if err := v.refreshKeys(minKeysRefresh); err != nil {
+			slog.Error(err.Error())
+		}
+
+		key, ok = v.lookupKey(kid)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown key %s", ErrCannotVerify, kid)
+		}
+	}
+
+	return key, nil
+}
+
+func (v *verifier) lookupKey(kid string) (crypto.PublicKey, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+// refreshKeys fetches the JWKS unless it was fetched less than minAge ago.
+func (v *verifier) refreshKeys(minAge time.Duration) error {
+	v.keysRefreshing.Lock()
+	defer v.keysRefreshing.Unlock()
+
+	v.mu.RLock()
+	recent := time.Since(v.keysFetchedAt) < minAge
+	v.mu.RUnlock()
+	if recent {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	resp, err := v.client.GetJWKS(ctx, &pb.GetJWKSRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to get jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(resp.Keys))
+	for _, jwk := range resp.Keys {
+		key, err := parseJWK(jwk)
+		if err != nil {
+			slog.Error(err.Error())
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.keysFetchedAt = time.Now()
+	v.mu.Unlock()
+
+	return nil
+}
+
+func (v *verifier) fresh() bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return time.Since(v.lastHeartbeat) < staleAfter
+}
+
+// Run keeps the signing keys and the revocation list up to date until ctx is done.
+func (v *verifier) Run(ctx context.Context) {
+	go v.runKeysRefresh(ctx)
+
+	for {
+		if err := v.watchRevocations(ctx); err != nil {
+			slog.Error("revocation stream broke: " + err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+func (v *verifier) runKeysRefresh(ctx context.Context) {
+	if err := v.refreshKeys(0); err != nil {
+		slog.Error(err.Error())
+	}
+
+	ticker := time.NewTicker(keysRefreshPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := v.refreshKeys(0); err != nil {
+			slog.Error(err.Error())
+		}
+	}
+}
+
+func (v *verifier) watchRevocations(ctx context.Context) error {
+	v.mu.RLock()
+	afterID := v.lastID
+	v.mu.RUnlock()
+
+	stream, err := v.client.WatchRevocations(ctx, &pb.WatchRevocationsRequest{AfterID: afterID})
+	if err != nil {
+		return fmt.Errorf("failed to watch revocations: %w", err)
+	}
+
+	for {
+		revocation, err := stream.Recv()
+		if err != nil {
+			return fmt.Errorf("failed to receive revocation: %w", err)
+		}
+
+		v.apply(revocation)
+	}
+}
+
+func (v *verifier) apply(revocation *pb.Revocation) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := time.Now()
+	if revocation.Heartbeat {
+		v.lastHeartbeat = now
+		if now.Sub(v.lastPrunedAt) >= time.Minute {
+			for sessionID, expiresAt := range v.revoked {
+				if now.After(expiresAt) {
+					delete(v.revoked, sessionID)
+				}
+			}
+			for sessionID, touchedAt := range v.touched {
+				if now.Sub(touchedAt) >= touchInterval {
+					delete(v.touched, sessionID)
+				}
+			}
+			v.lastPrunedAt = now
+		}
+		return
+	}
+
+	v.revoked[revocation.SessionID] = revocation.ExpiresAt.AsTime()
+	v.lastID = max(v.lastID, revocation.Id)
+}
+
+func New(client pb.AuthServiceClient) Verifier {
+	return &verifier{
+		client:  client,
+		keys:    make(map[string]crypto.PublicKey),
+		revoked: make(map[string]time.Time),
+		touched: make(map[string]time.Time),
+	}
+}
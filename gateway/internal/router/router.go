@@ -7,6 +7,8 @@ import (
 
 	"github.com/avran02/fileshare/gateway/internal/controller"
 	customMiddleware "github.com/avran02/fileshare/gateway/internal/middlaware"
+	"github.com/avran02/fileshare/gateway/internal/pkg/verifier"
+	authpb "github.com/avran02/fileshare/proto/authpb"
 
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/chi/v5"
@@ -15,6 +17,7 @@ import (
 type Router struct {
 	chi.Router
 	controllers *controller.Controllers
+	verifier    verifier.Verifier
 }
 
 func (router *Router) getUserRoutes() chi.Router {
@@ -24,42 +27,168 @@ func (router *Router) getUserRoutes() chi.Router {
 	r.Post("/refresh-token", router.controllers.UsersController.RefreshToken)
 	r.Post("/logout", router.controllers.UsersController.Logout)
 
+	r.Post("/password/forgot", router.controllers.UsersController.RequestPasswordReset)
+	r.Post("/password/reset", router.controllers.UsersController.ResetPassword)
+
+	r.Post("/2fa/login", router.controllers.UsersController.VerifyLoginTOTP)
+	r.Post("/2fa/setup", router.controllers.UsersController.BeginTOTPEnrollment)
+	r.Post("/2fa/confirm", router.controllers.UsersController.ConfirmTOTPEnrollment)
+
+	r.Group(func(r chi.Router) {
+		r.Use(customMiddleware.GetAuthMiddleware(router.controllers.UsersController.GetGrpcClient(), router.verifier))
+		r.Use(customMiddleware.GetSessionOnlyMiddleware())
+		r.Get("/sessions", router.controllers.UsersController.ListSessions)
+		r.Delete("/sessions/{sessionID}", router.controllers.UsersController.RevokeSession)
+		r.Delete("/sessions", router.controllers.UsersController.RevokeOtherSessions)
+
+		r.Post("/2fa/disable", router.controllers.UsersController.DisableTOTP)
+		r.Post("/password", router.controllers.UsersController.ChangePassword)
+
+		r.Post("/tokens", router.controllers.UsersController.CreatePersonalToken)
+		r.Get("/tokens", router.controllers.UsersController.ListPersonalTokens)
+		r.Delete("/tokens/{tokenID}", router.controllers.UsersController.RevokePersonalToken)
+	})
+
 	return r
 }
 
 func (router *Router) getFilesRoutes() chi.Router {
 	r := chi.NewRouter()
-	authMiddlaware := customMiddleware.GetAuthMiddleware(router.controllers.UsersController.GetGrpcClient())
-	r.Use(authMiddlaware)
+	authMiddlaware := customMiddleware.GetAuthMiddleware(router.controllers.UsersController.GetGrpcClient(), router.verifier)
+	read := customMiddleware.GetScopeMiddleware(authpb.ScopeFilesRead)
+	write := customMiddleware.GetScopeMiddleware(authpb.ScopeFilesWrite)
+
+	r.Route("/tus", func(r chi.Router) {
+		// tus clients discover the server before they send any credentials
+		r.Options("/", router.controllers.TusController.Options)
+		r.Group(func(r chi.Router) {
+			r.Use(authMiddlaware, write)
+			r.Post("/", router.controllers.TusController.Create)
+			r.Head("/{uploadID}", router.controllers.TusController.Head)
+			r.Patch("/{uploadID}", router.controllers.TusController.Patch)
+			r.Delete("/{uploadID}", router.controllers.TusController.Delete)
+		})
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(authMiddlaware)
+
+		r.With(write).Post("/upload", router.controllers.FilesController.Upload)
+		r.With(write).Post("/uploads", router.controllers.FilesController.CreateUpload)
+		r.With(write).Get("/uploads/{sessionID}", router.controllers.FilesController.GetUpload)
+		r.With(write).Put("/uploads/{sessionID}/chunks/{chunk}", router.controllers.FilesController.UploadChunk)
+		r.With(write).Post("/uploads/{sessionID}/complete", router.controllers.FilesController.CompleteUpload)
+		r.With(write).Delete("/uploads/{sessionID}", router.controllers.FilesController.AbortUpload)
+		r.With(read).Get("/download", router.controllers.FilesController.Download)
+		r.With(write).Delete("/rm", router.controllers.FilesController.Rm)
+		r.With(read).Get("/ls", router.controllers.FilesController.Ls)
+		r.With(write).Post("/mkdir", router.controllers.FilesController.Mkdir)
+		r.With(write).Delete("/rmdir", router.controllers.FilesController.Rmdir)
+		r.With(write).Post("/mv", router.controllers.FilesController.Mv)
+		r.With(write).Post("/cp", router.controllers.FilesController.Cp)
+		r.With(read).Get("/versions", router.controllers.FilesController.Versions)
+		r.With(write).Post("/versions/restore", router.controllers.FilesController.RestoreVersion)
+		r.With(write).Delete("/versions", router.controllers.FilesController.DeleteVersion)
+		r.With(read).Get("/retention", router.controllers.FilesController.GetRetention)
+		r.With(write).Put("/retention", router.controllers.FilesController.SetRetention)
+		r.With(read).Get("/archive", router.controllers.ArchiveController.Archive)
+		r.With(read).Post("/archive", router.controllers.ArchiveController.ArchiveSelection)
+		r.With(read).Get("/usage", router.controllers.FilesController.GetUsage)
+		r.With(read).Get("/trash", router.controllers.FilesController.ListTrash)
+		r.With(write).Post("/trash/restore", router.controllers.FilesController.RestoreTrash)
+		r.With(write).Delete("/trash", router.controllers.FilesController.EmptyTrash)
+		r.With(write).Delete("/trash/{itemID}", router.controllers.FilesController.EmptyTrash)
+	})
 
-	r.Post("/upload", router.controllers.FilesController.Upload)
-	r.Get("/download", router.controllers.FilesController.Download)
-	r.Delete("/rm", router.controllers.FilesController.Rm)
-	r.Get("/ls", router.controllers.FilesController.Ls)
 	return r
 }
 
 func (router *Router) getShareRoutes() chi.Router {
 	r := chi.NewRouter()
-	r.Post("/share", router.controllers.ShareController.Share)
-	r.Delete("/unshare", router.controllers.ShareController.Unshare)
+	authMiddlaware := customMiddleware.GetAuthMiddleware(router.controllers.UsersController.GetGrpcClient(), router.verifier)
+	r.Use(authMiddlaware)
+	read := customMiddleware.GetScopeMiddleware(authpb.ScopeFilesRead)
+	manage := customMiddleware.GetScopeMiddleware(authpb.ScopeShareManage)
+
+	r.With(manage).Post("/share", router.controllers.ShareController.Share)
+	r.With(manage).Delete("/unshare", router.controllers.ShareController.Unshare)
+	r.With(manage).Get("/outgoing", router.controllers.ShareController.ListOutgoing)
+	r.With(read).Get("/incoming", router.controllers.ShareController.ListIncoming)
+	r.With(read).Get("/incoming/{shareID}/ls", router.controllers.ShareController.LsShared)
+	r.With(read).Get("/incoming/{shareID}/download", router.controllers.ShareController.DownloadShared)
 
 	return r
 }
 
-func New(controllers controller.Controllers) *Router {
+func (router *Router) getLinkRoutes() chi.Router {
+	r := chi.NewRouter()
+	authMiddlaware := customMiddleware.GetAuthMiddleware(router.controllers.UsersController.GetGrpcClient(), router.verifier)
+	r.Use(authMiddlaware)
+	r.Use(customMiddleware.GetScopeMiddleware(authpb.ScopeShareManage))
+
+	r.Post("/", router.controllers.LinkController.Create)
+	r.Get("/", router.controllers.LinkController.List)
+	r.Delete("/{linkID}", router.controllers.LinkController.Revoke)
+
+	return r
+}
+
+func (router *Router) getPublicRoutes() chi.Router {
+	r := chi.NewRouter()
+	r.Get("/{token}", router.controllers.LinkController.PublicDownload)
+	r.Get("/{token}/ls", router.controllers.LinkController.PublicLs)
+	r.Post("/{token}/upload", router.controllers.LinkController.PublicUpload)
+
+	return r
+}
+
+func (router *Router) getAdminRoutes() chi.Router {
+	r := chi.NewRouter()
+	authMiddlaware := customMiddleware.GetAuthMiddleware(router.controllers.UsersController.GetGrpcClient(), router.verifier)
+	r.Use(authMiddlaware)
+	r.Use(customMiddleware.GetRoleMiddleware(authpb.RoleAdmin))
+	r.Use(customMiddleware.GetSessionOnlyMiddleware())
+
+	r.Get("/users", router.controllers.AdminController.ListUsers)
+	r.Get("/users/{userID}", router.controllers.AdminController.GetUser)
+	r.Post("/users/{userID}/disable", router.controllers.AdminController.DisableUser)
+	r.Post("/users/{userID}/enable", router.controllers.AdminController.EnableUser)
+	r.Post("/users/{userID}/logout", router.controllers.AdminController.ForceLogout)
+	r.Delete("/users/{userID}", router.controllers.AdminController.DeleteUser)
+
+	r.Get("/settings", router.controllers.AdminController.GetSettings)
+	r.Put("/settings", router.controllers.AdminController.UpdateSettings)
+
+	r.Get("/lockouts", router.controllers.AdminController.ListLockouts)
+	r.Delete("/lockouts/{scope}/{subject}", router.controllers.AdminController.ClearLockout)
+
+	r.Get("/users/{userID}/quota", router.controllers.AdminController.GetQuota)
+	r.Put("/users/{userID}/quota", router.controllers.AdminController.SetQuota)
+	r.Delete("/users/{userID}/quota", router.controllers.AdminController.ResetQuota)
+
+	return r
+}
+
+func New(controllers controller.Controllers, tokenVerifier verifier.Verifier, trustedProxies []string) *Router {
 	router := &Router{
 		controllers: &controllers,
+		verifier:    tokenVerifier,
 		Router:      chi.NewRouter(),
 	}
 
 	router.Router.Use(middleware.Recoverer)
 	router.Router.Use(middleware.Logger)
+	router.Router.Use(customMiddleware.GetClientIPMiddleware(trustedProxies))
+
+	router.Router.Get("/.well-known/jwks.json", router.controllers.UsersController.JWKS)
 
 	router.Router.Route("/api/v1", func(r chi.Router) {
 		r.Mount("/user", router.getUserRoutes())
 		r.Mount("/files", router.getFilesRoutes())
 		r.Mount("/share", router.getShareRoutes())
+		r.Mount("/links", router.getLinkRoutes())
+		r.Mount("/public", router.getPublicRoutes())
+		r.Mount("/admin", router.getAdminRoutes())
 	})
 
 	printRoutes(router.Router)
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	authpb "github.com/avran02/fileshare/proto/authpb"
+	filespb "github.com/avran02/fileshare/proto/filespb"
+)
+
+type AdminService interface {
+	ListUsers(ctx context.Context, accessToken, search string, page, pageSize int32) ([]*authpb.UserInfo, int32, error)
+	GetUser(ctx context.Context, accessToken, userID string) (*authpb.UserInfo, error)
+	DisableUser(ctx context.Context, accessToken, userID string) (bool, error)
+	EnableUser(ctx context.Context, accessToken, userID string) (bool, error)
+	DeleteUser(ctx context.Context, accessToken, userID string) (bool, error)
+	ForceLogout(ctx context.Context, accessToken, userID string) (bool, error)
+
+	GetSecuritySettings(ctx context.Context, accessToken string) (*authpb.GetSecuritySettingsResponse, error)
+	UpdateSecuritySettings(ctx context.Context, accessToken string, totpRequired bool) (bool, error)
+	ListLoginLockouts(ctx context.Context, accessToken string) ([]*authpb.LoginLockout, error)
+	ClearLoginLockout(ctx context.Context, accessToken, scope, subject string) (bool, error)
+
+	GetQuota(ctx context.Context, userID string) (*filespb.Quota, error)
+	SetQuota(ctx context.Context, userID string, limitBytes int64, useDefault bool) (*filespb.Quota, error)
+}
+
+type adminService struct {
+	authServiceClient authpb.AuthServiceClient
+	filesServerClient filespb.FileServiceClient
+}
+
+func (s *adminService) ListUsers(ctx context.Context, accessToken, search string, page, pageSize int32) ([]*authpb.UserInfo, int32, error) {
+	resp, err := s.authServiceClient.ListUsers(ctx, &authpb.ListUsersRequest{
+		AccessToken: accessToken,
+		Search:      search,
+		Page:        page,
+		PageSize:    pageSize,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return resp.Users, resp.Total, nil
+}
+
+func (s *adminService) GetUser(ctx context.Context, accessToken, userID string) (*authpb.UserInfo, error) {
+	resp, err := s.authServiceClient.GetUser(ctx, &authpb.GetUserRequest{
+		AccessToken: accessToken,
+		Id:          userID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return resp.User, nil
+}
+
+func (s *adminService) DisableUser(ctx context.Context, accessToken, userID string) (bool, error) {
+	resp, err := s.authServiceClient.DisableUser(ctx, &authpb.DisableUserRequest{
+		AccessToken: accessToken,
+		Id:          userID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return false, fmt.Errorf("failed to disable user: %w", err)
+	}
+
+	return resp.Success, nil
+}
+
+func (s *adminService) EnableUser(ctx context.Context, accessToken, userID string) (bool, error) {
+	resp, err := s.authServiceClient.EnableUser(ctx, &authpb.EnableUserRequest{
+		AccessToken: accessToken,
+		Id:          userID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return false, fmt.Errorf("failed to enable user: %w", err)
+	}
+
+	return resp.Success, nil
+}
+
+// DeleteUser disables the account, removes everything the user stored and
+// only then deletes the account, so a failure halfway leaves a locked account
+// the admin can delete again instead of orphaned files.
+func (s *adminService) DeleteUser(ctx context.Context, accessToken, userID string) (bool, error) {
+	if _, err := s.authServiceClient.DisableUser(ctx, &authpb.DisableUserRequest{
+		AccessToken: accessToken,
+		Id:          userID,
+	}); err != nil {
+		slog.Error(err.Error())
+		return false, fmt.Errorf("failed to disable user: %w", err)
+	}
+
+	filesResp, err := s.filesServerClient.DeleteUser(ctx, &filespb.DeleteUserRequest{
+		UserID: userID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return false, fmt.Errorf("failed to remove user storage: %w", err)
+	}
+
+	resp, err := s.authServiceClient.DeleteUser(ctx, &authpb.DeleteUserRequest{
+		AccessToken: accessToken,
+		Id:          userID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return false, fmt.Errorf("storage removed, but failed to delete user: %w", err)
+	}
+
+	return resp.Success && filesResp.Success, nil
+}
+
+func (s *adminService) GetQuota(ctx context.Context, userID string) (*filespb.Quota, error) {
+	resp, err := s.filesServerClient.GetQuota(ctx, &filespb.GetQuotaRequest{
+		UserID: userID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to get quota: %w", err)
+	}
+
+	return resp.Quota, nil
+}
+
+func (s *adminService) SetQuota(ctx context.Context, userID string, limitBytes int64, useDefault bool) (*filespb.Quota, error) {
+	resp, err := s.filesServerClient.SetQuota(ctx, &filespb.SetQuotaRequest{
+		UserID:     userID,
+		LimitBytes: limitBytes,
+		UseDefault: useDefault,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to set quota: %w", err)
+	}
+
+	return resp.Quota, nil
+}
+
+func (s *adminService) ForceLogout(ctx context.Context, accessToken, userID string) (bool, error) {
+	resp, err := s.authServiceClient.ForceLogout(ctx, &authpb.ForceLogoutRequest{
+		AccessToken: accessToken,
+		Id:          userID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return false, fmt.Errorf("failed to force logout: %w", err)
+	}
+
+	return resp.Success, nil
+}
+
+func (s *adminService) GetSecuritySettings(ctx context.Context, accessToken string) (*authpb.GetSecuritySettingsResponse, error) {
+	resp, err := s.authServiceClient.GetSecuritySettings(ctx, &authpb.GetSecuritySettingsRequest{
+		AccessToken: accessToken,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to get security settings: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (s *adminService) UpdateSecuritySettings(ctx context.Context, accessToken string, totpRequired bool) (bool, error) {
+	resp, err := s.authServiceClient.UpdateSecuritySettings(ctx, &authpb.UpdateSecuritySettingsRequest{
+		AccessToken:  accessToken,
+		TotpRequired: totpRequired,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return false, fmt.Errorf("failed to update security settings: %w", err)
+	}
+
+	return resp.Success, nil
+}
+
+func (s *adminService) ListLoginLockouts(ctx context.Context, accessToken string) ([]*authpb.LoginLockout, error) {
+	resp, err := s.authServiceClient.ListLoginLockouts(ctx, &authpb.ListLoginLockoutsRequest{
+		AccessToken: accessToken,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to list login lockouts: %w", err)
+	}
+
+	return resp.Lockouts, nil
+}
+
+func (s *adminService) ClearLoginLockout(ctx context.Context, accessToken, scope, subject string) (bool, error) {
+	resp, err := s.authServiceClient.ClearLoginLockout(ctx, &authpb.ClearLoginLockoutRequest{
+		AccessToken: accessToken,
+		Scope:       scope,
+		Subject:     subject,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return false, fmt.Errorf("failed to clear login lockout: %w", err)
+	}
+
+	return resp.Success, nil
+}
+
+func NewAdminService(authClient authpb.AuthServiceClient, filesClient filespb.FileServiceClient) AdminService {
+	return &adminService{
+		authServiceClient: authClient,
+		filesServerClient: filesClient,
+	}
+}
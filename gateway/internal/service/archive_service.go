@@ -0,0 +1,295 @@
+package service
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/avran02/fileshare/gateway/internal/config"
+)
+
+const (
+	ArchiveFormatZip   = "zip"
+	ArchiveFormatTarGz = "tar.gz"
+)
+
+var (
+	ErrArchiveTooLarge          = errors.New("archive exceeds the size or entry limit")
+	ErrArchiveNameConflict      = errors.New("different files would get the same name in the archive")
+	ErrArchivePathNotFound      = errors.New("path not found")
+	ErrUnsupportedArchiveFormat = errors.New("unsupported archive format")
+)
+
+// ArchiveEntry is a file or folder stored in an archive under Name.
+type ArchiveEntry struct {
+	Name     string
+	FilePath string
+	IsDir    bool
+	Size     int64
+}
+
+// ArchiveService packs files and folders into an archive streamed as it is
+// built, downloading one file at a time.
+type ArchiveService interface {
+	ListEntries(ctx context.Context, userID string, paths []string) ([]ArchiveEntry, error)
+	WriteArchive(ctx context.Context, w io.Writer, userID, format string, entries []ArchiveEntry) error
+}
+
+type archiveService struct {
+	filesService FilesService
+	conf         config.Archive
+}
+
+// ListEntries walks the paths, a trailing slash or an empty path meaning a
+// folder, and names the entries relative to the folder containing each path.
+// Paths overlapping an earlier one only add what wasn't listed yet, and
+// different files ending up under one name are rejected.
+func (s *archiveService) ListEntries(ctx context.Context, userID string, paths []string) ([]ArchiveEntry, error) {
+	l := archiveLister{service: s, userID: userID, entries: make([]ArchiveEntry, 0), seen: make(map[string]string)}
+
+	for _, path := range paths {
+		path = strings.TrimPrefix(path, "/")
+		parent := path[:strings.LastIndex(strings.TrimSuffix(path, "/"), "/")+1]
+
+		if path != "" {
+			isDir, size, err := s.stat(ctx, userID, strings.TrimSuffix(path, "/"))
+			if err != nil {
+				return nil, err
+			}
+
+			if !isDir {
+				if strings.HasSuffix(path, "/") {
+					return nil, ErrArchivePathNotFound
+				}
+
+				if _, err = l.add(ArchiveEntry{Name: strings.TrimPrefix(path, parent), FilePath: path, Size: size}); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			path = strings.TrimSuffix(path, "/") + "/"
+
+			added, err := l.add(ArchiveEntry{Name: strings.TrimPrefix(path, parent), FilePath: path, IsDir: true})
+			if err != nil {
+				return nil, err
+			}
+
+			// an already listed folder was walked along with it
+			if !added {
+				continue
+			}
+		}
+
+		if err := l.walk(ctx, path, parent); err != nil {
+			return nil, err
+		}
+	}
+
+	return l.entries, nil
+}
+
+func (s *archiveService) WriteArchive(ctx context.Context, w io.Writer, userID, format string, entries []ArchiveEntry) error {
+	switch format {
+	case ArchiveFormatZip:
+		return s.writeZip(ctx, w, userID, entries)
+	case ArchiveFormatTarGz:
+		return s.writeTarGz(ctx, w, userID, entries)
+	default:
+		return ErrUnsupportedArchiveFormat
+	}
+}
+
+func (s *archiveService) writeZip(ctx context.Context, w io.Writer, userID string, entries []ArchiveEntry) error {
+	zw := zip.NewWriter(w)
+
+	err := s.writeEntries(ctx, userID, entries,
+		func(entry ArchiveEntry) error {
+			_, err := zw.CreateHeader(&zip.FileHeader{Name: entry.Name, Method: zip.Store, Modified: time.Now()})
+			return err
+		},
+		func(entry ArchiveEntry, file *FileDownload) error {
+			fw, err := zw.CreateHeader(&zip.FileHeader{
+				Name:     entry.Name,
+				Method:   zip.Deflate,
+				Modified: file.Metadata.LastModified.AsTime(),
+			})
+			if err != nil {
+				return err
+			}
+
+			_, err = io.Copy(fw, file)
+			return err
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func (s *archiveService) writeTarGz(ctx context.Context, w io.Writer, userID string, entries []ArchiveEntry) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	err := s.writeEntries(ctx, userID, entries,
+		func(entry ArchiveEntry) error {
+			return tw.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeDir,
+				Name:     entry.Name,
+				Mode:     0o755,
+				ModTime:  time.Now(),
+			})
+		},
+		func(entry ArchiveEntry, file *FileDownload) error {
+			if err := tw.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeReg,
+				Name:     entry.Name,
+				Mode:     0o644,
+				Size:     file.Metadata.Size,
+				ModTime:  file.Metadata.LastModified.AsTime(),
+			}); err != nil {
+				return err
+			}
+
+			_, err := io.Copy(tw, file)
+			return err
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if err = tw.Close(); err != nil {
+		return err
+	}
+
+	return gw.Close()
+}
+
+// writeEntries downloads the files one by one and passes them to writeFile.
+func (s *archiveService) writeEntries(
+	ctx context.Context, userID string, entries []ArchiveEntry,
+	writeDir func(entry ArchiveEntry) error, writeFile func(entry ArchiveEntry, file *FileDownload) error,
+) error {
+	for _, entry := range entries {
+		if entry.IsDir {
+			if err := writeDir(entry); err != nil {
+				err = fmt.Errorf("failed to write folder %s: %w", entry.Name, err)
+				slog.Error(err.Error())
+				return err
+			}
+			continue
+		}
+
+		file, err := s.filesService.DownloadFile(ctx, userID, entry.FilePath, "", 0, 0)
+		if err != nil {
+			return err
+		}
+
+		err = writeFile(entry, file)
+		file.Close()
+		if err != nil {
+			err = fmt.Errorf("failed to write file %s: %w", entry.Name, err)
+			slog.Error(err.Error())
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stat tells whether the path without a trailing slash is a folder, and
+// returns its size if it's a file.
+func (s *archiveService) stat(ctx context.Context, userID, path string) (isDir bool, size int64, err error) {
+	files, err := s.filesService.ListFiles(ctx, userID, path)
+	if err != nil {
+		return false, 0, err
+	}
+
+	for _, file := range files {
+		switch file.Name {
+		case path:
+			return false, file.Size, nil
+		case path + "/":
+			return true, 0, nil
+		}
+	}
+
+	return false, 0, ErrArchivePathNotFound
+}
+
+// archiveLister collects archive entries while keeping them within the limits.
+type archiveLister struct {
+	service *archiveService
+	userID  string
+	entries []ArchiveEntry
+	size    int64
+
+	// seen maps the names taken so far to the files stored under them
+	seen map[string]string
+}
+
+// add appends the entry unless it is already listed, and reports whether it
+// did.
+func (l *archiveLister) add(entry ArchiveEntry) (bool, error) {
+	if filePath, ok := l.seen[entry.Name]; ok {
+		if filePath != entry.FilePath {
+			return false, fmt.Errorf("%w: %s", ErrArchiveNameConflict, entry.Name)
+		}
+		return false, nil
+	}
+	l.seen[entry.Name] = entry.FilePath
+
+	l.entries = append(l.entries, entry)
+	l.size += entry.Size
+
+	conf := l.service.conf
+	if (conf.MaxEntries > 0 && len(l.entries) > conf.MaxEntries) || (conf.MaxBytes > 0 && l.size > conf.MaxBytes) {
+		return false, ErrArchiveTooLarge
+	}
+
+	return true, nil
+}
+
+func (l *archiveLister) walk(ctx context.Context, prefix, parent string) error {
+	files, err := l.service.filesService.ListFiles(ctx, l.userID, prefix)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		entry := ArchiveEntry{
+			Name:     strings.TrimPrefix(file.Name, parent),
+			FilePath: file.Name,
+			IsDir:    strings.HasSuffix(file.Name, "/"),
+			Size:     file.Size,
+		}
+		added, err := l.add(entry)
+		if err != nil {
+			return err
+		}
+
+		if added && entry.IsDir {
+			if err = l.walk(ctx, file.Name, parent); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func NewArchiveService(filesService FilesService, conf config.Archive) ArchiveService {
+	return &archiveService{
+		filesService: filesService,
+		conf:         conf,
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/avran02/fileshare/gateway/internal/config"
+	pb "github.com/avran02/fileshare/proto/filespb"
+)
+
+// listingFilesService lists objects the way the files service does: one
+// level below the prefix, with folders ending in a slash.
+type listingFilesService struct {
+	FilesService
+	objects []string
+}
+
+func (s listingFilesService) ListFiles(_ context.Context, _, prefix string) ([]*pb.FileInfo, error) {
+	files := make([]*pb.FileInfo, 0)
+	listed := make(map[string]bool)
+	for _, object := range s.objects {
+		rest, ok := strings.CutPrefix(object, prefix)
+		if !ok {
+			continue
+		}
+
+		name := object
+		if i := strings.Index(rest, "/"); i >= 0 {
+			name = prefix + rest[:i+1]
+		}
+		if !listed[name] {
+			listed[name] = true
+			files = append(files, &pb.FileInfo{Name: name, Size: 1})
+		}
+	}
+
+	return files, nil
+}
+
+func TestListEntries(t *testing.T) {
+	objects := []string{"a/report.pdf", "a/b/notes.txt", "b/report.pdf", "empty/"}
+
+	tests := []struct {
+		name    string
+		paths   []string
+		want    []string
+		wantErr error
+	}{
+		{name: "file", paths: []string{"a/report.pdf"}, want: []string{"report.pdf"}},
+		{name: "folder", paths: []string{"a"}, want: []string{"a/", "a/report.pdf", "a/b/", "a/b/notes.txt"}},
+		{name: "same file twice", paths: []string{"a/report.pdf", "/a/report.pdf"}, want: []string{"report.pdf"}},
+		{name: "folder twice", paths: []string{"a/b/", "a/b"}, want: []string{"b/", "b/notes.txt"}},
+		{name: "same name from different folders", paths: []string{"a/report.pdf", "b/report.pdf"}, wantErr: ErrArchiveNameConflict},
+		{name: "empty folder", paths: []string{"empty/"}, want: []string{"empty/"}},
+		{name: "missing folder", paths: []string{"missing/"}, wantErr: ErrArchivePathNotFound},
+		{name: "file as folder", paths: []string{"a/report.pdf/"}, wantErr: ErrArchivePathNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewArchiveService(listingFilesService{objects: objects}, config.Archive{})
+			entries, err := s.ListEntries(context.Background(), "user", tt.paths)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ListEntries(%q) error = %v, want %v", tt.paths, err, tt.wantErr)
+			}
+
+			names := make([]string, len(entries))
+			for i, entry := range entries {
+				names[i] = entry.Name
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ListEntries(%q) = %q, want %q", tt.paths, names, tt.want)
+			}
+		})
+	}
+}
+
+func TestListEntriesLimits(t *testing.T) {
+	files := listingFilesService{objects: []string{"a/1", "a/2", "a/3"}}
+
+	s := NewArchiveService(files, config.Archive{MaxEntries: 3})
+	if _, err := s.ListEntries(context.Background(), "user", []string{"a"}); !errors.Is(err, ErrArchiveTooLarge) {
+		t.Errorf("4 entries with MaxEntries 3: error = %v, want %v", err, ErrArchiveTooLarge)
+	}
+
+	s = NewArchiveService(files, config.Archive{MaxBytes: 3})
+	if _, err := s.ListEntries(context.Background(), "user", []string{"a"}); err != nil {
+		t.Errorf("3 bytes with MaxBytes 3: error = %v", err)
+	}
+}
@@ -12,11 +12,43 @@ import (
 
 var chankSize = 1024 * 1024
 
+var ErrNoFileMetadata = errors.New("download stream has no file metadata")
+
 type FilesService interface {
 	ListFiles(ctx context.Context, userID, filePath string) ([]*pb.FileInfo, error)
 	UploadFile(ctx context.Context, reader io.Reader, userID, filePath string) (bool, error)
-	DownloadFile(ctx context.Context, userID, filePath string, w *io.PipeWriter) error
-	RemoveFile(ctx context.Context, userID, filePath string) (bool, error)
+	DownloadFile(ctx context.Context, userID, filePath, versionID string, offset, length int64) (*FileDownload, error)
+	RemoveFile(ctx context.Context, userID, filePath string) (*pb.RemoveFileResponse, error)
+	CreateDirectory(ctx context.Context, userID, filePath string) (bool, error)
+	RemoveDirectory(ctx context.Context, userID, filePath string, recursive bool) (*pb.RemoveDirectoryResponse, error)
+	MoveFile(ctx context.Context, userID, srcPath, dstPath string, overwrite bool) (*pb.MoveFileResponse, error)
+	CopyFile(ctx context.Context, userID, shareID, srcPath, dstPath string, overwrite bool) (*pb.CopyFileResponse, error)
+
+	CreateUploadSession(ctx context.Context, userID, filePath string, totalSize int64, checksum string) (*pb.UploadSession, error)
+	GetUploadSession(ctx context.Context, userID, sessionID string) (*pb.GetUploadSessionResponse, error)
+	UploadChunk(ctx context.Context, reader io.Reader, userID, sessionID string, chunk int32) (*pb.UploadChunkResponse, error)
+	AppendUpload(ctx context.Context, reader io.Reader, userID, sessionID string, offset int64) (newOffset int64, err error)
+	CompleteUploadSession(ctx context.Context, userID, sessionID string) (filePath string, err error)
+	AbortUploadSession(ctx context.Context, userID, sessionID string) (bool, error)
+
+	ListVersions(ctx context.Context, userID, filePath string) ([]*pb.VersionInfo, error)
+	RestoreVersion(ctx context.Context, userID, filePath, versionID string) (newVersionID string, err error)
+	DeleteVersion(ctx context.Context, userID, filePath, versionID string) (bool, error)
+	GetRetentionPolicy(ctx context.Context, userID string) (*pb.RetentionPolicy, error)
+	SetRetentionPolicy(ctx context.Context, userID string, keepVersions, keepDays int64) (*pb.RetentionPolicy, error)
+
+	GetUsage(ctx context.Context, userID string) (*pb.GetUsageResponse, error)
+
+	ListTrash(ctx context.Context, userID string) ([]*pb.TrashItem, error)
+	RestoreTrash(ctx context.Context, userID, itemID, filePath, conflict string) (*pb.RestoreTrashResponse, error)
+	EmptyTrash(ctx context.Context, userID, itemID string) (removed int64, err error)
+
+	ListSharedFiles(ctx context.Context, userID, shareID, filePath string) ([]*pb.FileInfo, error)
+	DownloadSharedFile(ctx context.Context, userID, shareID, filePath string, offset, length int64) (*FileDownload, error)
+
+	ListLinkFiles(ctx context.Context, token, password, filePath string) ([]*pb.FileInfo, error)
+	DownloadLinkFile(ctx context.Context, token, password, filePath string, offset, length int64) (*FileDownload, error)
+	UploadLinkFile(ctx context.Context, reader io.Reader, token, password, fileName string) (string, error)
 }
 
 type filesService struct {
@@ -24,10 +56,30 @@ type filesService struct {
 }
 
 func (s *filesService) ListFiles(ctx context.Context, userID, filePath string) ([]*pb.FileInfo, error) {
-	resp, err := s.filesServerClient.ListFiles(ctx, &pb.ListFilesRequest{
+	return s.listFiles(ctx, &pb.ListFilesRequest{
 		UserID:   userID,
 		FilePath: filePath,
 	})
+}
+
+func (s *filesService) ListSharedFiles(ctx context.Context, userID, shareID, filePath string) ([]*pb.FileInfo, error) {
+	return s.listFiles(ctx, &pb.ListFilesRequest{
+		UserID:   userID,
+		ShareID:  shareID,
+		FilePath: filePath,
+	})
+}
+
+func (s *filesService) ListLinkFiles(ctx context.Context, token, password, filePath string) ([]*pb.FileInfo, error) {
+	return s.listFiles(ctx, &pb.ListFilesRequest{
+		LinkToken:    token,
+		LinkPassword: password,
+		FilePath:     filePath,
+	})
+}
+
+func (s *filesService) listFiles(ctx context.Context, req *pb.ListFilesRequest) ([]*pb.FileInfo, error) {
+	resp, err := s.filesServerClient.ListFiles(ctx, req)
 	if err != nil {
 		slog.Error(err.Error())
 		return nil, fmt.Errorf("failed to list files: %w", err)
@@ -38,97 +90,377 @@ func (s *filesService) ListFiles(ctx context.Context, userID, filePath string) (
 }
 
 func (s *filesService) UploadFile(ctx context.Context, reader io.Reader, userID, filePath string) (bool, error) {
+	resp, err := s.uploadFile(ctx, reader, &pb.UploadFileRequest{
+		UserID:   userID,
+		FilePath: filePath,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return resp.Success, nil
+}
+
+func (s *filesService) UploadLinkFile(ctx context.Context, reader io.Reader, token, password, fileName string) (string, error) {
+	resp, err := s.uploadFile(ctx, reader, &pb.UploadFileRequest{
+		LinkToken:    token,
+		LinkPassword: password,
+		FilePath:     fileName,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return resp.FilePath, nil
+}
+
+func (s *filesService) uploadFile(ctx context.Context, reader io.Reader, req *pb.UploadFileRequest) (*pb.UploadFileResponse, error) {
 	stream, err := s.filesServerClient.UploadFile(ctx)
 	if err != nil {
 		slog.Error(err.Error())
-		return false, fmt.Errorf("failed to create upload stream: %w", err)
+		return nil, fmt.Errorf("failed to create upload stream: %w", err)
 	}
 
-	if err = stream.Send(&pb.UploadFileRequest{
-		UserID:   userID,
-		FilePath: filePath,
+	if err = stream.Send(req); err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to send initial request: %w", err)
+	}
+
+	if err = sendContent(reader, func(content []byte) error {
+		return stream.Send(&pb.UploadFileRequest{Content: content})
 	}); err != nil {
+		return nil, err
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
 		slog.Error(err.Error())
-		return false, fmt.Errorf("failed to send initial request: %w", err)
+		return nil, fmt.Errorf("failed to close and receive response: %w", err)
 	}
 
+	return resp, nil
+}
+
+func (s *filesService) CreateUploadSession(ctx context.Context, userID, filePath string, totalSize int64, checksum string) (*pb.UploadSession, error) {
+	resp, err := s.filesServerClient.CreateUploadSession(ctx, &pb.CreateUploadSessionRequest{
+		UserID:    userID,
+		FilePath:  filePath,
+		TotalSize: totalSize,
+		Checksum:  checksum,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to create upload session: %w", err)
+	}
+	return resp.Session, nil
+}
+
+func (s *filesService) GetUploadSession(ctx context.Context, userID, sessionID string) (*pb.GetUploadSessionResponse, error) {
+	resp, err := s.filesServerClient.GetUploadSession(ctx, &pb.GetUploadSessionRequest{
+		UserID:    userID,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to get upload session: %w", err)
+	}
+	return resp, nil
+}
+
+func (s *filesService) UploadChunk(ctx context.Context, reader io.Reader, userID, sessionID string, chunk int32) (*pb.UploadChunkResponse, error) {
+	stream, err := s.filesServerClient.UploadChunk(ctx)
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to create upload chunk stream: %w", err)
+	}
+
+	if err = stream.Send(&pb.UploadChunkRequest{
+		UserID:    userID,
+		SessionID: sessionID,
+		Chunk:     chunk,
+	}); err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to send initial request: %w", err)
+	}
+
+	if err = sendContent(reader, func(content []byte) error {
+		return stream.Send(&pb.UploadChunkRequest{Content: content})
+	}); err != nil {
+		return nil, err
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to close and receive response: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (s *filesService) AppendUpload(ctx context.Context, reader io.Reader, userID, sessionID string, offset int64) (int64, error) {
+	stream, err := s.filesServerClient.AppendUpload(ctx)
+	if err != nil {
+		slog.Error(err.Error())
+		return 0, fmt.Errorf("failed to create append upload stream: %w", err)
+	}
+
+	if err = stream.Send(&pb.AppendUploadRequest{
+		UserID:    userID,
+		SessionID: sessionID,
+		Offset:    offset,
+	}); err != nil {
+		slog.Error(err.Error())
+		return 0, fmt.Errorf("failed to send initial request: %w", err)
+	}
+
+	if err = sendContent(reader, func(content []byte) error {
+		return stream.Send(&pb.AppendUploadRequest{Content: content})
+	}); err != nil {
+		return 0, err
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		slog.Error(err.Error())
+		return 0, err
+	}
+
+	return resp.Offset, nil
+}
+
+func (s *filesService) CompleteUploadSession(ctx context.Context, userID, sessionID string) (string, error) {
+	resp, err := s.filesServerClient.CompleteUploadSession(ctx, &pb.CompleteUploadSessionRequest{
+		UserID:    userID,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return "", fmt.Errorf("failed to complete upload: %w", err)
+	}
+	return resp.FilePath, nil
+}
+
+func (s *filesService) AbortUploadSession(ctx context.Context, userID, sessionID string) (bool, error) {
+	resp, err := s.filesServerClient.AbortUploadSession(ctx, &pb.AbortUploadSessionRequest{
+		UserID:    userID,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return false, fmt.Errorf("failed to abort upload: %w", err)
+	}
+	return resp.Success, nil
+}
+
+// sendContent reads the reader to the end, passing it to send piece by piece.
+func sendContent(reader io.Reader, send func(content []byte) error) error {
 	buf := make([]byte, chankSize)
 
 	for {
 		n, err := reader.Read(buf)
+		if n > 0 {
+			if sendErr := send(buf[:n]); sendErr != nil {
+				slog.Error(sendErr.Error())
+				return fmt.Errorf("failed to send file chunk: %w", sendErr)
+			}
+		}
+
 		if err != nil {
 			if errors.Is(err, io.EOF) {
-				break
+				return nil
 			}
 			slog.Error(err.Error())
-			return false, fmt.Errorf("failed to read file: %w", err)
+			return fmt.Errorf("failed to read file: %w", err)
 		}
+	}
+}
 
-		if err = stream.Send(&pb.UploadFileRequest{
-			Content: buf[:n],
-		}); err != nil {
-			slog.Error(err.Error())
-			return false, fmt.Errorf("failed to send file chunk: %w", err)
+// FileDownload reads the content of a file being downloaded. Its metadata
+// arrives first, so the response headers can be decided before the content.
+type FileDownload struct {
+	Metadata *pb.FileMetadata
+	stream   pb.FileService_DownloadFileClient
+	cancel   context.CancelFunc
+	buf      []byte
+}
+
+func (d *FileDownload) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		resp, err := d.stream.Recv()
+		if err != nil {
+			return 0, err
 		}
+		d.buf = resp.Content
 	}
 
-	resp, err := stream.CloseAndRecv()
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+// Close stops the download, even if not all of the content was read.
+func (d *FileDownload) Close() error {
+	d.cancel()
+	return nil
+}
+
+func (s *filesService) DownloadFile(ctx context.Context, userID, filePath, versionID string, offset, length int64) (*FileDownload, error) {
+	return s.downloadFile(ctx, &pb.DownloadFileRequest{
+		UserID:    userID,
+		FilePath:  filePath,
+		VersionID: versionID,
+		Offset:    offset,
+		Length:    length,
+	})
+}
+
+func (s *filesService) DownloadSharedFile(ctx context.Context, userID, shareID, filePath string, offset, length int64) (*FileDownload, error) {
+	return s.downloadFile(ctx, &pb.DownloadFileRequest{
+		UserID:   userID,
+		ShareID:  shareID,
+		FilePath: filePath,
+		Offset:   offset,
+		Length:   length,
+	})
+}
+
+func (s *filesService) DownloadLinkFile(ctx context.Context, token, password, filePath string, offset, length int64) (*FileDownload, error) {
+	return s.downloadFile(ctx, &pb.DownloadFileRequest{
+		LinkToken:    token,
+		LinkPassword: password,
+		FilePath:     filePath,
+		Offset:       offset,
+		Length:       length,
+	})
+}
+
+func (s *filesService) downloadFile(ctx context.Context, req *pb.DownloadFileRequest) (*FileDownload, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := s.filesServerClient.DownloadFile(ctx, req)
 	if err != nil {
+		cancel()
 		slog.Error(err.Error())
-		return false, fmt.Errorf("failed to close and receive response: %w", err)
+		return nil, fmt.Errorf("failed to create download stream: %w", err)
 	}
 
-	return resp.Success, nil
+	resp, err := stream.Recv()
+	if err != nil {
+		cancel()
+		err = fmt.Errorf("failed to receive file metadata: %w", err)
+		slog.Error(err.Error())
+		return nil, err
+	}
+
+	if resp.Metadata == nil {
+		cancel()
+		return nil, ErrNoFileMetadata
+	}
+
+	return &FileDownload{
+		Metadata: resp.Metadata,
+		stream:   stream,
+		cancel:   cancel,
+	}, nil
 }
 
-func (s *filesService) DownloadFile(ctx context.Context, userID, filePath string, w *io.PipeWriter) error {
-	defer w.Close()
-	stream, err := s.filesServerClient.DownloadFile(ctx, &pb.DownloadFileRequest{
+func (s *filesService) RemoveFile(ctx context.Context, userID, filePath string) (*pb.RemoveFileResponse, error) {
+	resp, err := s.filesServerClient.RemoveFile(ctx, &pb.RemoveFileRequest{
 		UserID:   userID,
 		FilePath: filePath,
 	})
 	if err != nil {
 		slog.Error(err.Error())
-		return fmt.Errorf("failed to create download stream: %w", err)
+		return nil, err
 	}
+	return resp, nil
+}
 
-	for {
-		resp, err := stream.Recv()
-		if err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			} else {
-				err = fmt.Errorf("failed to receive download file response: %w", err)
-				slog.Error(err.Error())
-				return err
-			}
-		}
+func (s *filesService) CreateDirectory(ctx context.Context, userID, filePath string) (bool, error) {
+	resp, err := s.filesServerClient.CreateDirectory(ctx, &pb.CreateDirectoryRequest{
+		UserID:   userID,
+		FilePath: filePath,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return false, err
+	}
+	return resp.Success, nil
+}
 
-		_, err = w.Write(resp.Content)
-		if err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			}
-			err = fmt.Errorf("failed to write download file response: %w", err)
-			slog.Error(err.Error())
-			return err
-		}
+func (s *filesService) RemoveDirectory(ctx context.Context, userID, filePath string, recursive bool) (*pb.RemoveDirectoryResponse, error) {
+	resp, err := s.filesServerClient.RemoveDirectory(ctx, &pb.RemoveDirectoryRequest{
+		UserID:    userID,
+		FilePath:  filePath,
+		Recursive: recursive,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, err
 	}
+	return resp, nil
+}
 
-	if err = stream.CloseSend(); err != nil {
-		err = fmt.Errorf("failed to close send: %w", err)
+func (s *filesService) MoveFile(ctx context.Context, userID, srcPath, dstPath string, overwrite bool) (*pb.MoveFileResponse, error) {
+	resp, err := s.filesServerClient.MoveFile(ctx, &pb.MoveFileRequest{
+		UserID:    userID,
+		SrcPath:   srcPath,
+		DstPath:   dstPath,
+		Overwrite: overwrite,
+	})
+	if err != nil {
 		slog.Error(err.Error())
-		return err
+		return nil, err
 	}
+	return resp, nil
+}
 
-	return nil
+func (s *filesService) CopyFile(ctx context.Context, userID, shareID, srcPath, dstPath string, overwrite bool) (*pb.CopyFileResponse, error) {
+	resp, err := s.filesServerClient.CopyFile(ctx, &pb.CopyFileRequest{
+		UserID:    userID,
+		ShareID:   shareID,
+		SrcPath:   srcPath,
+		DstPath:   dstPath,
+		Overwrite: overwrite,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, err
+	}
+	return resp, nil
 }
 
-func (s *filesService) RemoveFile(ctx context.Context, userID, filePath string) (bool, error) {
-	resp, err := s.filesServerClient.RemoveFile(ctx, &pb.RemoveFileRequest{
+func (s *filesService) ListVersions(ctx context.Context, userID, filePath string) ([]*pb.VersionInfo, error) {
+	resp, err := s.filesServerClient.ListVersions(ctx, &pb.ListVersionsRequest{
 		UserID:   userID,
 		FilePath: filePath,
 	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+	return resp.Versions, nil
+}
+
+func (s *filesService) RestoreVersion(ctx context.Context, userID, filePath, versionID string) (string, error) {
+	resp, err := s.filesServerClient.RestoreVersion(ctx, &pb.RestoreVersionRequest{
+		UserID:    userID,
+		FilePath:  filePath,
+		VersionID: versionID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return "", err
+	}
+	return resp.VersionID, nil
+}
+
+func (s *filesService) DeleteVersion(ctx context.Context, userID, filePath, versionID string) (bool, error) {
+	resp, err := s.filesServerClient.DeleteVersion(ctx, &pb.DeleteVersionRequest{
+		UserID:    userID,
+		FilePath:  filePath,
+		VersionID: versionID,
+	})
 	if err != nil {
 		slog.Error(err.Error())
 		return false, err
@@ -136,6 +468,78 @@ func (s *filesService) RemoveFile(ctx context.Context, userID, filePath string)
 	return resp.Success, nil
 }
 
+func (s *filesService) GetRetentionPolicy(ctx context.Context, userID string) (*pb.RetentionPolicy, error) {
+	resp, err := s.filesServerClient.GetRetentionPolicy(ctx, &pb.GetRetentionPolicyRequest{
+		UserID: userID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to get retention policy: %w", err)
+	}
+	return resp.Policy, nil
+}
+
+func (s *filesService) SetRetentionPolicy(ctx context.Context, userID string, keepVersions, keepDays int64) (*pb.RetentionPolicy, error) {
+	resp, err := s.filesServerClient.SetRetentionPolicy(ctx, &pb.SetRetentionPolicyRequest{
+		UserID:       userID,
+		KeepVersions: keepVersions,
+		KeepDays:     keepDays,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to set retention policy: %w", err)
+	}
+	return resp.Policy, nil
+}
+
+func (s *filesService) GetUsage(ctx context.Context, userID string) (*pb.GetUsageResponse, error) {
+	resp, err := s.filesServerClient.GetUsage(ctx, &pb.GetUsageRequest{
+		UserID: userID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to get usage: %w", err)
+	}
+	return resp, nil
+}
+
+func (s *filesService) ListTrash(ctx context.Context, userID string) ([]*pb.TrashItem, error) {
+	resp, err := s.filesServerClient.ListTrash(ctx, &pb.ListTrashRequest{
+		UserID: userID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+	return resp.Items, nil
+}
+
+func (s *filesService) RestoreTrash(ctx context.Context, userID, itemID, filePath, conflict string) (*pb.RestoreTrashResponse, error) {
+	resp, err := s.filesServerClient.RestoreTrash(ctx, &pb.RestoreTrashRequest{
+		UserID:   userID,
+		ItemID:   itemID,
+		FilePath: filePath,
+		Conflict: conflict,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *filesService) EmptyTrash(ctx context.Context, userID, itemID string) (int64, error) {
+	resp, err := s.filesServerClient.EmptyTrash(ctx, &pb.EmptyTrashRequest{
+		UserID: userID,
+		ItemID: itemID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return 0, err
+	}
+	return resp.Removed, nil
+}
+
 func NewFilesService(client pb.FileServiceClient) FilesService {
 	return &filesService{
 		filesServerClient: client,
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	pb "github.com/avran02/fileshare/proto/filespb"
+)
+
+type LinkService interface {
+	CreateLink(ctx context.Context, req *pb.CreateLinkRequest) (*pb.LinkInfo, error)
+	RevokeLink(ctx context.Context, ownerID, linkID string) (bool, error)
+	ListLinks(ctx context.Context, ownerID string) ([]*pb.LinkInfo, error)
+	ResolveLink(ctx context.Context, token, password, filePath string) (string, error)
+}
+
+type linkService struct {
+	filesServerClient pb.FileServiceClient
+}
+
+func (s *linkService) CreateLink(ctx context.Context, req *pb.CreateLinkRequest) (*pb.LinkInfo, error) {
+	resp, err := s.filesServerClient.CreateLink(ctx, req)
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to create link: %w", err)
+	}
+
+	return resp.Link, nil
+}
+
+func (s *linkService) RevokeLink(ctx context.Context, ownerID, linkID string) (bool, error) {
+	resp, err := s.filesServerClient.RevokeLink(ctx, &pb.RevokeLinkRequest{
+		OwnerID: ownerID,
+		LinkID:  linkID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return false, fmt.Errorf("failed to revoke link: %w", err)
+	}
+
+	return resp.Success, nil
+}
+
+func (s *linkService) ListLinks(ctx context.Context, ownerID string) ([]*pb.LinkInfo, error) {
+	resp, err := s.filesServerClient.ListLinks(ctx, &pb.ListLinksRequest{
+		OwnerID: ownerID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to list links: %w", err)
+	}
+
+	return resp.Links, nil
+}
+
+func (s *linkService) ResolveLink(ctx context.Context, token, password, filePath string) (string, error) {
+	resp, err := s.filesServerClient.ResolveLink(ctx, &pb.ResolveLinkRequest{
+		Token:    token,
+		Password: password,
+		FilePath: filePath,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return "", fmt.Errorf("failed to resolve link: %w", err)
+	}
+
+	return resp.FilePath, nil
+}
+
+func NewLinkService(client pb.FileServiceClient) LinkService {
+	return &linkService{
+		filesServerClient: client,
+	}
+}
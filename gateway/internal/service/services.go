@@ -4,4 +4,7 @@ type Services struct {
 	UserService
 	FilesService
 	ShareService
+	LinkService
+	AdminService
+	ArchiveService
 }
@@ -1,16 +1,102 @@
 package service
 
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	authpb "github.com/avran02/fileshare/proto/authpb"
+	filespb "github.com/avran02/fileshare/proto/filespb"
+)
+
 type ShareService interface {
-	Share()
-	Unshare()
+	Share(ctx context.Context, ownerID, username, filePath, permission string) (*filespb.ShareInfo, error)
+	Unshare(ctx context.Context, ownerID, shareID string) (bool, error)
+	ListShares(ctx context.Context, ownerID string) ([]*filespb.ShareInfo, error)
+	ListIncomingShares(ctx context.Context, recipientID string) ([]*filespb.ShareInfo, error)
+	GetUsername(ctx context.Context, userID string) (string, error)
 }
 
-type shareService struct{}
+type shareService struct {
+	filesServerClient filespb.FileServiceClient
+	authServiceClient authpb.AuthServiceClient
+}
+
+func (s *shareService) Share(ctx context.Context, ownerID, username, filePath, permission string) (*filespb.ShareInfo, error) {
+	user, err := s.authServiceClient.FindUser(ctx, &authpb.FindUserRequest{
+		Username: username,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to find user %s: %w", username, err)
+	}
+
+	resp, err := s.filesServerClient.CreateShare(ctx, &filespb.CreateShareRequest{
+		OwnerID:     ownerID,
+		RecipientID: user.Id,
+		FilePath:    filePath,
+		Permission:  permission,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to share file: %w", err)
+	}
 
-func (s *shareService) Share() {}
+	return resp.Share, nil
+}
+
+func (s *shareService) Unshare(ctx context.Context, ownerID, shareID string) (bool, error) {
+	resp, err := s.filesServerClient.RemoveShare(ctx, &filespb.RemoveShareRequest{
+		OwnerID: ownerID,
+		ShareID: shareID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return false, fmt.Errorf("failed to unshare file: %w", err)
+	}
+
+	return resp.Success, nil
+}
 
-func (s *shareService) Unshare() {}
+func (s *shareService) ListShares(ctx context.Context, ownerID string) ([]*filespb.ShareInfo, error) {
+	resp, err := s.filesServerClient.ListShares(ctx, &filespb.ListSharesRequest{
+		OwnerID: ownerID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to list shares: %w", err)
+	}
+
+	return resp.Shares, nil
+}
+
+func (s *shareService) ListIncomingShares(ctx context.Context, recipientID string) ([]*filespb.ShareInfo, error) {
+	resp, err := s.filesServerClient.ListIncomingShares(ctx, &filespb.ListIncomingSharesRequest{
+		RecipientID: recipientID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to list incoming shares: %w", err)
+	}
+
+	return resp.Shares, nil
+}
+
+func (s *shareService) GetUsername(ctx context.Context, userID string) (string, error) {
+	user, err := s.authServiceClient.FindUser(ctx, &authpb.FindUserRequest{
+		Id: userID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return "", fmt.Errorf("failed to find user %s: %w", userID, err)
+	}
+
+	return user.Username, nil
+}
 
-func NewShareService() ShareService {
-	return &shareService{}
+func NewShareService(filesClient filespb.FileServiceClient, authClient authpb.AuthServiceClient) ShareService {
+	return &shareService{
+		filesServerClient: filesClient,
+		authServiceClient: authClient,
+	}
 }
@@ -10,10 +10,29 @@ import (
 
 type UserService interface {
 	RegisterUser(ctx context.Context, username, password string) (bool, error)
-	LoginUser(ctx context.Context, username, password string) (accessToken, refreshToken string, err error)
-	RefreshToken(ctx context.Context, refreshToken string) (string, error)
+	LoginUser(ctx context.Context, username, password, userAgent, ip string) (*pb.LoginResponse, error)
+	RefreshToken(ctx context.Context, refreshToken string) (newAccessToken, newRefreshToken string, err error)
 	Logout(ctx context.Context, accessToken string) (bool, error)
 
+	ChangePassword(ctx context.Context, accessToken, currentPassword, newPassword string) (bool, error)
+	RequestPasswordReset(ctx context.Context, username string) (bool, error)
+	ResetPassword(ctx context.Context, token, newPassword string) (bool, error)
+
+	VerifyLoginTOTP(ctx context.Context, challengeToken, code, userAgent, ip string) (accessToken, refreshToken string, err error)
+	BeginTOTPEnrollment(ctx context.Context, token string) (*pb.BeginTOTPEnrollmentResponse, error)
+	ConfirmTOTPEnrollment(ctx context.Context, token, code, userAgent, ip string) (*pb.ConfirmTOTPEnrollmentResponse, error)
+	DisableTOTP(ctx context.Context, accessToken, code string) (bool, error)
+
+	ListSessions(ctx context.Context, accessToken string) ([]*pb.SessionInfo, error)
+	RevokeSession(ctx context.Context, accessToken, sessionID string) (bool, error)
+	RevokeOtherSessions(ctx context.Context, accessToken string) (bool, error)
+
+	CreatePersonalToken(ctx context.Context, accessToken, name string, scopes []string, expiresInDays int32) (*pb.CreatePersonalTokenResponse, error)
+	ListPersonalTokens(ctx context.Context, accessToken string) ([]*pb.PersonalTokenInfo, error)
+	RevokePersonalToken(ctx context.Context, accessToken, id string) (bool, error)
+
+	GetJWKS(ctx context.Context) ([]*pb.JWK, error)
+
 	GetGrpcClient() pb.AuthServiceClient
 }
 
@@ -33,29 +52,31 @@ func (s *userService) RegisterUser(ctx context.Context, username, password strin
 	return resp.Success, nil
 }
 
-func (s *userService) LoginUser(ctx context.Context, username, password string) (accessToken, refreshToken string, err error) {
+func (s *userService) LoginUser(ctx context.Context, username, password, userAgent, ip string) (*pb.LoginResponse, error) {
 	resp, err := s.authServiceClient.Login(ctx, &pb.LoginRequest{
-		Username: username,
-		Password: password,
+		Username:  username,
+		Password:  password,
+		UserAgent: userAgent,
+		Ip:        ip,
 	})
 	if err != nil {
 		slog.Error(err.Error())
-		return "", "", fmt.Errorf("failed to login: %w", err)
+		return nil, fmt.Errorf("failed to login: %w", err)
 	}
 
-	return resp.AccessToken, resp.RefreshToken, nil
+	return resp, nil
 }
 
-func (s *userService) RefreshToken(ctx context.Context, refreshToken string) (string, error) {
+func (s *userService) RefreshToken(ctx context.Context, refreshToken string) (newAccessToken, newRefreshToken string, err error) {
 	resp, err := s.authServiceClient.RefreshToken(ctx, &pb.RefreshTokenRequest{
 		RefreshToken: refreshToken,
 	})
 	if err != nil {
 		slog.Error(err.Error())
-		return "", fmt.Errorf("failed to refresh token: %w", err)
+		return "", "", fmt.Errorf("failed to refresh token: %w", err)
 	}
 
-	return resp.AccessToken, nil
+	return resp.AccessToken, resp.RefreshToken, nil
 }
 
 func (s *userService) Logout(ctx context.Context, accessToken string) (bool, error) {
@@ -70,6 +91,187 @@ func (s *userService) Logout(ctx context.Context, accessToken string) (bool, err
 	return resp.Success, nil
 }
 
+func (s *userService) ChangePassword(ctx context.Context, accessToken, currentPassword, newPassword string) (bool, error) {
+	resp, err := s.authServiceClient.ChangePassword(ctx, &pb.ChangePasswordRequest{
+		AccessToken:     accessToken,
+		CurrentPassword: currentPassword,
+		NewPassword:     newPassword,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return false, fmt.Errorf("failed to change password: %w", err)
+	}
+
+	return resp.Success, nil
+}
+
+func (s *userService) RequestPasswordReset(ctx context.Context, username string) (bool, error) {
+	resp, err := s.authServiceClient.RequestPasswordReset(ctx, &pb.RequestPasswordResetRequest{
+		Username: username,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return false, fmt.Errorf("failed to request password reset: %w", err)
+	}
+
+	return resp.Success, nil
+}
+
+func (s *userService) ResetPassword(ctx context.Context, token, newPassword string) (bool, error) {
+	resp, err := s.authServiceClient.ResetPassword(ctx, &pb.ResetPasswordRequest{
+		Token:       token,
+		NewPassword: newPassword,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return false, fmt.Errorf("failed to reset password: %w", err)
+	}
+
+	return resp.Success, nil
+}
+
+func (s *userService) VerifyLoginTOTP(ctx context.Context, challengeToken, code, userAgent, ip string) (accessToken, refreshToken string, err error) {
+	resp, err := s.authServiceClient.VerifyLoginTOTP(ctx, &pb.VerifyLoginTOTPRequest{
+		ChallengeToken: challengeToken,
+		Code:           code,
+		UserAgent:      userAgent,
+		Ip:             ip,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return "", "", fmt.Errorf("failed to verify code: %w", err)
+	}
+
+	return resp.AccessToken, resp.RefreshToken, nil
+}
+
+func (s *userService) BeginTOTPEnrollment(ctx context.Context, token string) (*pb.BeginTOTPEnrollmentResponse, error) {
+	resp, err := s.authServiceClient.BeginTOTPEnrollment(ctx, &pb.BeginTOTPEnrollmentRequest{
+		Token: token,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to begin totp enrollment: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (s *userService) ConfirmTOTPEnrollment(ctx context.Context, token, code, userAgent, ip string) (*pb.ConfirmTOTPEnrollmentResponse, error) {
+	resp, err := s.authServiceClient.ConfirmTOTPEnrollment(ctx, &pb.ConfirmTOTPEnrollmentRequest{
+		Token:     token,
+		Code:      code,
+		UserAgent: userAgent,
+		Ip:        ip,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to confirm totp enrollment: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (s *userService) DisableTOTP(ctx context.Context, accessToken, code string) (bool, error) {
+	resp, err := s.authServiceClient.DisableTOTP(ctx, &pb.DisableTOTPRequest{
+		AccessToken: accessToken,
+		Code:        code,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return false, fmt.Errorf("failed to disable totp: %w", err)
+	}
+
+	return resp.Success, nil
+}
+
+func (s *userService) ListSessions(ctx context.Context, accessToken string) ([]*pb.SessionInfo, error) {
+	resp, err := s.authServiceClient.ListSessions(ctx, &pb.ListSessionsRequest{
+		AccessToken: accessToken,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	return resp.Sessions, nil
+}
+
+func (s *userService) RevokeSession(ctx context.Context, accessToken, sessionID string) (bool, error) {
+	resp, err := s.authServiceClient.RevokeSession(ctx, &pb.RevokeSessionRequest{
+		AccessToken: accessToken,
+		SessionID:   sessionID,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return false, fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return resp.Success, nil
+}
+
+func (s *userService) RevokeOtherSessions(ctx context.Context, accessToken string) (bool, error) {
+	resp, err := s.authServiceClient.RevokeOtherSessions(ctx, &pb.RevokeOtherSessionsRequest{
+		AccessToken: accessToken,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return false, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return resp.Success, nil
+}
+
+func (s *userService) CreatePersonalToken(ctx context.Context, accessToken, name string, scopes []string, expiresInDays int32) (*pb.CreatePersonalTokenResponse, error) {
+	resp, err := s.authServiceClient.CreatePersonalToken(ctx, &pb.CreatePersonalTokenRequest{
+		AccessToken:   accessToken,
+		Name:          name,
+		Scopes:        scopes,
+		ExpiresInDays: expiresInDays,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to create personal token: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (s *userService) ListPersonalTokens(ctx context.Context, accessToken string) ([]*pb.PersonalTokenInfo, error) {
+	resp, err := s.authServiceClient.ListPersonalTokens(ctx, &pb.ListPersonalTokensRequest{
+		AccessToken: accessToken,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to list personal tokens: %w", err)
+	}
+
+	return resp.Tokens, nil
+}
+
+func (s *userService) RevokePersonalToken(ctx context.Context, accessToken, id string) (bool, error) {
+	resp, err := s.authServiceClient.RevokePersonalToken(ctx, &pb.RevokePersonalTokenRequest{
+		AccessToken: accessToken,
+		Id:          id,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		return false, fmt.Errorf("failed to revoke personal token: %w", err)
+	}
+
+	return resp.Success, nil
+}
+
+func (s *userService) GetJWKS(ctx context.Context) ([]*pb.JWK, error) {
+	resp, err := s.authServiceClient.GetJWKS(ctx, &pb.GetJWKSRequest{})
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, fmt.Errorf("failed to get jwks: %w", err)
+	}
+
+	return resp.Keys, nil
+}
+
 func (s *userService) GetGrpcClient() pb.AuthServiceClient {
 	return s.authServiceClient
 }
@@ -0,0 +1,8 @@
+package pb
+
+// Roles a user can have. The auth service puts them into the role fields of
+// its responses and into the tokens it issues.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
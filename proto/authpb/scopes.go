@@ -0,0 +1,12 @@
+package pb
+
+// PersonalTokenPrefix tells personal access tokens apart from session JWTs.
+const PersonalTokenPrefix = "fsp_"
+
+// Scopes a personal access token can be granted. Session tokens carry none
+// and may do everything.
+const (
+	ScopeFilesRead   = "files:read"
+	ScopeFilesWrite  = "files:write"
+	ScopeShareManage = "share:manage"
+)